@@ -0,0 +1,265 @@
+package airtel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"paydex/phone"
+
+	"github.com/pkg/errors"
+)
+
+type ClientOption func(*Airtel)
+
+type Airtel struct {
+	// For sandbox use false and for production use true.
+	Live         bool
+	ClientID     string
+	ClientSecret string
+	TimeOut      time.Duration
+	// Country and Currency are sent on every request
+	// the defaults are KE and KES.
+	Country  string
+	Currency string
+	// CountryCode is stripped from msisdns since airtel expects
+	// the number without the country code.
+	CountryCode string
+	// EncryptedPIN is the disbursement pin encrypted
+	// with the airtel public key.
+	EncryptedPIN string
+	// BaseURL overrides the live and sandbox urls
+	// e.g to point the client at a stand-in server.
+	BaseURL string
+
+	lock  *sync.Mutex
+	token *AccessTokenResponse
+}
+
+func New(clientID, clientSecret string, opts ...ClientOption) *Airtel {
+	client := &Airtel{
+		Live:         false,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TimeOut:      20 * time.Second,
+		Country:      "KE",
+		Currency:     "KES",
+		CountryCode:  "254",
+		lock:         &sync.Mutex{},
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+// WithLiveMode changes from production to sandbox and viceversa.
+func WithLiveMode(mode bool) ClientOption {
+	return func(a *Airtel) {
+		a.Live = mode
+	}
+}
+
+// WithTimeout sets the timeout of each http request to airtel
+// the default is 20 seconds.
+func WithTimeout(timeOut time.Duration) ClientOption {
+	return func(a *Airtel) {
+		a.TimeOut = timeOut
+	}
+}
+
+// WithCountry sets the country, currency and dialling code
+// of the airtel operation.
+func WithCountry(country, currency, countryCode string) ClientOption {
+	return func(a *Airtel) {
+		a.Country = country
+		a.Currency = currency
+		a.CountryCode = countryCode
+	}
+}
+
+// WithEncryptedPIN sets the pin used for disbursements.
+func WithEncryptedPIN(pin string) ClientOption {
+	return func(a *Airtel) {
+		a.EncryptedPIN = pin
+	}
+}
+
+// WithBaseURL points the client at a different host.
+func WithBaseURL(url string) ClientOption {
+	return func(a *Airtel) {
+		a.BaseURL = strings.TrimSuffix(url, "/") + "/"
+	}
+}
+
+// Msisdn returns the national number airtel expects e.g 733000000,
+// numbers outside the supported numbering plans only have the country code stripped.
+func (a *Airtel) Msisdn(number string) string {
+	if n, err := phone.Parse(number, a.Country); err == nil {
+		return n.National
+	}
+	number = strings.TrimPrefix(number, "+")
+	return strings.TrimPrefix(number, a.CountryCode)
+}
+
+// CollectionRequest sends a ussd push to the subscriber
+// the outcome is posted to the callback url.
+func (a *Airtel) CollectionRequest(ctx context.Context, body CollectionRequestBody) (*TransactionResponse, error) {
+	if body.Subscriber.Country == "" {
+		body.Subscriber.Country = a.Country
+	}
+	if body.Subscriber.Currency == "" {
+		body.Subscriber.Currency = a.Currency
+	}
+	if body.Transaction.Country == "" {
+		body.Transaction.Country = a.Country
+	}
+	if body.Transaction.Currency == "" {
+		body.Transaction.Currency = a.Currency
+	}
+	body.Subscriber.Msisdn = a.Msisdn(body.Subscriber.Msisdn)
+	if err := body.Validate(); err != nil {
+		return nil, err
+	}
+	var res TransactionResponse
+	err := a.sendAndProcessRequest(ctx, http.MethodPost, a.getURL(collectURL), body, &res)
+	return &res, err
+}
+
+// CollectionEnquiry returns the status of a collection.
+func (a *Airtel) CollectionEnquiry(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	var res TransactionResponse
+	err := a.sendAndProcessRequest(ctx, http.MethodGet, a.getURL(enquiryURL, transactionID), nil, &res)
+	return &res, err
+}
+
+// RefundRequest refunds a completed collection.
+func (a *Airtel) RefundRequest(ctx context.Context, airtelMoneyID string) (*TransactionResponse, error) {
+	var body RefundRequestBody
+	body.Transaction.AirtelMoneyID = airtelMoneyID
+	var res TransactionResponse
+	err := a.sendAndProcessRequest(ctx, http.MethodPost, a.getURL(refundURL), body, &res)
+	return &res, err
+}
+
+// DisbursementRequest sends money to the subscriber.
+func (a *Airtel) DisbursementRequest(ctx context.Context, body DisbursementRequestBody) (*TransactionResponse, error) {
+	if body.Pin == "" {
+		body.Pin = a.EncryptedPIN
+	}
+	body.Payee.Msisdn = a.Msisdn(body.Payee.Msisdn)
+	if err := body.Validate(); err != nil {
+		return nil, err
+	}
+	var res TransactionResponse
+	err := a.sendAndProcessRequest(ctx, http.MethodPost, a.getURL(disburseURL), body, &res)
+	return &res, err
+}
+
+// DisbursementEnquiry returns the status of a disbursement.
+func (a *Airtel) DisbursementEnquiry(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	var res TransactionResponse
+	err := a.sendAndProcessRequest(ctx, http.MethodGet, a.getURL(disburseQuery, transactionID), nil, &res)
+	return &res, err
+}
+
+// GetBalance returns the balance of the disbursement wallet.
+func (a *Airtel) GetBalance(ctx context.Context) (*BalanceResponse, error) {
+	var res BalanceResponse
+	err := a.sendAndProcessRequest(ctx, http.MethodGet, a.getURL(balanceURL), nil, &res)
+	return &res, err
+}
+
+func (a *Airtel) sendAndProcessRequest(ctx context.Context, method, url string, data, respItem any) error {
+	token, err := a.GetAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	headers := make(map[string]string)
+	headers["Content-Type"] = "application/json"
+	headers["Accept"] = "*/*"
+	headers["X-Country"] = a.Country
+	headers["X-Currency"] = a.Currency
+	headers["Authorization"] = "Bearer " + token.AccessToken
+
+	resp, err := doRequest(ctx, method, url, data, headers, a.TimeOut)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("url: %s  status code: %d  body: %s", resp.Request.URL, resp.StatusCode, b)
+	}
+	if errx := json.NewDecoder(resp.Body).Decode(respItem); errx != nil {
+		return errors.Wrap(errx, "error converting from json")
+	}
+	return nil
+}
+
+// GetAccessToken returns the cached token or gets a new one when it has expired.
+func (a *Airtel) GetAccessToken(ctx context.Context) (*AccessTokenResponse, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.token != nil && time.Until(a.token.ExpireTime) > 0 {
+		return a.token, nil
+	}
+
+	resp, err := doRequest(ctx, http.MethodPost, a.getURL(tokenURL), tokenRequest{
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		GrantType:    "client_credentials",
+	}, map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "*/*",
+	}, a.TimeOut)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("url: %s  status code: %d  body: %s", resp.Request.URL, resp.StatusCode, b)
+	}
+
+	var token AccessTokenResponse
+	if errx := json.NewDecoder(resp.Body).Decode(&token); errx != nil {
+		return nil, errors.Wrap(errx, "error converting from json")
+	}
+	expiresIn, err := token.ExpiresIn.Int64()
+	if err != nil || expiresIn <= 0 {
+		expiresIn = 180
+	}
+	// refresh a little before airtel expires the token.
+	token.ExpireTime = time.Now().Add(time.Duration(expiresIn)*time.Second - 10*time.Second)
+	a.token = &token
+	return a.token, nil
+}
+
+func doRequest(ctx context.Context, method, url string, data any, headers map[string]string, timeOut time.Duration) (*http.Response, error) {
+	body := io.Reader(http.NoBody)
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewBuffer(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	client := &http.Client{
+		Timeout: timeOut,
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,95 @@
+package airtel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"paydex/airtel/airteltest"
+	"paydex/money"
+	"paydex/provider"
+)
+
+func newTestClient(t *testing.T) (*Airtel, *airteltest.Server) {
+	t.Helper()
+	s := airteltest.NewServer()
+	t.Cleanup(s.Close)
+	return New(airteltest.ClientID, airteltest.ClientSecret, WithBaseURL(s.URL), WithEncryptedPIN("pin")), s
+}
+
+func TestAirtel_Collect(t *testing.T) {
+	a, s := newTestClient(t)
+	ctx := context.Background()
+
+	received := make(chan *CallbackBody, 1)
+	callbacks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callback, err := ParseCallback(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		received <- callback
+	}))
+	defer callbacks.Close()
+	s.CallbackURL = callbacks.URL
+
+	res, err := a.Collect(ctx, provider.CollectRequest{Amount: money.Money{Minor: 1000, Currency: "KES"}, PhoneNumber: "254733000000", Reference: "order-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != provider.StatusPending {
+		t.Errorf("Collect() status = %v, want %v", res.Status, provider.StatusPending)
+	}
+	txn, ok := s.Transaction(res.TransactionID)
+	if !ok {
+		t.Fatalf("transaction %s was not received", res.TransactionID)
+	}
+	if txn.Msisdn != "733000000" {
+		t.Errorf("msisdn = %v, want country code stripped", txn.Msisdn)
+	}
+	if txn.Amount != "10.00" {
+		t.Errorf("amount = %v, want 10.00", txn.Amount)
+	}
+
+	if err := s.Complete(res.TransactionID, StatusSuccess); err != nil {
+		t.Fatal(err)
+	}
+	callback := <-received
+	if callback.Transaction.ID != res.TransactionID || callback.Status() != provider.StatusCompleted {
+		t.Errorf("callback = %+v, want completed %s", callback.Transaction, res.TransactionID)
+	}
+
+	status, err := a.Status(ctx, provider.StatusRequest{TransactionID: res.TransactionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != provider.StatusCompleted {
+		t.Errorf("Status() = %v, want %v", status.Status, provider.StatusCompleted)
+	}
+}
+
+func TestAirtel_Payout(t *testing.T) {
+	a, _ := newTestClient(t)
+	res, err := a.Payout(context.Background(), provider.PayoutRequest{Amount: money.Money{Minor: 1000, Currency: "KES"}, PhoneNumber: "254733000000", Reference: "payout-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != provider.StatusCompleted {
+		t.Errorf("Payout() status = %v, want %v", res.Status, provider.StatusCompleted)
+	}
+
+	_, err = a.Payout(context.Background(), provider.PayoutRequest{Amount: money.Money{Minor: 1000, Currency: "USD"}, PhoneNumber: "254733000000", Reference: "payout-2"})
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("Payout() error = %v, want %v", err, money.ErrCurrencyMismatch)
+	}
+}
+
+func TestAirtel_InvalidCredentials(t *testing.T) {
+	s := airteltest.NewServer()
+	defer s.Close()
+	a := New("wrong", "credentials", WithBaseURL(s.URL))
+	if _, err := a.Balance(context.Background(), provider.BalanceRequest{}); err == nil {
+		t.Error("expected an error with invalid credentials")
+	}
+}
@@ -0,0 +1,198 @@
+// Package airteltest provides a stand-in for the Airtel Money Open API
+// for use in tests and local development.
+package airteltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+const (
+	ClientID     = "test-client"
+	ClientSecret = "test-secret"
+	token        = "test-token"
+)
+
+// Transaction is a collection or disbursement received by the server.
+type Transaction struct {
+	ID            string
+	Msisdn        string
+	Amount        string
+	Status        string
+	AirtelMoneyID string
+}
+
+type Server struct {
+	*httptest.Server
+	// CallbackURL receives callbacks when a transaction is completed.
+	CallbackURL string
+
+	lock         *sync.Mutex
+	transactions map[string]*Transaction
+}
+
+// NewServer starts a stand-in server, close it when done.
+func NewServer() *Server {
+	s := &Server{
+		lock:         &sync.Mutex{},
+		transactions: make(map[string]*Transaction),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/oauth2/token", s.handleToken)
+	mux.HandleFunc("/merchant/v1/payments/", s.authorized(s.handleCollect))
+	mux.HandleFunc("/standard/v1/payments/", s.authorized(s.handleEnquiry))
+	mux.HandleFunc("/standard/v1/disbursements/", s.authorized(s.handleDisburse))
+	mux.HandleFunc("/standard/v1/users/balance", s.authorized(s.handleBalance))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Transaction returns the transaction with the given id.
+func (s *Server) Transaction(id string) (*Transaction, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t, ok := s.transactions[id]
+	if !ok {
+		return nil, false
+	}
+	c := *t
+	return &c, true
+}
+
+// Complete simulates the customer acting on a push and posts
+// the callback to CallbackURL when set.
+func (s *Server) Complete(id, status string) error {
+	var airtelMoneyID string
+	s.lock.Lock()
+	t, ok := s.transactions[id]
+	if ok {
+		t.Status = status
+		t.AirtelMoneyID = "MP" + strings.ReplaceAll(id, "-", "")
+		airtelMoneyID = t.AirtelMoneyID
+	}
+	s.lock.Unlock()
+	if !ok || s.CallbackURL == "" {
+		return nil
+	}
+	body := map[string]any{"transaction": map[string]string{
+		"id":              id,
+		"message":         "stand-in callback",
+		"status_code":     status,
+		"airtel_money_id": airtelMoneyID,
+	}}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(s.CallbackURL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if req["client_id"] != ClientID || req["client_secret"] != ClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, map[string]any{"access_token": token, "expires_in": "180", "token_type": "bearer"})
+}
+
+func (s *Server) handleCollect(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Subscriber struct {
+			Msisdn string `json:"msisdn"`
+		} `json:"subscriber"`
+		Transaction struct {
+			Amount string `json:"amount"`
+			ID     string `json:"id"`
+		} `json:"transaction"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.store(&Transaction{ID: req.Transaction.ID, Msisdn: req.Subscriber.Msisdn, Amount: req.Transaction.Amount, Status: "TIP"})
+	writeTransaction(w, req.Transaction.ID, "Success.", "")
+}
+
+func (s *Server) handleDisburse(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Payee struct {
+			Msisdn string `json:"msisdn"`
+		} `json:"payee"`
+		Transaction struct {
+			Amount string `json:"amount"`
+			ID     string `json:"id"`
+		} `json:"transaction"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.store(&Transaction{ID: req.Transaction.ID, Msisdn: req.Payee.Msisdn, Amount: req.Transaction.Amount, Status: "TS"})
+	writeTransaction(w, req.Transaction.ID, "TS", "")
+}
+
+func (s *Server) handleEnquiry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeTransaction(w, "", "TS", "")
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/standard/v1/payments/")
+	t, ok := s.Transaction(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeTransaction(w, t.ID, t.Status, t.AirtelMoneyID)
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"data":   map[string]string{"balance": "1000.00", "currency": "KES", "account_status": "ACTIVE"},
+		"status": map[string]any{"code": "200", "message": "SUCCESS", "success": true},
+	})
+}
+
+func (s *Server) store(t *Transaction) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.transactions[t.ID] = t
+}
+
+func writeTransaction(w http.ResponseWriter, id, status, airtelMoneyID string) {
+	writeJSON(w, map[string]any{
+		"data": map[string]any{"transaction": map[string]string{
+			"id":              id,
+			"status":          status,
+			"airtel_money_id": airtelMoneyID,
+		}},
+		"status": map[string]any{"code": "200", "message": "SUCCESS", "result_code": "ESB000010", "success": true},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,37 @@
+package airtel
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"paydex/provider"
+)
+
+// ParseCallback decodes the body posted to the callback url.
+func ParseCallback(r io.Reader) (*CallbackBody, error) {
+	var callback CallbackBody
+	if err := json.NewDecoder(r).Decode(&callback); err != nil {
+		return nil, err
+	}
+	if IsEmpty(callback.Transaction.ID) {
+		return nil, errors.New("callback transaction id is required")
+	}
+	return &callback, nil
+}
+
+// Status maps the callback status code to the provider status.
+func (c *CallbackBody) Status() provider.Status {
+	return transactionStatus(c.Transaction.StatusCode)
+}
+
+func transactionStatus(code string) provider.Status {
+	switch code {
+	case StatusSuccess:
+		return provider.StatusCompleted
+	case StatusFailed:
+		return provider.StatusFailed
+	default:
+		return provider.StatusPending
+	}
+}
@@ -0,0 +1,156 @@
+package airtel
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transaction status codes returned by enquiries and callbacks.
+const (
+	// StatusSuccess transaction completed.
+	StatusSuccess = "TS"
+	// StatusFailed transaction failed.
+	StatusFailed = "TF"
+	// StatusAmbiguous the outcome is unknown and should be enquired again.
+	StatusAmbiguous = "TA"
+	// StatusInProgress the customer has not yet acted on the push.
+	StatusInProgress = "TIP"
+)
+
+type tokenRequest struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	GrantType    string `json:"grant_type"`
+}
+
+type AccessTokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+	TokenType   string      `json:"token_type"`
+	ExpireTime  time.Time   `json:"-"`
+}
+
+// ResponseStatus is included in every Open API response.
+type ResponseStatus struct {
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+	ResultCode   string `json:"result_code"`
+	ResponseCode string `json:"response_code"`
+	Success      bool   `json:"success"`
+}
+
+type Subscriber struct {
+	Country  string `json:"country"`
+	Currency string `json:"currency"`
+	// msisdn without the country code.
+	Msisdn string `json:"msisdn"`
+}
+
+type Transaction struct {
+	Amount   string `json:"amount"`
+	Country  string `json:"country,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	// ID is our unique id for the transaction.
+	ID string `json:"id"`
+}
+
+// CollectionRequestBody sends a ussd push to the subscriber.
+type CollectionRequestBody struct {
+	Reference   string      `json:"reference"`
+	Subscriber  Subscriber  `json:"subscriber"`
+	Transaction Transaction `json:"transaction"`
+}
+
+func (s *CollectionRequestBody) Validate() error {
+	if IsEmpty(s.Reference) {
+		return errors.New("reference is required")
+	}
+	if IsEmpty(s.Subscriber.Msisdn) {
+		return errors.New("msisdn is required")
+	}
+	if IsEmpty(s.Transaction.ID) {
+		return errors.New("transaction id is required")
+	}
+	return validateAmount(s.Transaction.Amount)
+}
+
+type Payee struct {
+	Msisdn string `json:"msisdn"`
+}
+
+// DisbursementRequestBody sends money to a subscriber.
+type DisbursementRequestBody struct {
+	Payee     Payee  `json:"payee"`
+	Reference string `json:"reference"`
+	// the disbursement pin encrypted with the airtel public key.
+	Pin         string      `json:"pin"`
+	Transaction Transaction `json:"transaction"`
+}
+
+func (s *DisbursementRequestBody) Validate() error {
+	if IsEmpty(s.Payee.Msisdn) {
+		return errors.New("msisdn is required")
+	}
+	if IsEmpty(s.Pin) {
+		return errors.New("pin is required")
+	}
+	if IsEmpty(s.Transaction.ID) {
+		return errors.New("transaction id is required")
+	}
+	return validateAmount(s.Transaction.Amount)
+}
+
+type RefundRequestBody struct {
+	Transaction struct {
+		AirtelMoneyID string `json:"airtel_money_id"`
+	} `json:"transaction"`
+}
+
+type TransactionResult struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	AirtelMoneyID string `json:"airtel_money_id"`
+	ReferenceID   string `json:"reference_id"`
+}
+
+type TransactionResponse struct {
+	Data struct {
+		Transaction TransactionResult `json:"transaction"`
+	} `json:"data"`
+	Status ResponseStatus `json:"status"`
+}
+
+type BalanceResponse struct {
+	Data struct {
+		Balance       string `json:"balance"`
+		Currency      string `json:"currency"`
+		AccountStatus string `json:"account_status"`
+	} `json:"data"`
+	Status ResponseStatus `json:"status"`
+}
+
+// CallbackBody is posted by airtel to the callback url configured on the portal.
+type CallbackBody struct {
+	Transaction struct {
+		ID            string `json:"id"`
+		Message       string `json:"message"`
+		StatusCode    string `json:"status_code"`
+		AirtelMoneyID string `json:"airtel_money_id"`
+	} `json:"transaction"`
+}
+
+func IsEmpty(s string) bool {
+	return len(strings.TrimSpace(s)) == 0
+}
+
+func validateAmount(amount string) error {
+	i, err := strconv.ParseFloat(amount, 64)
+	if err != nil || i <= 0 {
+		return errors.New("amount should be a string number that is greater than 0")
+	}
+	return nil
+}
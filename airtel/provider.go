@@ -0,0 +1,130 @@
+package airtel
+
+import (
+	"context"
+	"fmt"
+
+	"paydex/money"
+	"paydex/provider"
+
+	"github.com/google/uuid"
+)
+
+// ProviderName is the name airtel is registered under.
+const ProviderName = "airtel"
+
+var _ provider.Provider = (*Airtel)(nil)
+
+func (a *Airtel) Name() string {
+	return ProviderName
+}
+
+// Collect sends a ussd push to the customer.
+func (a *Airtel) Collect(ctx context.Context, req provider.CollectRequest) (*provider.CollectResult, error) {
+	amount, err := a.formatAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	// airtel expects us to generate the transaction id.
+	id := uuid.NewString()
+	res, err := a.CollectionRequest(ctx, CollectionRequestBody{
+		Reference:  req.Reference,
+		Subscriber: Subscriber{Msisdn: req.PhoneNumber},
+		Transaction: Transaction{
+			Amount: amount,
+			ID:     id,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	status := provider.StatusPending
+	if !res.Status.Success {
+		status = provider.StatusFailed
+	}
+	return &provider.CollectResult{
+		TransactionID: id,
+		Status:        status,
+		Message:       res.Status.Message,
+	}, nil
+}
+
+// Payout disburses money to the customer.
+func (a *Airtel) Payout(ctx context.Context, req provider.PayoutRequest) (*provider.PayoutResult, error) {
+	amount, err := a.formatAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	id := uuid.NewString()
+	res, err := a.DisbursementRequest(ctx, DisbursementRequestBody{
+		Payee:     Payee{Msisdn: req.PhoneNumber},
+		Reference: req.Reference,
+		Transaction: Transaction{
+			Amount: amount,
+			ID:     id,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	status := transactionStatus(res.Data.Transaction.Status)
+	if !res.Status.Success {
+		status = provider.StatusFailed
+	}
+	return &provider.PayoutResult{
+		TransactionID: id,
+		Status:        status,
+		Message:       res.Status.Message,
+	}, nil
+}
+
+// Status enquires the state of a collection.
+func (a *Airtel) Status(ctx context.Context, req provider.StatusRequest) (*provider.StatusResult, error) {
+	res, err := a.CollectionEnquiry(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.StatusResult{
+		TransactionID: res.Data.Transaction.ID,
+		Status:        transactionStatus(res.Data.Transaction.Status),
+		ResultCode:    res.Data.Transaction.Status,
+		Message:       res.Data.Transaction.Message,
+	}, nil
+}
+
+// Balance returns the disbursement wallet balance.
+func (a *Airtel) Balance(ctx context.Context, _ provider.BalanceRequest) (*provider.BalanceResult, error) {
+	res, err := a.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := money.Parse(res.Data.Balance, res.Data.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("airtel: balance: %w", err)
+	}
+	return &provider.BalanceResult{
+		Balances: []provider.Balance{{Type: "available", Amount: balance}},
+	}, nil
+}
+
+// Refund refunds a collection, TransactionID is the airtel money id.
+func (a *Airtel) Refund(ctx context.Context, req provider.RefundRequest) (*provider.RefundResult, error) {
+	res, err := a.RefundRequest(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.RefundResult{
+		TransactionID: res.Data.Transaction.AirtelMoneyID,
+		Status:        transactionStatus(res.Data.Transaction.Status),
+		Message:       res.Status.Message,
+	}, nil
+}
+
+// formatAmount converts the amount to the decimal string airtel expects,
+// the wallet currency is fixed by the country the client was created for.
+func (a *Airtel) formatAmount(m money.Money) (string, error) {
+	if m.Currency != a.Currency {
+		return "", fmt.Errorf("airtel: %w: %s", money.ErrCurrencyMismatch, m.Currency)
+	}
+	return m.Decimal(), nil
+}
@@ -0,0 +1,35 @@
+package airtel
+
+import "fmt"
+
+type AURL string
+
+const (
+	LiveURL       AURL = "https://openapi.airtel.africa/"
+	SandboxURL    AURL = "https://openapiuat.airtel.africa/"
+	tokenURL      AURL = "auth/oauth2/token"
+	collectURL    AURL = "merchant/v1/payments/"
+	enquiryURL    AURL = "standard/v1/payments/%s"
+	refundURL     AURL = "standard/v1/payments/refund"
+	disburseURL   AURL = "standard/v1/disbursements/"
+	disburseQuery AURL = "standard/v1/disbursements/%s"
+	balanceURL    AURL = "standard/v1/users/balance"
+)
+
+func (a *Airtel) getURL(u AURL, args ...any) string {
+	path := string(u)
+	if len(args) > 0 {
+		path = fmt.Sprintf(path, args...)
+	}
+	return a.getBaseURL() + path
+}
+
+func (a *Airtel) getBaseURL() string {
+	if a.BaseURL != "" {
+		return a.BaseURL
+	}
+	if !a.Live {
+		return string(SandboxURL)
+	}
+	return string(LiveURL)
+}
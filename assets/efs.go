@@ -2,5 +2,8 @@ package assets
 
 import "embed"
 
+// paydex.swagger.json is generated with the protos, copy it after a proto build.
+//go:generate cp ../pkg/gen/paydex.swagger.json paydex.swagger.json
+
 //go:embed "swagger-ui" "paydex.swagger.json"
 var EmbeddedFiles embed.FS
@@ -0,0 +1,21 @@
+package assets
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestSwaggerSpecInSync(t *testing.T) {
+	generated, err := os.ReadFile("../pkg/gen/paydex.swagger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded, err := EmbeddedFiles.ReadFile("paydex.swagger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated, embedded) {
+		t.Error("assets/paydex.swagger.json is out of date, run go generate ./assets")
+	}
+}
@@ -0,0 +1,118 @@
+// Package auth authenticates the api clients by the api key they send as
+// a bearer token.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"paydex/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type callerKey struct{}
+
+// Caller is the name of the api key the request was authenticated with.
+func Caller(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(callerKey{}).(string)
+	return name, ok
+}
+
+// WithCaller returns a copy of ctx authenticated as the named key.
+func WithCaller(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, callerKey{}, name)
+}
+
+// Authenticator checks the api keys of the current config, so keys can be
+// rotated with a reload.
+type Authenticator struct {
+	conf func() *config.Config
+	// protected are the full method names that require a key.
+	protected map[string]bool
+}
+
+// New requires a key on the protected methods e.g
+// "/PaydexService/Payout", the other methods accept requests without one.
+func New(conf func() *config.Config, protected ...string) *Authenticator {
+	a := &Authenticator{conf: conf, protected: make(map[string]bool, len(protected))}
+	for _, m := range protected {
+		a.protected[m] = true
+	}
+	return a
+}
+
+// authenticate adds the caller to ctx. A key that is sent must be valid
+// even on the methods that do not require one.
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	key := bearer(ctx)
+	if key == "" {
+		if a.protected[method] {
+			return nil, status.Error(codes.Unauthenticated, "an api key is required")
+		}
+		return ctx, nil
+	}
+	name, ok := a.lookup(key)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid api key")
+	}
+	return WithCaller(ctx, name), nil
+}
+
+// lookup compares the hashes of the keys in constant time.
+func (a *Authenticator) lookup(key string) (string, bool) {
+	sum := sha256.Sum256([]byte(key))
+	name, found := "", false
+	for _, k := range a.conf().Auth.APIKeys {
+		want := sha256.Sum256([]byte(k.Key))
+		if subtle.ConstantTimeCompare(sum[:], want[:]) == 1 && !found {
+			name, found = k.Name, true
+		}
+	}
+	return name, found
+}
+
+func bearer(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if key := strings.TrimSpace(strings.TrimPrefix(v, "Bearer ")); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &stream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// stream carries the authenticated context.
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
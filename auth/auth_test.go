@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"paydex/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := &config.Config{}
+	c.Auth.APIKeys = append(c.Auth.APIKeys, struct {
+		Name string
+		Key  string `secret:"true"`
+	}{Name: "ops", Key: "secret"})
+	interceptor := New(func() *config.Config { return c }, "/PaydexService/Payout").UnaryServerInterceptor()
+
+	tests := []struct {
+		name   string
+		method string
+		md     metadata.MD
+		code   codes.Code
+		caller string
+	}{
+		{"protected without key", "/PaydexService/Payout", metadata.MD{}, codes.Unauthenticated, ""},
+		{"protected with key", "/PaydexService/Payout", metadata.Pairs("authorization", "Bearer secret"), codes.OK, "ops"},
+		{"invalid key", "/PaydexService/Payout", metadata.Pairs("authorization", "Bearer guess"), codes.Unauthenticated, ""},
+		{"open without key", "/PaydexService/InitStkPush", metadata.MD{}, codes.OK, ""},
+		{"open with invalid key", "/PaydexService/InitStkPush", metadata.Pairs("authorization", "Bearer guess"), codes.Unauthenticated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var caller string
+			handler := func(ctx context.Context, req any) (any, error) {
+				caller, _ = Caller(ctx)
+				return nil, nil
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.code {
+				t.Fatalf("error = %v, want %s", err, tt.code)
+			}
+			if caller != tt.caller {
+				t.Errorf("caller = %q, want %q", caller, tt.caller)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package breaker stops the requests to a failing service and lets a probe
+// through after a cooldown to find out when it is back.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned instead of sending a request while the circuit is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	// Closed lets the requests through.
+	Closed State = iota
+	// HalfOpen lets a single probe through.
+	HalfOpen
+	// Open fails the requests until the cooldown passed.
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+// Breaker opens after consecutive failures, once the cooldown passed the
+// next request probes the service and closes the circuit when it succeeds.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	listeners []func(from, to State)
+}
+
+// New opens after threshold consecutive failures and probes every cooldown.
+func New(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// OnStateChange calls fn after each change of state, e.g to pause a queue.
+func (b *Breaker) OnStateChange(fn func(from, to State)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, fn)
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow returns ErrOpen while the circuit is open or a probe is in flight,
+// the caller reports the outcome of an allowed request.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			b.mu.Unlock()
+			return ErrOpen
+		}
+		b.set(HalfOpen)
+		return nil
+	case HalfOpen:
+		b.mu.Unlock()
+		return ErrOpen
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+// Success closes the circuit.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	b.failures = 0
+	if b.state == Closed {
+		b.mu.Unlock()
+		return
+	}
+	b.set(Closed)
+}
+
+// Failure opens the circuit after threshold failures in a row or when the probe failed.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	b.failures++
+	if b.state == Open || (b.state == Closed && b.failures < b.threshold) {
+		b.mu.Unlock()
+		return
+	}
+	b.openedAt = b.now()
+	b.set(Open)
+}
+
+// Abandon tells that the request ended without an outcome e.g the caller
+// gave up, a probe is then retried by the next request.
+func (b *Breaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		// the cooldown already passed, the state is not reported as it did not change for the callers.
+		b.state = Open
+	}
+}
+
+// set changes the state and notifies the listeners, it unlocks b.mu.
+func (b *Breaker) set(to State) {
+	from := b.state
+	b.state = to
+	listeners := b.listeners
+	b.mu.Unlock()
+	for _, fn := range listeners {
+		fn(from, to)
+	}
+}
+
+// Transport fails the requests with ErrOpen while the circuit is open, the
+// transport errors e.g timeouts and the 502, 503 and 504 responses are
+// failures. A 500 is an answer of the service, daraja sends business
+// errors such as a transaction being processed with it.
+func (b *Breaker) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{breaker: b, base: base}
+}
+
+type transport struct {
+	breaker *Breaker
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	res, err := t.base.RoundTrip(r)
+	switch {
+	case err != nil && errors.Is(err, context.Canceled):
+		t.breaker.Abandon()
+	case err != nil || unavailable(res.StatusCode):
+		t.breaker.Failure()
+	default:
+		t.breaker.Success()
+	}
+	return res, err
+}
+
+// unavailable tells whether the status means the service or a gateway in
+// front of it is down.
+func unavailable(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package breaker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := New(3, 30*time.Second)
+	b.now = func() time.Time { return now }
+	var changes []State
+	b.OnStateChange(func(_, to State) { changes = append(changes, to) })
+
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	if b.State() != Closed {
+		t.Fatal("a success did not reset the failures")
+	}
+	b.Failure()
+	if b.State() != Open {
+		t.Fatalf("state = %s after 3 failures, want open", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() = %v, want ErrOpen during the cooldown", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() = %v, want the probe allowed", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() = %v, want a single probe", err)
+	}
+	b.Failure()
+	if b.State() != Open {
+		t.Fatal("a failed probe did not open the circuit")
+	}
+
+	now = now.Add(30 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Success()
+	if b.State() != Closed {
+		t.Fatal("a successful probe did not close the circuit")
+	}
+	want := []State{Open, HalfOpen, Open, HalfOpen, Closed}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("changes = %v, want %v", changes, want)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	code := http.StatusServiceUnavailable
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	b := New(2, time.Hour)
+	client := &http.Client{Transport: b.Transport(nil)}
+	// daraja answers business errors with a 500, they are not failures.
+	code = http.StatusInternalServerError
+	for i := 0; i < 3; i++ {
+		res, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if b.State() != Closed {
+		t.Fatalf("state = %s after 500s, want closed", b.State())
+	}
+	code, calls = http.StatusServiceUnavailable, 0
+	for i := 0; i < 2; i++ {
+		res, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Get() error = %v, want ErrOpen", err)
+	}
+	if calls != 2 {
+		t.Errorf("the server got %d requests, want none while open", calls)
+	}
+}
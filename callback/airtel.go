@@ -0,0 +1,116 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"paydex/airtel"
+	"paydex/events"
+	"paydex/metrics"
+	"paydex/provider"
+	"paydex/store"
+	"paydex/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+)
+
+// Airtel applies the collection callbacks to the stored payments. Airtel
+// posts them to the url registered with it, without a per payment token,
+// so the outcome is only applied once the collection enquiry confirms it.
+type Airtel struct {
+	store     store.Store
+	providers *provider.Registry
+	publisher events.Publisher
+}
+
+func NewAirtel(s store.Store, providers *provider.Registry, publisher events.Publisher) *Airtel {
+	return &Airtel{store: s, providers: providers, publisher: publisher}
+}
+
+// Handle applies a collection callback to its payment.
+func (a *Airtel) Handle(ctx context.Context, cb *airtel.CallbackBody) error {
+	payment, err := a.store.GetPaymentByTransactionID(ctx, airtel.ProviderName, cb.Transaction.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return reject("unknown payment")
+	}
+	if err != nil {
+		return err
+	}
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, payment.Trace), "airtel.callback",
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(
+			attribute.String("payment.id", payment.ID),
+			attribute.String("airtel.transaction_id", cb.Transaction.ID),
+			attribute.String("airtel.status_code", cb.Transaction.StatusCode),
+		))
+	defer span.End()
+	if err := a.apply(ctx, cb, payment); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (a *Airtel) apply(ctx context.Context, cb *airtel.CallbackBody, payment *store.Payment) error {
+	if payment.Status != store.PaymentPending {
+		// airtel retries the callbacks it did not see acknowledged.
+		return nil
+	}
+	status := cb.Status()
+	if status == provider.StatusPending {
+		// the final outcome follows in another callback.
+		return nil
+	}
+	if err := a.crossCheck(ctx, cb); err != nil {
+		return err
+	}
+
+	updated := *payment
+	updated.ResultCode = cb.Transaction.StatusCode
+	updated.ResultDesc = cb.Transaction.Message
+	updated.Status = store.PaymentFailed
+	if status == provider.StatusCompleted {
+		updated.Status = store.PaymentCompleted
+		updated.ReceiptNumber = cb.Transaction.AirtelMoneyID
+	}
+	updated.UpdatedAt = time.Now()
+	if err := a.store.SwapPayment(ctx, &updated, store.PaymentPending); errors.Is(err, store.ErrConflict) {
+		// the reconciler or a retried callback applied the outcome first.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	metrics.StkPushResolved(updated.Provider, string(updated.Status), updated.ResultCode)
+	if err := a.publisher.Publish(ctx, events.NewPaymentEvent(&updated)); err != nil {
+		slog.Error("failed to publish payment event", err, "payment_id", updated.ID)
+	}
+	return nil
+}
+
+// crossCheck accepts the callback only when the collection enquiry reports
+// the same outcome.
+func (a *Airtel) crossCheck(ctx context.Context, cb *airtel.CallbackBody) error {
+	p, err := a.providers.Get(airtel.ProviderName)
+	if err != nil {
+		return err
+	}
+	res, err := p.Status(ctx, provider.StatusRequest{TransactionID: cb.Transaction.ID})
+	if err != nil {
+		return fmt.Errorf("unable to cross check callback: %w", err)
+	}
+	switch res.Status {
+	case cb.Status():
+		return nil
+	case provider.StatusPending:
+		// leave it to the reconciler.
+		return errors.New("collection enquiry is still pending")
+	default:
+		return reject("callback does not match the collection enquiry")
+	}
+}
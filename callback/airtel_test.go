@@ -0,0 +1,76 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"paydex/airtel"
+	"paydex/money"
+	"paydex/mpesa"
+	"paydex/provider"
+	"paydex/store"
+)
+
+type airtelProvider struct {
+	statusProvider
+}
+
+func (p *airtelProvider) Name() string { return airtel.ProviderName }
+
+func TestAirtel_Callback(t *testing.T) {
+	tests := []struct {
+		name     string
+		enquiry  provider.Status
+		want     store.PaymentStatus
+		rejected bool
+	}{
+		{name: "confirmed", enquiry: provider.StatusCompleted, want: store.PaymentCompleted},
+		{name: "forged", enquiry: provider.StatusFailed, want: store.PaymentPending, rejected: true},
+		{name: "enquiry pending", enquiry: provider.StatusPending, want: store.PaymentPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewMemoryStore()
+			if err := s.CreatePayment(ctx, &store.Payment{
+				ID:            "p1",
+				Provider:      airtel.ProviderName,
+				Status:        store.PaymentPending,
+				Amount:        money.Money{Minor: 1000, Currency: "KES"},
+				TransactionID: "8e5a3b7c",
+			}); err != nil {
+				t.Fatal(err)
+			}
+			distributor := &recordingDistributor{}
+			handler := NewInbox(s, distributor, mpesa.SourceFilter{}).AirtelHandler()
+			body := `{"transaction":{"id":"8e5a3b7c","message":"Paid","status_code":"TS","airtel_money_id":"MP210603.1234.L06941"}}`
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callbacks/airtel", strings.NewReader(body)))
+			if w.Code != http.StatusOK || len(distributor.ids) != 1 {
+				t.Fatalf("status = %d, queued %d, want the callback archived and queued", w.Code, len(distributor.ids))
+			}
+
+			publisher := &recordingPublisher{}
+			providers := provider.NewRegistry(airtel.ProviderName, &airtelProvider{statusProvider{status: tt.enquiry}})
+			processor := NewProcessor(s, NewMpesa(s, providers, publisher), NewAirtel(s, providers, publisher))
+			c, err := processor.Process(ctx, distributor.ids[0])
+			if tt.rejected != errors.Is(err, mpesa.ErrCallbackRejected) {
+				t.Errorf("Process() error = %v, rejected = %v", err, tt.rejected)
+			}
+			if c.Provider != airtel.ProviderName {
+				t.Errorf("callback provider = %q, want airtel", c.Provider)
+			}
+			p, _ := s.GetPayment(ctx, "p1")
+			if p.Status != tt.want {
+				t.Errorf("payment status = %v, want %v", p.Status, tt.want)
+			}
+			if tt.want == store.PaymentCompleted && (p.ReceiptNumber != "MP210603.1234.L06941" || len(publisher.events) != 1) {
+				t.Errorf("receipt = %q, events = %d", p.ReceiptNumber, len(publisher.events))
+			}
+		})
+	}
+}
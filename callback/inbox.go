@@ -0,0 +1,170 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"paydex/airtel"
+	"paydex/mpesa"
+	"paydex/store"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+// Callback kinds, the provider and the kind select how a stored callback
+// is processed.
+const (
+	KindStk    = "stk"
+	KindResult = "result"
+	// KindCollection is the outcome of an airtel ussd push.
+	KindCollection = "collection"
+)
+
+// maxBodySize is the largest callback body that is archived.
+const maxBodySize = 1 << 20
+
+// ErrMalformed is returned for callbacks that cannot be parsed,
+// processing them again does not help until the parser is fixed.
+var ErrMalformed = errors.New("malformed callback")
+
+// Distributor queues the processing of a stored callback.
+type Distributor interface {
+	DistributeTaskProcessCallback(ctx context.Context, callbackID string, opts ...asynq.Option) error
+}
+
+// Inbox archives the raw provider callbacks before they are parsed and
+// queues them, the worker applies them with a Processor.
+type Inbox struct {
+	store       store.Store
+	distributor Distributor
+	source      mpesa.SourceFilter
+}
+
+func NewInbox(s store.Store, distributor Distributor, source mpesa.SourceFilter) *Inbox {
+	return &Inbox{store: s, distributor: distributor, source: source}
+}
+
+// Handler receives the mpesa callbacks of the kind.
+func (i *Inbox) Handler(kind string) http.Handler {
+	return i.handler(mpesa.ProviderName, kind, mpesa.Acknowledge)
+}
+
+// AirtelHandler receives the airtel collection callbacks, airtel retries
+// the callbacks that do not get a 200 response.
+func (i *Inbox) AirtelHandler() http.Handler {
+	return i.handler(airtel.ProviderName, KindCollection, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func (i *Inbox) handler(provider, kind string, acknowledge func(http.ResponseWriter)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		// the source networks are the safaricom ones.
+		if provider == mpesa.ProviderName && !i.source.Allowed(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "unable to read body", http.StatusBadRequest)
+			return
+		}
+		c, err := i.Receive(r.Context(), provider, kind, body, r)
+		if err != nil {
+			slog.Error("failed to receive callback", err, "provider", provider, "kind", kind)
+			http.Error(w, "unable to receive callback", http.StatusInternalServerError)
+			return
+		}
+		if c.Status == store.CallbackFailed {
+			http.Error(w, c.Error, http.StatusBadRequest)
+			return
+		}
+		acknowledge(w)
+	})
+}
+
+// Receive stores the callback and queues it unless it is malformed
+// or a duplicate of a callback that was already received.
+func (i *Inbox) Receive(ctx context.Context, provider, kind string, body []byte, r *http.Request) (*store.Callback, error) {
+	headers := r.Header.Clone()
+	headers.Del("Authorization")
+	headers.Del("Cookie")
+	c := &store.Callback{
+		ID:         uuid.NewString(),
+		Provider:   provider,
+		Kind:       kind,
+		Body:       body,
+		Headers:    headers,
+		Query:      archivedQuery(r.URL.RawQuery),
+		RemoteAddr: r.RemoteAddr,
+		Status:     store.CallbackReceived,
+		ReceivedAt: time.Now(),
+	}
+	key, err := dedupeKey(c)
+	if err != nil {
+		c.Status = store.CallbackFailed
+		c.Error = err.Error()
+		return c, i.store.SaveCallback(ctx, c)
+	}
+	c.DedupeKey = key
+	first, err := i.store.IndexCallback(ctx, key, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if first != c.ID {
+		// the providers retry callbacks they did not see acknowledged in time.
+		c.Status = store.CallbackDuplicate
+		c.DuplicateOf = first
+		return c, i.store.SaveCallback(ctx, c)
+	}
+	if err := i.store.SaveCallback(ctx, c); err != nil {
+		return nil, err
+	}
+	if err := i.distributor.DistributeTaskProcessCallback(ctx, c.ID); err != nil {
+		// the callback is archived, it can be replayed.
+		slog.Error("failed to queue callback", err, "callback_id", c.ID)
+	}
+	return c, nil
+}
+
+// dedupeKey identifies the transaction the callback belongs to. The stk key
+// includes the token hash so a forged callback cannot shadow the real one.
+func dedupeKey(c *store.Callback) (string, error) {
+	if c.Provider == airtel.ProviderName {
+		if c.Kind != KindCollection {
+			return "", fmt.Errorf("%w: unknown kind %q", ErrMalformed, c.Kind)
+		}
+		cb, err := airtel.ParseCallback(bytes.NewReader(c.Body))
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+		// a pending callback does not shadow the final one.
+		return "airtel:collection:" + cb.Transaction.ID + ":" + cb.Transaction.StatusCode, nil
+	}
+	switch c.Kind {
+	case KindStk:
+		cb, err := mpesa.ParseStkCallback(bytes.NewReader(c.Body))
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+		return "mpesa:stk:" + cb.CheckoutRequestID + ":" + queryTokenHash(query(c))[:16], nil
+	case KindResult:
+		res, err := mpesa.ParseResultCallback(bytes.NewReader(c.Body))
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+		return "mpesa:result:" + res.ConversationID, nil
+	}
+	return "", fmt.Errorf("%w: unknown kind %q", ErrMalformed, c.Kind)
+}
@@ -0,0 +1,107 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"paydex/money"
+	"paydex/mpesa"
+	"paydex/provider"
+	"paydex/store"
+
+	"github.com/hibiken/asynq"
+)
+
+type recordingDistributor struct {
+	ids []string
+}
+
+func (d *recordingDistributor) DistributeTaskProcessCallback(_ context.Context, id string, _ ...asynq.Option) error {
+	d.ids = append(d.ids, id)
+	return nil
+}
+
+func TestInbox_Receive(t *testing.T) {
+	ctx := context.Background()
+	token, hash, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := store.NewMemoryStore()
+	if err := s.CreatePayment(ctx, &store.Payment{
+		ID:                "p1",
+		Provider:          mpesa.ProviderName,
+		Status:            store.PaymentPending,
+		Amount:            money.Money{Minor: 1000, Currency: "KES"},
+		TransactionID:     "ws_CO_1",
+		CallbackTokenHash: hash,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	distributor := &recordingDistributor{}
+	handler := NewInbox(s, distributor, mpesa.SourceFilter{}).Handler(KindStk)
+	post := func(query, body string) int {
+		r := httptest.NewRequest(http.MethodPost, "/callbacks/mpesa?"+query, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	body := `{"Body":{"stkCallback":{"CheckoutRequestID":"ws_CO_1","ResultCode":1032,"ResultDesc":"Request cancelled by user"}}}`
+
+	for i := 0; i < 2; i++ {
+		if code := post("payment_id=p1&token="+token, body); code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", code, http.StatusOK)
+		}
+	}
+	// a forged callback does not shadow the real one.
+	if code := post("payment_id=p1&token=guessed", body); code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+	if code := post("payment_id=p1&token="+token, `{"Body":{}}`); code != http.StatusBadRequest {
+		t.Fatalf("malformed status = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	callbacks, err := s.ListCallbacks(ctx, store.CallbackFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[store.CallbackStatus]int{}
+	for _, c := range callbacks {
+		statuses[c.Status]++
+		if c.Headers.Get("Authorization") != "" {
+			t.Errorf("authorization header was archived")
+		}
+		if strings.Contains(c.Query, token) || strings.Contains(c.Query, "token=") {
+			t.Errorf("callback token was archived in %q", c.Query)
+		}
+	}
+	if statuses[store.CallbackReceived] != 2 || statuses[store.CallbackDuplicate] != 1 || statuses[store.CallbackFailed] != 1 {
+		t.Fatalf("statuses = %v", statuses)
+	}
+	if len(distributor.ids) != 2 {
+		t.Fatalf("queued %d callbacks, want 2", len(distributor.ids))
+	}
+
+	processor := NewProcessor(s, NewMpesa(s, provider.NewRegistry(mpesa.ProviderName, &statusProvider{}), &recordingPublisher{}), nil)
+	c, err := processor.Process(ctx, distributor.ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != store.CallbackProcessed || c.Attempts != 1 {
+		t.Errorf("callback status = %v, attempts = %d", c.Status, c.Attempts)
+	}
+	p, _ := s.GetPayment(ctx, "p1")
+	if p.Status != store.PaymentFailed {
+		t.Errorf("payment status = %v, want %v", p.Status, store.PaymentFailed)
+	}
+
+	c, err = processor.Process(ctx, distributor.ids[1])
+	if !errors.Is(err, mpesa.ErrCallbackRejected) || c.Status != store.CallbackRejected {
+		t.Errorf("forged callback: status = %v, err = %v", c.Status, err)
+	}
+}
@@ -0,0 +1,208 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"paydex/events"
+	"paydex/metrics"
+	"paydex/mpesa"
+	"paydex/phone"
+	"paydex/provider"
+	"paydex/store"
+	"paydex/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+)
+
+// Mpesa applies stk callbacks to the stored payments once they are
+// authenticated, daraja does not sign its callbacks.
+type Mpesa struct {
+	store     store.Store
+	providers *provider.Registry
+	publisher events.Publisher
+}
+
+func NewMpesa(s store.Store, providers *provider.Registry, publisher events.Publisher) *Mpesa {
+	return &Mpesa{store: s, providers: providers, publisher: publisher}
+}
+
+func reject(reason string) error {
+	return fmt.Errorf("%w: %s", mpesa.ErrCallbackRejected, reason)
+}
+
+// Handle applies an stk callback to its payment, q is the query of the
+// callback url e.g the per payment token.
+func (m *Mpesa) Handle(ctx context.Context, q mpesa.CallbackQuery, cb *mpesa.StkCallback) error {
+	payment, err := m.payment(ctx, q, cb)
+	if errors.Is(err, store.ErrNotFound) {
+		return reject("unknown payment")
+	}
+	if err != nil {
+		return err
+	}
+	// the callback is part of the trace of the request that created the payment.
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, payment.Trace), "mpesa.callback",
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(
+			attribute.String("payment.id", payment.ID),
+			attribute.String("mpesa.checkout_request_id", cb.CheckoutRequestID),
+			attribute.Int("mpesa.result_code", cb.ResultCode),
+		))
+	defer span.End()
+	if err := m.apply(ctx, q, cb, payment); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (m *Mpesa) apply(ctx context.Context, q mpesa.CallbackQuery, cb *mpesa.StkCallback, payment *store.Payment) error {
+	if payment.Provider != mpesa.ProviderName {
+		return reject("payment was not made with mpesa")
+	}
+	// payments created before tokens were issued have no hash and are cross-checked.
+	if payment.CallbackTokenHash != "" && !validQueryToken(q, payment.CallbackTokenHash) {
+		return reject("invalid callback token")
+	}
+	if payment.Status != store.PaymentPending {
+		// daraja may post a callback more than once.
+		return nil
+	}
+	// the callback must be about the push sent for the payment, the status
+	// query would otherwise confirm the outcome of another push.
+	if payment.TransactionID == "" {
+		// the callback arrived before the worker saved the id, it is retried.
+		return errors.New("payment has no checkout request id yet")
+	}
+	if payment.TransactionID != cb.CheckoutRequestID {
+		return reject("checkout request id does not match the payment")
+	}
+
+	if reason := suspicious(payment, cb); reason != "" {
+		slog.Warn("cross checking suspicious mpesa callback", "payment_id", payment.ID,
+			"checkout_request_id", cb.CheckoutRequestID, "reason", reason)
+		if err := m.crossCheck(ctx, payment, cb); err != nil {
+			return err
+		}
+	}
+
+	payment.ResultCode = strconv.Itoa(cb.ResultCode)
+	payment.ResultDesc = cb.ResultDesc
+	payment.Status = store.PaymentFailed
+	if cb.Status() == provider.StatusCompleted {
+		payment.Status = store.PaymentCompleted
+		payment.ReceiptNumber = cb.ReceiptNumber()
+	}
+	payment.UpdatedAt = time.Now()
+	if err := m.store.SwapPayment(ctx, payment, store.PaymentPending); errors.Is(err, store.ErrConflict) {
+		// the reconciler or a retried callback applied the outcome first.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	metrics.StkPushResolved(payment.Provider, string(payment.Status), payment.ResultCode)
+	if err := m.publisher.Publish(ctx, events.NewPaymentEvent(payment)); err != nil {
+		slog.Error("failed to publish payment event", err, "payment_id", payment.ID)
+	}
+	return nil
+}
+
+// HandleResult applies the result of a reversal to its payment, found by
+// the ConversationID. q is the query of the result url, it names the
+// payment of a reversal, payouts are not tracked as payments yet.
+func (m *Mpesa) HandleResult(ctx context.Context, q mpesa.CallbackQuery, res *mpesa.Result) error {
+	payment, err := m.store.GetPaymentByTransactionID(ctx, mpesa.ProviderName, res.ConversationID)
+	if err == nil && payment.ReversalID != res.ConversationID {
+		err = store.ErrNotFound
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		if q.PaymentID != "" {
+			// the result arrived before the reversal id was saved, it is retried.
+			return fmt.Errorf("no reversal with conversation id %s yet", res.ConversationID)
+		}
+		slog.Info("mpesa result", "conversation_id", res.ConversationID, "transaction_id", res.TransactionID,
+			"result_code", res.ResultCode, "result_desc", res.ResultDesc)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if payment.Status != store.PaymentReversing {
+		// daraja may post a result more than once.
+		return nil
+	}
+	updated := *payment
+	updated.Status = store.PaymentReversed
+	if res.ResultCode != 0 {
+		// the reversal failed, it can be requested again.
+		updated.Status = store.PaymentCompleted
+	}
+	updated.UpdatedAt = time.Now()
+	if err := m.store.SwapPayment(ctx, &updated, store.PaymentReversing); errors.Is(err, store.ErrConflict) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	slog.Info("reversal result", "payment_id", payment.ID, "conversation_id", res.ConversationID,
+		"status", updated.Status, "result_code", res.ResultCode, "result_desc", res.ResultDesc)
+	return nil
+}
+
+func (m *Mpesa) payment(ctx context.Context, q mpesa.CallbackQuery, cb *mpesa.StkCallback) (*store.Payment, error) {
+	if q.PaymentID != "" {
+		return m.store.GetPayment(ctx, q.PaymentID)
+	}
+	return m.store.GetPaymentByTransactionID(ctx, mpesa.ProviderName, cb.CheckoutRequestID)
+}
+
+// suspicious returns why the callback does not match the payment.
+func suspicious(payment *store.Payment, cb *mpesa.StkCallback) string {
+	if payment.CallbackTokenHash == "" {
+		return "payment has no callback token"
+	}
+	if cb.Status() != provider.StatusCompleted {
+		return ""
+	}
+	amount, ok := cb.Amount()
+	if !ok || !amount.Equal(payment.Amount) {
+		return "amount does not match"
+	}
+	// safaricom masks the number for some shortcodes, those are not compared.
+	paid, err := phone.Parse(cb.PhoneNumber(), phone.Kenya)
+	if err != nil {
+		return ""
+	}
+	if expected, err := phone.Parse(payment.PhoneNumber, phone.Kenya); err == nil && expected.MSISDN() != paid.MSISDN() {
+		return "phone number does not match"
+	}
+	return ""
+}
+
+// crossCheck accepts the callback only when StkPushQuery reports the same outcome.
+func (m *Mpesa) crossCheck(ctx context.Context, payment *store.Payment, cb *mpesa.StkCallback) error {
+	p, err := m.providers.Get(mpesa.ProviderName)
+	if err != nil {
+		return err
+	}
+	res, err := p.Status(ctx, provider.StatusRequest{TransactionID: payment.TransactionID})
+	if err != nil {
+		return fmt.Errorf("unable to cross check callback: %w", err)
+	}
+	switch res.Status {
+	case cb.Status():
+		return nil
+	case provider.StatusPending:
+		// leave it to the reconciler.
+		return errors.New("status query is still pending")
+	default:
+		return reject("callback does not match the status query")
+	}
+}
@@ -0,0 +1,229 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"paydex/events"
+	"paydex/money"
+	"paydex/mpesa"
+	"paydex/provider"
+	"paydex/store"
+)
+
+type statusProvider struct {
+	provider.Provider
+	status provider.Status
+}
+
+func (p *statusProvider) Name() string { return mpesa.ProviderName }
+
+func (p *statusProvider) Status(_ context.Context, req provider.StatusRequest) (*provider.StatusResult, error) {
+	return &provider.StatusResult{TransactionID: req.TransactionID, Status: p.status}, nil
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e events.Event) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+func paidCallback(checkoutRequestID string, amount float64, phoneNumber float64) *mpesa.StkCallback {
+	return &mpesa.StkCallback{
+		CheckoutRequestID: checkoutRequestID,
+		ResultDesc:        "The service request is processed successfully.",
+		CallbackMetadata: mpesa.CallbackMetadata{Item: []mpesa.Item{
+			{Name: "Amount", Value: amount},
+			{Name: "MpesaReceiptNumber", Value: "NLJ7RT61SV"},
+			{Name: "PhoneNumber", Value: phoneNumber},
+		}},
+	}
+}
+
+func TestMpesa_Handle(t *testing.T) {
+	token, hash, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		query       mpesa.CallbackQuery
+		callback    *mpesa.StkCallback
+		queryStatus provider.Status
+		wantErr     error
+		want        store.PaymentStatus
+	}{
+		{
+			name:     "authentic",
+			query:    mpesa.CallbackQuery{PaymentID: "p1", Token: token},
+			callback: paidCallback("ws_CO_1", 10, 254712345678),
+			want:     store.PaymentCompleted,
+		},
+		{
+			name:     "invalid token",
+			query:    mpesa.CallbackQuery{PaymentID: "p1", Token: "guessed"},
+			callback: paidCallback("ws_CO_1", 10, 254712345678),
+			wantErr:  mpesa.ErrCallbackRejected,
+			want:     store.PaymentPending,
+		},
+		{
+			name:     "unknown payment",
+			query:    mpesa.CallbackQuery{PaymentID: "p2", Token: token},
+			callback: paidCallback("ws_CO_1", 10, 254712345678),
+			wantErr:  mpesa.ErrCallbackRejected,
+			want:     store.PaymentPending,
+		},
+		{
+			name:        "amount mismatch confirmed by status query",
+			query:       mpesa.CallbackQuery{PaymentID: "p1", Token: token},
+			callback:    paidCallback("ws_CO_1", 1, 254712345678),
+			queryStatus: provider.StatusCompleted,
+			want:        store.PaymentCompleted,
+		},
+		{
+			name:        "phone mismatch contradicted by status query",
+			query:       mpesa.CallbackQuery{PaymentID: "p1", Token: token},
+			callback:    paidCallback("ws_CO_1", 10, 254700000000),
+			queryStatus: provider.StatusFailed,
+			wantErr:     mpesa.ErrCallbackRejected,
+			want:        store.PaymentPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewMemoryStore()
+			if err := s.CreatePayment(ctx, &store.Payment{
+				ID:                "p1",
+				Provider:          mpesa.ProviderName,
+				Status:            store.PaymentPending,
+				Amount:            money.Money{Minor: 1000, Currency: "KES"},
+				PhoneNumber:       "+254712345678",
+				TransactionID:     "ws_CO_1",
+				CallbackTokenHash: hash,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			publisher := &recordingPublisher{}
+			m := NewMpesa(s, provider.NewRegistry(mpesa.ProviderName, &statusProvider{status: tt.queryStatus}), publisher)
+
+			err := m.Handle(ctx, tt.query, tt.callback)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Handle() error = %v, want %v", err, tt.wantErr)
+			}
+			p, err := s.GetPayment(ctx, "p1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Status != tt.want {
+				t.Errorf("status = %v, want %v", p.Status, tt.want)
+			}
+			if tt.want == store.PaymentCompleted && (p.ReceiptNumber != "NLJ7RT61SV" || len(publisher.events) != 1) {
+				t.Errorf("receipt = %v, events = %d", p.ReceiptNumber, len(publisher.events))
+			}
+		})
+	}
+}
+
+func TestMpesa_Handle_ForgedCheckout(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	// p2 has no token so its callbacks are cross-checked, ws_CO_1 was paid for p1.
+	for _, p := range []*store.Payment{
+		{ID: "p1", Provider: mpesa.ProviderName, Status: store.PaymentCompleted, TransactionID: "ws_CO_1"},
+		{ID: "p2", Provider: mpesa.ProviderName, Status: store.PaymentPending, TransactionID: "ws_CO_2",
+			Amount: money.Money{Minor: 1000, Currency: "KES"}, PhoneNumber: "+254712345678"},
+	} {
+		if err := s.CreatePayment(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewMpesa(s, provider.NewRegistry(mpesa.ProviderName, &statusProvider{status: provider.StatusCompleted}), &recordingPublisher{})
+
+	err := m.Handle(ctx, mpesa.CallbackQuery{PaymentID: "p2"}, paidCallback("ws_CO_1", 10, 254712345678))
+	if !errors.Is(err, mpesa.ErrCallbackRejected) {
+		t.Fatalf("Handle() error = %v, want ErrCallbackRejected", err)
+	}
+	p, _ := s.GetPayment(ctx, "p2")
+	if p.Status != store.PaymentPending || p.TransactionID != "ws_CO_2" {
+		t.Errorf("payment = %s %s, want it pending with its own checkout", p.Status, p.TransactionID)
+	}
+}
+
+func TestInbox_AllowedNetworks(t *testing.T) {
+	networks, err := mpesa.ParseNetworks(mpesa.SafaricomCallbackIPs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewInbox(store.NewMemoryStore(), &recordingDistributor{}, mpesa.SourceFilter{Networks: networks}).Handler(KindStk)
+	body := `{"Body":{"stkCallback":{"CheckoutRequestID":"ws_CO_1","ResultCode":1032}}}`
+
+	for addr, want := range map[string]int{
+		"196.201.214.200:443": http.StatusOK,
+		"203.0.113.7:443":     http.StatusForbidden,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/callbacks/mpesa?payment_id=p1&token=t", strings.NewReader(body))
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", addr, w.Code, want)
+		}
+	}
+}
+
+func TestMpesa_HandleResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		resultCode int
+		want       store.PaymentStatus
+	}{
+		{name: "reversed", want: store.PaymentReversed},
+		{name: "rejected", resultCode: 2001, want: store.PaymentCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewMemoryStore()
+			if err := s.CreatePayment(ctx, &store.Payment{ID: "p1", Provider: mpesa.ProviderName, Status: store.PaymentReversing, ReversalID: "AG_1"}); err != nil {
+				t.Fatal(err)
+			}
+			m := NewMpesa(s, provider.NewRegistry(mpesa.ProviderName), &recordingPublisher{})
+			res := &mpesa.Result{ConversationID: "AG_1", ResultCode: tt.resultCode}
+
+			if err := m.HandleResult(ctx, mpesa.CallbackQuery{PaymentID: "p1"}, res); err != nil {
+				t.Fatalf("HandleResult() error = %v", err)
+			}
+			payment, _ := s.GetPayment(ctx, "p1")
+			if payment.Status != tt.want {
+				t.Errorf("payment status = %s, want %s", payment.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestMpesa_HandleResult_Untracked(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	if err := s.CreatePayment(ctx, &store.Payment{ID: "p1", Provider: mpesa.ProviderName, Status: store.PaymentReversing}); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMpesa(s, provider.NewRegistry(mpesa.ProviderName), &recordingPublisher{})
+	res := &mpesa.Result{ConversationID: "AG_1"}
+
+	// a payout result.
+	if err := m.HandleResult(ctx, mpesa.CallbackQuery{}, res); err != nil {
+		t.Errorf("HandleResult() error = %v, want payout results ignored", err)
+	}
+	// a reversal result that arrived before the reversal id was saved is retried.
+	if err := m.HandleResult(ctx, mpesa.CallbackQuery{PaymentID: "p1"}, res); err == nil || errors.Is(err, mpesa.ErrCallbackRejected) {
+		t.Errorf("HandleResult() error = %v, want a retryable error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"paydex/airtel"
+	"paydex/mpesa"
+	"paydex/store"
+)
+
+// Processor applies the callbacks stored by the Inbox, a callback can be
+// processed again e.g after a fix to the parser.
+type Processor struct {
+	store  store.Store
+	mpesa  *Mpesa
+	airtel *Airtel
+}
+
+func NewProcessor(s store.Store, m *Mpesa, a *Airtel) *Processor {
+	return &Processor{store: s, mpesa: m, airtel: a}
+}
+
+// Process processes the stored callback and records the outcome on it.
+// Rejected and malformed callbacks return errors that wrap
+// mpesa.ErrCallbackRejected and ErrMalformed, retrying them does not help.
+func (p *Processor) Process(ctx context.Context, id string) (*store.Callback, error) {
+	c, err := p.store.GetCallback(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.Attempts++
+	c.ProcessedAt = time.Now()
+	processErr := p.process(ctx, c)
+	switch {
+	case processErr == nil:
+		c.Status = store.CallbackProcessed
+		c.Error = ""
+	case errors.Is(processErr, mpesa.ErrCallbackRejected):
+		c.Status = store.CallbackRejected
+		c.Error = processErr.Error()
+	default:
+		c.Status = store.CallbackFailed
+		c.Error = processErr.Error()
+	}
+	if err := p.store.UpdateCallback(ctx, c); err != nil {
+		return c, err
+	}
+	return c, processErr
+}
+
+func (p *Processor) process(ctx context.Context, c *store.Callback) error {
+	if c.Provider == airtel.ProviderName {
+		cb, err := airtel.ParseCallback(bytes.NewReader(c.Body))
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+		return p.airtel.Handle(ctx, cb)
+	}
+	switch c.Kind {
+	case KindStk:
+		cb, err := mpesa.ParseStkCallback(bytes.NewReader(c.Body))
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+		return p.mpesa.Handle(ctx, query(c), cb)
+	case KindResult:
+		res, err := mpesa.ParseResultCallback(bytes.NewReader(c.Body))
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+		return p.mpesa.HandleResult(ctx, query(c), res)
+	}
+	return fmt.Errorf("%w: unknown kind %q", ErrMalformed, c.Kind)
+}
+
+func query(c *store.Callback) mpesa.CallbackQuery {
+	values, _ := url.ParseQuery(c.Query)
+	return mpesa.ParseCallbackQuery(values)
+}
@@ -0,0 +1,104 @@
+package callback
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"paydex/mpesa"
+)
+
+// NewToken returns an unguessable callback token and the hash
+// that is stored on the payment in its place.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidToken compares the token with the stored hash in constant time.
+func ValidToken(token, hash string) bool {
+	if token == "" || hash == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(hash)) == 1
+}
+
+// validQueryToken checks the token of the callback url, or its hash when
+// the callback was archived without the token.
+func validQueryToken(q mpesa.CallbackQuery, hash string) bool {
+	if q.TokenHash == "" {
+		return ValidToken(q.Token, hash)
+	}
+	return hash != "" && subtle.ConstantTimeCompare([]byte(q.TokenHash), []byte(hash)) == 1
+}
+
+// queryTokenHash is the hash of the token of the callback url.
+func queryTokenHash(q mpesa.CallbackQuery) string {
+	if q.TokenHash != "" {
+		return q.TokenHash
+	}
+	return HashToken(q.Token)
+}
+
+// archivedQuery replaces the token of the callback url with its hash, the
+// archive must not hold a token that authenticates callbacks.
+func archivedQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// the token cannot be told apart, none of the query is kept.
+		return ""
+	}
+	if token := values.Get("token"); token != "" {
+		values.Set("token_hash", HashToken(token))
+	}
+	values.Del("token")
+	return values.Encode()
+}
+
+// URL adds the payment id and token to the configured callback url.
+func URL(base, paymentID, token string) (string, error) {
+	if base == "" {
+		return "", nil
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("payment_id", paymentID)
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// ResultURL is where mpesa posts the outcome of payouts and reversals,
+// the result endpoint below the configured stk callback url. paymentID is
+// the payment being reversed, empty for payouts.
+func ResultURL(base, paymentID string) (string, error) {
+	if base == "" {
+		return "", nil
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/result"
+	u.RawQuery = ""
+	if paymentID != "" {
+		u.RawQuery = url.Values{"payment_id": {paymentID}}.Encode()
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,294 @@
+// Package cli is the paydex command line client for operators, it talks
+// to the grpc api of a paydex server chosen by a profile.
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"paydex/money"
+	pb "paydex/pkg/gen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const Usage = `usage: paydex <command> [flags] [args]
+
+commands:
+  stk push           request a payment with an stk push
+  payment get ID     show a payment
+  payment list       list payments, oldest first
+  payout             send money to a phone number
+  balance            show the business account balances
+  reverse ID         reverse a completed payment
+  callbacks replay ID
+                     process a stored provider callback again
+
+every command takes -profile, -address, -output table|json and -timeout.
+the api key is read from PAYDEX_API_KEY or the apiKeyFile of the profile.`
+
+// Commands are the first arguments handled by Run.
+var Commands = []string{"stk", "payment", "payout", "balance", "reverse", "callbacks"}
+
+// Env reads the environment, see os.LookupEnv.
+type Env func(key string) (string, bool)
+
+// Run runs the command in args e.g ["payment", "get", ID] and writes the
+// response to stdout.
+func Run(ctx context.Context, args []string, stdout io.Writer, env Env) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+	name := args[0]
+	if len(args) > 1 && (name == "stk" || name == "payment" || name == "callbacks") {
+		name += " " + args[1]
+		args = args[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", name, Usage)
+	}
+	c := &command{name: name, fs: flag.NewFlagSet("paydex "+name, flag.ContinueOnError), stdout: stdout, env: env}
+	c.fs.StringVar(&c.profile, "profile", "", "profile to use, see PAYDEX_PROFILES")
+	c.fs.StringVar(&c.address, "address", "", "grpc address, overrides the profile")
+	c.fs.StringVar(&c.output, "output", "", "table or json, overrides the profile")
+	c.fs.DurationVar(&c.timeout, "timeout", 30*time.Second, "request timeout")
+	return cmd(ctx, c, args[1:])
+}
+
+var commands = map[string]func(ctx context.Context, c *command, args []string) error{
+	"stk push":         stkPush,
+	"payment get":      getPayment,
+	"payment list":     listPayments,
+	"payout":           payout,
+	"balance":          balance,
+	"reverse":          reverse,
+	"callbacks replay": replayCallback,
+}
+
+// command holds the flags shared by the commands.
+type command struct {
+	name    string
+	fs      *flag.FlagSet
+	stdout  io.Writer
+	env     Env
+	profile string
+	address string
+	output  string
+	timeout time.Duration
+}
+
+// parse reads the flags and the positional arguments the command expects.
+func (c *command) parse(args []string, positional ...string) ([]string, error) {
+	if err := c.fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if c.fs.NArg() != len(positional) {
+		usage := "paydex " + c.name + " [flags]"
+		for _, p := range positional {
+			usage += " " + p
+		}
+		return nil, errors.New("usage: " + usage)
+	}
+	return c.fs.Args(), nil
+}
+
+// call connects with the profile and prints the response of fn.
+func (c *command) call(ctx context.Context, fn func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error)) error {
+	profiles, err := LoadProfiles(ProfilesPath(c.env))
+	if err != nil {
+		return err
+	}
+	profile, err := profiles.Resolve(c.profile, c.env)
+	if err != nil {
+		return err
+	}
+	if c.address != "" {
+		profile.Address = c.address
+	}
+	if c.output != "" {
+		profile.Output = c.output
+	}
+	if profile.Output == "" {
+		profile.Output = OutputTable
+	}
+	if profile.Output != OutputTable && profile.Output != OutputJSON {
+		return fmt.Errorf("unknown output %q, use table or json", profile.Output)
+	}
+
+	creds := insecure.NewCredentials()
+	if profile.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.Dial(profile.Address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(apiKeyInterceptor(profile.apiKey)),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	res, err := fn(ctx, pb.NewPaydexServiceClient(conn))
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			return fmt.Errorf("%s: %s", s.Code(), s.Message())
+		}
+		return err
+	}
+	if res == nil {
+		return nil
+	}
+	return printer{w: c.stdout, format: profile.Output}.print(res)
+}
+
+// apiKeyInterceptor sends the api key as a bearer token, the gateway
+// forwards the same authorization header.
+func apiKeyInterceptor(key string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// amountFlags are the flags of the commands that move money.
+type amountFlags struct {
+	phone, amount, currency, description, provider string
+}
+
+func (a *amountFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&a.phone, "phone", "", "mobile number e.g 0712345678")
+	fs.StringVar(&a.amount, "amount", "", "decimal amount e.g 10.50")
+	fs.StringVar(&a.currency, "currency", "KES", "ISO 4217 currency of the amount")
+	fs.StringVar(&a.description, "description", "", "description shown to the customer")
+	fs.StringVar(&a.provider, "provider", "", "provider e.g mpesa, defaults to the server default")
+}
+
+func (a *amountFlags) money() (*pb.Money, error) {
+	if a.phone == "" || a.amount == "" {
+		return nil, errors.New("-phone and -amount are required")
+	}
+	m, err := money.Parse(a.amount, a.currency)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Money{MinorUnits: m.Minor, Currency: m.Currency}, nil
+}
+
+func stkPush(ctx context.Context, c *command, args []string) error {
+	var a amountFlags
+	a.register(c.fs)
+	merchant := c.fs.String("merchant", "", "merchant whose webhooks receive the payment events")
+	if _, err := c.parse(args); err != nil {
+		return err
+	}
+	amount, err := a.money()
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error) {
+		return client.InitStkPush(ctx, &pb.StkPushRequest{
+			PhoneNumber:     a.phone,
+			Amount:          amount,
+			TransactionDesc: a.description,
+			Provider:        a.provider,
+			MerchantId:      *merchant,
+		})
+	})
+}
+
+func getPayment(ctx context.Context, c *command, args []string) error {
+	args, err := c.parse(args, "ID")
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error) {
+		return client.GetPayment(ctx, &pb.GetPaymentRequest{PaymentId: args[0]})
+	})
+}
+
+func listPayments(ctx context.Context, c *command, args []string) error {
+	statusFilter := c.fs.String("status", "", "pending, completed, failed or expired")
+	since := c.fs.Duration("since", 0, "only payments created within the duration e.g 24h")
+	limit := c.fs.Int("limit", 50, "maximum number of payments")
+	if _, err := c.parse(args); err != nil {
+		return err
+	}
+	req := &pb.ListPaymentsRequest{Status: *statusFilter, Limit: int32(*limit)}
+	if *since > 0 {
+		req.CreatedAfter = timestamppb.New(time.Now().Add(-*since))
+	}
+	return c.call(ctx, func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error) {
+		return client.ListPayments(ctx, req)
+	})
+}
+
+func payout(ctx context.Context, c *command, args []string) error {
+	var a amountFlags
+	a.register(c.fs)
+	if _, err := c.parse(args); err != nil {
+		return err
+	}
+	amount, err := a.money()
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error) {
+		return client.Payout(ctx, &pb.PayoutRequest{
+			PhoneNumber: a.phone,
+			Amount:      amount,
+			Description: a.description,
+			Provider:    a.provider,
+		})
+	})
+}
+
+func balance(ctx context.Context, c *command, args []string) error {
+	provider := c.fs.String("provider", "", "provider e.g jenga, defaults to the server default")
+	account := c.fs.String("account", "", "account to query, defaults to the configured account")
+	if _, err := c.parse(args); err != nil {
+		return err
+	}
+	return c.call(ctx, func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error) {
+		return client.GetBalance(ctx, &pb.GetBalanceRequest{Provider: *provider, AccountId: *account})
+	})
+}
+
+func reverse(ctx context.Context, c *command, args []string) error {
+	reason := c.fs.String("reason", "", "reason recorded with the provider")
+	args, err := c.parse(args, "ID")
+	if err != nil {
+		return err
+	}
+	if *reason == "" {
+		return errors.New("-reason is required")
+	}
+	return c.call(ctx, func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error) {
+		return client.ReversePayment(ctx, &pb.ReversePaymentRequest{PaymentId: args[0], Reason: *reason})
+	})
+}
+
+func replayCallback(ctx context.Context, c *command, args []string) error {
+	args, err := c.parse(args, "ID")
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, func(ctx context.Context, client pb.PaydexServiceClient) (proto.Message, error) {
+		return client.ReplayCallback(ctx, &pb.ReplayCallbackRequest{CallbackId: args[0]})
+	})
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "paydex/pkg/gen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeServer struct {
+	pb.UnimplementedPaydexServiceServer
+	authorization []string
+}
+
+func (s *fakeServer) GetPayment(ctx context.Context, in *pb.GetPaymentRequest) (*pb.Payment, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.authorization = md.Get("authorization")
+	if in.PaymentId != "pay-1" {
+		return nil, status.Error(codes.NotFound, "payment not found")
+	}
+	return &pb.Payment{
+		Id:            "pay-1",
+		Status:        "completed",
+		Provider:      "mpesa",
+		Amount:        &pb.Money{MinorUnits: 1050, Currency: "KES"},
+		ReceiptNumber: "RKT1234",
+		CreatedAt:     timestamppb.New(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}, nil
+}
+
+func (s *fakeServer) InitStkPush(context.Context, *pb.StkPushRequest) (*pb.StkPushResponse, error) {
+	return &pb.StkPushResponse{PaymentId: "pay-1", Provider: "mpesa"}, nil
+}
+
+func startServer(t *testing.T) (*fakeServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeServer{}
+	srv := grpc.NewServer()
+	pb.RegisterPaydexServiceServer(srv, fake)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return fake, lis.Addr().String()
+}
+
+func TestRun_PaymentGet(t *testing.T) {
+	fake, address := startServer(t)
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "staging.key")
+	if err := os.WriteFile(keyFile, []byte("secret-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	profiles := filepath.Join(dir, "profiles.toml")
+	if err := os.WriteFile(profiles, []byte(`
+default = "staging"
+[profiles.staging]
+address = "`+address+`"
+apiKeyFile = "`+keyFile+`"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := func(key string) (string, bool) {
+		if key == "PAYDEX_PROFILES" {
+			return profiles, true
+		}
+		return "", false
+	}
+
+	var out bytes.Buffer
+	if err := Run(context.Background(), []string{"payment", "get", "pay-1"}, &out, env); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"pay-1", "completed", "KES 10.50", "RKT1234"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("table output %q does not contain %q", out.String(), want)
+		}
+	}
+	if len(fake.authorization) != 1 || fake.authorization[0] != "Bearer secret-key" {
+		t.Errorf("authorization = %v, want the api key of the profile", fake.authorization)
+	}
+
+	out.Reset()
+	if err := Run(context.Background(), []string{"payment", "get", "-output", "json", "pay-1"}, &out, env); err != nil {
+		t.Fatal(err)
+	}
+	// protojson varies its whitespace, the output is compared decoded.
+	var payment pb.Payment
+	if err := protojson.Unmarshal(out.Bytes(), &payment); err != nil {
+		t.Fatalf("json output %q: %v", out.String(), err)
+	}
+	if payment.ReceiptNumber != "RKT1234" {
+		t.Errorf("receiptNumber = %q, want RKT1234", payment.ReceiptNumber)
+	}
+
+	err := Run(context.Background(), []string{"payment", "get", "pay-2"}, &out, env)
+	if err == nil || !strings.Contains(err.Error(), "NotFound") {
+		t.Errorf("Run() error = %v, want NotFound", err)
+	}
+}
+
+func TestProfiles_Resolve(t *testing.T) {
+	profiles := Profiles{
+		Default: "staging",
+		Profiles: map[string]Profile{
+			"staging":    {Address: "staging:443", TLS: true},
+			"production": {Address: "production:443", TLS: true, Output: OutputJSON},
+		},
+	}
+	env := map[string]string{}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	p, err := profiles.Resolve("", lookup)
+	if err != nil || p.Address != "staging:443" {
+		t.Errorf("Resolve() = %v, %v, want the default profile", p, err)
+	}
+	env["PAYDEX_PROFILE"] = "production"
+	env["PAYDEX_API_KEY"] = "from-env"
+	if p, _ = profiles.Resolve("", lookup); p.Address != "production:443" || p.apiKey != "from-env" {
+		t.Errorf("Resolve() = %v, want production with the api key of the environment", p)
+	}
+	if p, _ = profiles.Resolve("staging", lookup); p.Address != "staging:443" {
+		t.Errorf("Resolve(staging) = %v, the flag should win over PAYDEX_PROFILE", p)
+	}
+	if _, err = profiles.Resolve("unknown", lookup); err == nil {
+		t.Error("Resolve(unknown) should fail")
+	}
+	if p, _ = (Profiles{}).Resolve("", func(string) (string, bool) { return "", false }); p.Address != DefaultAddress {
+		t.Errorf("Resolve() without profiles = %v, want %s", p.Address, DefaultAddress)
+	}
+}
+
+func TestRun_StkPush(t *testing.T) {
+	_, address := startServer(t)
+	var out bytes.Buffer
+	args := []string{"stk", "push", "-address", address, "-phone", "0712345678", "-amount", "10", "-description", "order-42"}
+	if err := Run(context.Background(), args, &out, func(string) (string, bool) { return "", false }); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "pay-1") {
+		t.Errorf("output %q does not contain the payment id", out.String())
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"paydex/money"
+	pb "paydex/pkg/gen"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// printer writes the responses as a table for people or json for scripts.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func (p printer) print(m proto.Message) error {
+	if p.format == OutputJSON {
+		b, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(m)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	switch m := m.(type) {
+	case *pb.Payment:
+		printPayments(tw, m)
+	case *pb.ListPaymentsResponse:
+		printPayments(tw, m.Payments...)
+	case *pb.StkPushResponse:
+		fmt.Fprintln(tw, "PAYMENT\tPROVIDER\tSTATUS")
+		fmt.Fprintf(tw, "%s\t%s\tqueued\n", m.PaymentId, m.Provider)
+	case *pb.ProviderResult:
+		fmt.Fprintln(tw, "PROVIDER\tTRANSACTION\tSTATUS\tMESSAGE")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", m.Provider, m.TransactionId, m.Status, m.Message)
+	case *pb.GetBalanceResponse:
+		fmt.Fprintln(tw, "TYPE\tAMOUNT")
+		for _, b := range m.Balances {
+			fmt.Fprintf(tw, "%s\t%s\n", b.Type, formatMoney(b.Amount))
+		}
+	case *pb.Callback:
+		fmt.Fprintln(tw, "ID\tKIND\tSTATUS\tATTEMPTS\tERROR\tPROCESSED")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", m.Id, m.Kind, m.Status, m.Attempts, m.Error, formatTime(m.ProcessedAt))
+	default:
+		return fmt.Errorf("no table format for %T", m)
+	}
+	return tw.Flush()
+}
+
+func printPayments(w io.Writer, payments ...*pb.Payment) {
+	fmt.Fprintln(w, "ID\tSTATUS\tPROVIDER\tAMOUNT\tPHONE\tRECEIPT\tCREATED")
+	for _, p := range payments {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.Id, p.Status, p.Provider, formatMoney(p.Amount), p.PhoneNumber, p.ReceiptNumber, formatTime(p.CreatedAt))
+	}
+}
+
+func formatMoney(m *pb.Money) string {
+	if m == nil {
+		return ""
+	}
+	// the minor units are shown as is when the currency is unknown.
+	amount, err := money.New(m.MinorUnits, m.Currency)
+	if err != nil {
+		return fmt.Sprintf("%d %s", m.MinorUnits, m.Currency)
+	}
+	return amount.String()
+}
+
+func formatTime(t *timestamppb.Timestamp) string {
+	if t == nil || t.AsTime().IsZero() || t.AsTime().Unix() == 0 {
+		return ""
+	}
+	return t.AsTime().Local().Format(time.RFC3339)
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultAddress is the grpc server used without a profile.
+const DefaultAddress = "localhost:9090"
+
+// Profile is an environment the cli talks to e.g staging or production.
+type Profile struct {
+	// Address of the grpc server e.g paydex.example.com:443.
+	Address string
+	// TLS is required for servers that are not local.
+	TLS bool
+	// APIKeyFile holds the api key, keep the key out of the profiles file.
+	APIKeyFile string
+	// Output is table or json.
+	Output string
+	apiKey string
+}
+
+// Profiles is the profiles file e.g
+//
+//	default = "staging"
+//	[profiles.staging]
+//	address = "paydex.staging.example.com:443"
+//	tls = true
+//	apiKeyFile = "~/.config/paydex/staging.key"
+type Profiles struct {
+	Default  string
+	Profiles map[string]Profile
+}
+
+// ProfilesPath is the profiles file, PAYDEX_PROFILES overrides it.
+func ProfilesPath(lookup Env) string {
+	if path, ok := lookup("PAYDEX_PROFILES"); ok {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "paydex", "profiles.toml")
+}
+
+// LoadProfiles reads the profiles file, a missing file has no profiles.
+func LoadProfiles(path string) (Profiles, error) {
+	var p Profiles
+	if path == "" {
+		return p, nil
+	}
+	if _, err := toml.DecodeFile(path, &p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return p, fmt.Errorf("invalid profiles file: %w", err)
+	}
+	return p, nil
+}
+
+// Resolve picks the named profile, or PAYDEX_PROFILE, or the default one,
+// and applies the PAYDEX_ADDRESS and PAYDEX_API_KEY variables on top.
+func (p Profiles) Resolve(name string, lookup Env) (Profile, error) {
+	if name == "" {
+		name, _ = lookup("PAYDEX_PROFILE")
+	}
+	if name == "" {
+		name = p.Default
+	}
+	var profile Profile
+	if name != "" {
+		var ok bool
+		if profile, ok = p.Profiles[name]; !ok {
+			return profile, fmt.Errorf("unknown profile %q", name)
+		}
+	}
+	if address, ok := lookup("PAYDEX_ADDRESS"); ok {
+		profile.Address = address
+	}
+	if profile.Address == "" {
+		profile.Address = DefaultAddress
+	}
+	if key, ok := lookup("PAYDEX_API_KEY"); ok {
+		profile.apiKey = key
+	} else if profile.APIKeyFile != "" {
+		b, err := os.ReadFile(expandHome(profile.APIKeyFile))
+		if err != nil {
+			return profile, fmt.Errorf("api key: %w", err)
+		}
+		profile.apiKey = strings.TrimSpace(string(b))
+	}
+	return profile, nil
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
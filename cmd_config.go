@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"paydex/config"
+	"paydex/services"
+
+	"github.com/BurntSushi/toml"
+)
+
+const configUsage = `usage: paydex config <command> [flags]
+
+commands:
+  print      print the config after the environment overrides
+  validate   check the config and list all its problems`
+
+// runConfig runs the config subcommands.
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+	switch args[0] {
+	case "print":
+		return printConfig(args[1:])
+	case "validate":
+		return validateConfig(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], configUsage)
+	}
+}
+
+func printConfig(args []string) error {
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	loc := fs.String("config", "", "provide config file location")
+	redacted := fs.Bool("redacted", true, "mask the secrets")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	conf, err := config.MustLoad(*loc)
+	if err != nil {
+		return err
+	}
+	if *redacted {
+		if conf, err = conf.Redacted(); err != nil {
+			return err
+		}
+	}
+	return toml.NewEncoder(os.Stdout).Encode(conf)
+}
+
+func validateConfig(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	loc := fs.String("config", "", "provide config file location")
+	serve := fs.String("serve", "all", "check the servers the serve command needs, serve-api|serve-gateway|worker|scheduler|all")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	roles, ok := serveCommands[*serve]
+	if !ok {
+		return fmt.Errorf("unknown serve command %q", *serve)
+	}
+	conf, err := config.MustLoad(*loc)
+	if err != nil {
+		return err
+	}
+	if err := conf.Validate(services.RequiredServers(&conf, roles...)...); err != nil {
+		return err
+	}
+	fmt.Println("config is valid")
+	return nil
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
 )
@@ -10,8 +12,16 @@ type Config struct {
 		Address string
 		Port    string
 		Timeout int
+		// Upstream is the host:port of the grpc api the gateway of
+		// servers.http dials, the address of servers.grpc when not set.
+		Upstream string
 	}
 	Prod bool
+	// ShutdownTimeout is the seconds the servers get to drain on SIGTERM,
+	// 30 when not set.
+	ShutdownTimeout int
+	// DefaultProvider handles requests that do not name a provider.
+	DefaultProvider string
 
 	Redis struct {
 		Address string
@@ -20,21 +30,171 @@ type Config struct {
 		Timeout int
 	}
 	Mpesa struct {
-		ConsumerKey    string
-		ConsumerSecret string
-		PassKey        string
+		ConsumerKey    string `secret:"true"`
+		ConsumerSecret string `secret:"true"`
+		PassKey        string `secret:"true"`
 		BusinessName   string
 		BusinessDesc   string
 		ShortCode      string
 		CallbackURL    string
-		Timeout        int
+		// CallbackIPs restricts the stk callbacks to the addresses or cidr ranges,
+		// "safaricom" expands to the published daraja addresses. empty accepts all.
+		CallbackIPs []string
+		// CallbackForwardedFor reads the callback source address from X-Forwarded-For,
+		// enable it only behind a proxy that sets the header.
+		CallbackForwardedFor bool
+		// Live uses the production daraja api instead of the sandbox.
+		Live    bool
+		Timeout int
+		// used for b2c payouts and reversals.
+		InitiatorName      string
+		SecurityCredential string `secret:"true"`
+		B2CShortCode       string
+		// RequestsPerSecond caps the daraja requests of all the instances
+		// together, 0 disables the limit. Burst defaults to the rate.
+		RequestsPerSecond float64
+		Burst             int
+		// BreakerFailures consecutive 5xx or timed out requests open the circuit
+		// breaker, 5 when not set. The stk pushes are paused while it is open and
+		// daraja is probed every BreakerCooldown seconds, 30 when not set.
+		BreakerFailures int
+		BreakerCooldown int
+		// ShareAccessToken shares the daraja access token between the
+		// instances through redis instead of each requesting its own.
+		ShareAccessToken bool
+	}
+	Airtel struct {
+		ClientID     string
+		ClientSecret string `secret:"true"`
+		// EncryptedPIN is the disbursement pin encrypted with the airtel public key.
+		EncryptedPIN string `secret:"true"`
+		Country      string
+		Currency     string
+		CountryCode  string
+		// BaseURL overrides the airtel host e.g for a stand-in server.
+		BaseURL string
+		Live    bool
+		Timeout int
+	}
+	Currency struct {
+		// Provider is either http or static.
+		Provider string
+		// URL of the exchangerate.host compatible api.
+		URL string
+		// File with the static rates.
+		File string
+		// CacheTTL is how long rates are reused in seconds.
+		CacheTTL int
+		Timeout  int
+	}
+	Reconciliation struct {
+		// Schedule is the cron spec of the reconciliation job e.g "@every 1m".
+		Schedule string
+		// PendingAfter is the seconds a payment stays pending
+		// before its status is queried.
+		PendingAfter int
+		// ExpireAfter is the seconds after which unresolved payments expire.
+		ExpireAfter int
+		// BatchSize is the maximum payments reconciled per run.
+		BatchSize int
+	}
+	Retention struct {
+		// Schedule is the cron spec of the purge job e.g "@daily".
+		Schedule string
+		// DryRun only reports what would be purged.
+		DryRun bool
+		// days records are kept, 0 keeps them forever.
+		CallbackDays int
+		// PIIDays also applies to the webhook deliveries, their payloads
+		// carry the phone numbers.
+		PIIDays     int
+		PaymentDays int
+	}
+	// Auth lists the api keys the clients send as a bearer token. The
+	// payouts, reversals, balances and payment listings require one.
+	Auth struct {
+		APIKeys []struct {
+			// Name identifies the client in the logs and the rate limits.
+			Name string
+			Key  string `secret:"true"`
+		}
+	}
+	// Merchants are keyed by the merchant id set on payments.
+	Merchants map[string]struct {
+		Name     string
+		Webhooks []struct {
+			// ID identifies the subscription in the delivery log.
+			ID  string
+			URL string
+			// Events are the event types delivered e.g payment.completed,
+			// all payment events are delivered when empty.
+			Events []string
+			// Secret signs the payloads with HMAC-SHA256.
+			Secret string `secret:"true"`
+		}
+	}
+	Tracing struct {
+		// Exporter is otlp or stdout, tracing is disabled when empty.
+		Exporter string
+		// Endpoint of the otlp grpc collector e.g localhost:4317.
+		Endpoint string
+		Insecure bool
+		// SampleRatio is the fraction of new traces recorded, all when 0.
+		SampleRatio float64
+	}
+	// RateLimits caps the stk pushes per minute, 0 disables a limit.
+	RateLimits struct {
+		PerAPIKey   int
+		PerMerchant int
+		PerPhone    int
+		// OnePendingPerPhone rejects a push while the previous push to the
+		// phone awaits its outcome, for at most PendingTimeout seconds, 120 when not set.
+		OnePendingPerPhone bool
+		PendingTimeout     int
+		// TrustedProxies are the addresses and cidr ranges of the gateways
+		// and load balancers in front of the api, the callers without an api
+		// key are limited by the address these proxies forward. Loopback
+		// addresses are always trusted.
+		TrustedProxies []string
+	}
+	Webhooks struct {
+		// Timeout of a delivery attempt in seconds.
+		Timeout int
+		// MaxAttempts before a delivery is marked as failed.
+		MaxAttempts int
+	}
+	Jenga struct {
+		Username       string
+		Password       string `secret:"true"`
+		APIKey         string `secret:"true"`
+		MerchantCode   string
+		PrivateKeyPath string
+		AccountName    string
+		AccountNumber  string
+		CountryCode    string
+		// Live uses the production jenga api instead of the sandbox.
+		Live    bool
+		Timeout int
 	}
 }
 
+// MustLoad reads the config file and overrides it with the environment,
+// see applyEnv for the variable names.
 func MustLoad(loc string) (Config, error) {
+	return Load(loc, os.LookupEnv, os.Environ())
+}
+
+// Load reads the config file, when loc is not empty, and applies the
+// environment variables on top of it.
+func Load(loc string, lookup LookupEnv, environ []string) (Config, error) {
 	var config Config
-	if _, err := toml.DecodeFile(loc, &config); err != nil {
-		return config, errors.Wrap(err, "Unable to decode config")
+	if loc != "" {
+		if _, err := toml.DecodeFile(loc, &config); err != nil {
+			return config, errors.Wrap(err, "Unable to decode config")
+		}
+	}
+	if err := applyEnv(&config, lookup, environ); err != nil {
+		return config, errors.Wrap(err, "invalid environment")
 	}
 	return config, nil
 }
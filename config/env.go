@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// EnvPrefix starts the environment variables that override the config file.
+const EnvPrefix = "PAYDEX_"
+
+// LookupEnv reads an environment variable, see os.LookupEnv.
+type LookupEnv func(key string) (string, bool)
+
+// applyEnv overrides the config with environment variables named after the
+// field path e.g PAYDEX_MPESA_CONSUMER_SECRET for Mpesa.ConsumerSecret.
+// A variable with the _FILE suffix names a file holding the value, the
+// variable itself takes precedence and setting both is an error.
+// Map entries are addressed by their key e.g PAYDEX_SERVERS_GRPC_PORT and
+// slice elements of structs by index e.g PAYDEX_MERCHANTS_ACME_WEBHOOKS_0_SECRET,
+// other slices are comma separated.
+func applyEnv(c *Config, lookup LookupEnv, environ []string) error {
+	e := &envApplier{lookup: lookup, environ: environ}
+	e.walk(reflect.ValueOf(c).Elem(), strings.TrimSuffix(EnvPrefix, "_"))
+	if len(e.errs) > 0 {
+		return errors.New(strings.Join(e.errs, "; "))
+	}
+	return nil
+}
+
+type envApplier struct {
+	lookup  LookupEnv
+	environ []string
+	errs    []string
+}
+
+func (e *envApplier) walk(v reflect.Value, name string) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			e.walk(v.Field(i), name+"_"+envName(t.Field(i).Name))
+		}
+	case reflect.Map:
+		e.walkMap(v, name)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			for i := 0; i < v.Len(); i++ {
+				e.walk(v.Index(i), name+"_"+strconv.Itoa(i))
+			}
+			return
+		}
+		e.set(v, name)
+	default:
+		e.set(v, name)
+	}
+}
+
+// walkMap overrides the entries of maps of structs, entries that only
+// exist in the environment are added under the lower case key.
+func (e *envApplier) walkMap(v reflect.Value, name string) {
+	if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() != reflect.Struct {
+		return
+	}
+	keys := map[string]string{}
+	for _, k := range v.MapKeys() {
+		keys[envName(k.String())] = k.String()
+	}
+	for _, kv := range e.environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, name+"_") {
+			continue
+		}
+		rest := strings.TrimSuffix(strings.TrimPrefix(key, name+"_"), "_FILE")
+		for _, field := range fieldNames(v.Type().Elem()) {
+			entry := strings.TrimSuffix(rest, "_"+field)
+			if entry == rest || entry == "" {
+				continue
+			}
+			if _, known := keys[entry]; !known {
+				keys[entry] = strings.ToLower(entry)
+			}
+			break
+		}
+	}
+	if len(keys) > 0 && v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	entries := make([]string, 0, len(keys))
+	for entry := range keys {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	for _, entry := range entries {
+		key := reflect.ValueOf(keys[entry])
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		e.walk(elem, name+"_"+entry)
+		v.SetMapIndex(key, elem)
+	}
+}
+
+// set reads the variable or the file named by its _FILE variable into v.
+func (e *envApplier) set(v reflect.Value, name string) {
+	value, ok := e.lookup(name)
+	if file, fromFile := e.lookup(name + "_FILE"); fromFile {
+		if ok {
+			e.errs = append(e.errs, fmt.Sprintf("both %s and %s_FILE are set", name, name))
+			return
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Sprintf("%s_FILE: %s", name, err))
+			return
+		}
+		value, ok = strings.TrimRight(string(b), "\r\n"), true
+	}
+	if !ok {
+		return
+	}
+	if err := setValue(v, value); err != nil {
+		e.errs = append(e.errs, fmt.Sprintf("%s: %s", name, err))
+	}
+}
+
+func setValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func fieldNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, envName(t.Field(i).Name))
+	}
+	// the longest name wins when one field name ends with another.
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return names
+}
+
+// envName converts a field name or map key to upper snake case
+// e.g ConsumerSecret to CONSUMER_SECRET and CallbackIPs to CALLBACK_IPS.
+func envName(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			b.WriteRune('_')
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// an acronym ends before a capitalised word but keeps its plural s.
+			nextWord := i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !(runes[i+1] == 's' && i+2 == len(runes))
+			if unicode.IsLower(prev) || (unicode.IsUpper(prev) && nextWord) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for in, want := range map[string]string{
+		"ConsumerSecret": "CONSUMER_SECRET",
+		"APIKey":         "API_KEY",
+		"CallbackIPs":    "CALLBACK_IPS",
+		"B2CShortCode":   "B2C_SHORT_CODE",
+		"PIIDays":        "PII_DAYS",
+		"CacheTTL":       "CACHE_TTL",
+		"acme-shop":      "ACME_SHOP",
+	} {
+		if got := envName(in); got != want {
+			t.Errorf("envName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoad_Environment(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(file, []byte(`
+[servers.grpc]
+port = "9090"
+[mpesa]
+consumerSecret = "from-file"
+passKey = "from-file"
+[merchants.acme]
+name = "Acme"
+[[merchants.acme.webhooks]]
+url = "https://acme.test/hooks"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(dir, "passkey")
+	if err := os.WriteFile(secret, []byte("from-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"PAYDEX_MPESA_CONSUMER_SECRET":            "from-env",
+		"PAYDEX_MPESA_PASS_KEY_FILE":              secret,
+		"PAYDEX_MPESA_CALLBACK_IPS":               "safaricom, 10.0.0.0/8",
+		"PAYDEX_SERVERS_HTTP_PORT":                "8080",
+		"PAYDEX_MERCHANTS_ACME_WEBHOOKS_0_SECRET": "whsec",
+		"PAYDEX_JENGA_LIVE":                       "true",
+	}
+	var environ []string
+	for k, v := range env {
+		environ = append(environ, k+"="+v)
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	c, err := Load(file, lookup, environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Mpesa.ConsumerSecret != "from-env" || c.Mpesa.PassKey != "from-secret" {
+		t.Errorf("secrets = %q, %q", c.Mpesa.ConsumerSecret, c.Mpesa.PassKey)
+	}
+	if strings.Join(c.Mpesa.CallbackIPs, " ") != "safaricom 10.0.0.0/8" {
+		t.Errorf("callback ips = %v", c.Mpesa.CallbackIPs)
+	}
+	if !c.Jenga.Live {
+		t.Error("jenga.Live was not overridden")
+	}
+	if c.Servers["grpc"].Port != "9090" || c.Servers["http"].Port != "8080" {
+		t.Errorf("servers = %+v", c.Servers)
+	}
+	if hook := c.Merchants["acme"].Webhooks[0]; hook.URL != "https://acme.test/hooks" || hook.Secret != "whsec" {
+		t.Errorf("webhook = %+v", hook)
+	}
+
+	redacted, err := c.Redacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redacted.Mpesa.PassKey != RedactedValue || redacted.Merchants["acme"].Webhooks[0].Secret != RedactedValue {
+		t.Errorf("secrets were not redacted: %+v", redacted.Mpesa)
+	}
+	if c.Merchants["acme"].Webhooks[0].Secret != "whsec" {
+		t.Errorf("redacting changed the config")
+	}
+
+	env["PAYDEX_MPESA_PASS_KEY"] = "both"
+	if _, err := Load(file, lookup, environ); err == nil {
+		t.Errorf("Load() with both PASS_KEY and PASS_KEY_FILE should fail")
+	}
+}
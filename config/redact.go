@@ -0,0 +1,53 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// RedactedValue is shown in place of the secrets.
+const RedactedValue = "[redacted]"
+
+// Redacted returns a copy of the config with the fields tagged
+// secret:"true" masked, e.g for printing.
+func (c Config) Redacted() (Config, error) {
+	// a json round trip copies the maps and slices shared with c.
+	b, err := json.Marshal(c)
+	if err != nil {
+		return c, err
+	}
+	var redacted Config
+	if err := json.Unmarshal(b, &redacted); err != nil {
+		return c, err
+	}
+	redact(reflect.ValueOf(&redacted).Elem())
+	return redacted, nil
+}
+
+func redact(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := v.Field(i)
+			if t.Field(i).Tag.Get("secret") == "true" && f.Kind() == reflect.String {
+				if f.String() != "" {
+					f.SetString(RedactedValue)
+				}
+				continue
+			}
+			redact(f)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			redact(v.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			redact(elem)
+			v.SetMapIndex(k, elem)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Reloader reads the config again on demand and hands valid configs to
+// the subscribers, the current config is swapped atomically.
+type Reloader struct {
+	loc     string
+	lookup  LookupEnv
+	environ func() []string
+	current atomic.Pointer[Config]
+	// mu serialises the reloads.
+	mu          sync.Mutex
+	subscribers []func(old, c *Config)
+}
+
+// NewReloader reloads the file at loc, and the environment, on top of
+// the config the process started with.
+func NewReloader(loc string, c *Config) *Reloader {
+	r := &Reloader{loc: loc, lookup: os.LookupEnv, environ: os.Environ}
+	r.current.Store(c)
+	return r
+}
+
+// Current is the last valid config.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnReload calls fn with the previous and the new config after each
+// successful reload, it must be called before Watch.
+func (r *Reloader) OnReload(fn func(old, c *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload reads and validates the config, an invalid config is rejected
+// and the current one stays in effect. It returns the changed settings
+// that only take effect after a restart.
+func (r *Reloader) Reload() (restart []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, err := Load(r.loc, r.lookup, r.environ())
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	old := r.current.Swap(&c)
+	for _, fn := range r.subscribers {
+		fn(old, &c)
+	}
+	return RestartRequired(old, &c), nil
+}
+
+// Watch reloads the config on SIGHUP and when the config file changes,
+// the file is checked every interval, until ctx is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// without a file only SIGHUP reloads e.g to pick up rotated secret files.
+	var tick <-chan time.Time
+	if r.loc != "" {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	modified := r.modified()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("reloading config", "reason", "SIGHUP")
+		case <-tick:
+			m := r.modified()
+			if m.Equal(modified) {
+				continue
+			}
+			// a half written file is rejected and read again on its next write.
+			modified = m
+			slog.Info("reloading config", "reason", "file changed", "config", r.loc)
+		}
+		restart, err := r.Reload()
+		if err != nil {
+			slog.Error("config reload rejected", err)
+			continue
+		}
+		slog.Info("config reloaded")
+		if len(restart) > 0 {
+			slog.Warn("config changes need a restart", "settings", restart)
+		}
+	}
+}
+
+func (r *Reloader) modified() time.Time {
+	info, err := os.Stat(r.loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// RestartRequired lists the changed settings that are only read at
+// startup e.g the listen addresses, they keep their old value until the
+// process restarts.
+func RestartRequired(old, c *Config) []string {
+	var settings []string
+	changed := func(setting string, a, b any) {
+		if !reflect.DeepEqual(a, b) {
+			settings = append(settings, setting)
+		}
+	}
+
+	names := make([]string, 0, len(old.Servers)+len(c.Servers))
+	for name := range old.Servers {
+		names = append(names, name)
+	}
+	for name := range c.Servers {
+		if _, ok := old.Servers[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a, inOld := old.Servers[name]
+		b, inNew := c.Servers[name]
+		if inOld != inNew {
+			settings = append(settings, "servers."+name)
+			continue
+		}
+		changed("servers."+name+".Address", a.Address, b.Address)
+		changed("servers."+name+".Port", a.Port, b.Port)
+		changed("servers."+name+".Timeout", a.Timeout, b.Timeout)
+		changed("servers."+name+".Upstream", a.Upstream, b.Upstream)
+	}
+	changed("Prod", old.Prod, c.Prod)
+	changed("ShutdownTimeout", old.ShutdownTimeout, c.ShutdownTimeout)
+	changed("DefaultProvider", old.DefaultProvider, c.DefaultProvider)
+	changed("redis", old.Redis, c.Redis)
+
+	// the providers are replaced on reload but not added or removed.
+	changed("mpesa.ConsumerKey", old.Mpesa.ConsumerKey != "", c.Mpesa.ConsumerKey != "")
+	changed("mpesa.CallbackIPs", old.Mpesa.CallbackIPs, c.Mpesa.CallbackIPs)
+	changed("mpesa.CallbackForwardedFor", old.Mpesa.CallbackForwardedFor, c.Mpesa.CallbackForwardedFor)
+	// the limiter and the breaker are shared by the clients replaced on reload.
+	changed("mpesa.RequestsPerSecond", old.Mpesa.RequestsPerSecond, c.Mpesa.RequestsPerSecond)
+	changed("mpesa.Burst", old.Mpesa.Burst, c.Mpesa.Burst)
+	changed("mpesa.BreakerFailures", old.Mpesa.BreakerFailures, c.Mpesa.BreakerFailures)
+	changed("mpesa.BreakerCooldown", old.Mpesa.BreakerCooldown, c.Mpesa.BreakerCooldown)
+	changed("airtel.ClientID", old.Airtel.ClientID != "", c.Airtel.ClientID != "")
+	changed("jenga.APIKey", old.Jenga.APIKey != "", c.Jenga.APIKey != "")
+
+	changed("currency", old.Currency, c.Currency)
+	changed("reconciliation.Schedule", old.Reconciliation.Schedule, c.Reconciliation.Schedule)
+	changed("retention.Schedule", old.Retention.Schedule, c.Retention.Schedule)
+	changed("webhooks.MaxAttempts", old.Webhooks.MaxAttempts, c.Webhooks.MaxAttempts)
+	changed("tracing", old.Tracing, c.Tracing)
+	return settings
+}
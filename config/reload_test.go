@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	write := func(config string) {
+		if err := os.WriteFile(file, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(validConfig)
+	noEnv := func(string) (string, bool) { return "", false }
+	c, err := Load(file, noEnv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReloader(file, &c)
+	r.lookup, r.environ = noEnv, func() []string { return nil }
+	var reloaded []string
+	r.OnReload(func(old, c *Config) {
+		reloaded = append(reloaded, old.Mpesa.PassKey+" -> "+c.Mpesa.PassKey)
+	})
+
+	rotated := strings.Replace(validConfig, `passKey = "passkey"`, `passKey = "rotated"`, 1)
+	write(strings.Replace(rotated, `port = "9090"`, `port = "9091"`, 1))
+	restart, err := r.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"servers.grpc.Port"}; !reflect.DeepEqual(restart, want) {
+		t.Errorf("Reload() restart = %v, want %v", restart, want)
+	}
+	if got := r.Current().Mpesa.PassKey; got != "rotated" {
+		t.Errorf("Current().Mpesa.PassKey = %q, want rotated", got)
+	}
+
+	// an invalid config leaves the current one in effect.
+	write(strings.Replace(rotated, "https://pay.example.com", "not a url", 1))
+	if _, err := r.Reload(); err == nil {
+		t.Error("Reload() accepted an invalid config")
+	}
+	if got := r.Current().Mpesa.CallbackURL; got != "https://pay.example.com/callbacks/mpesa" {
+		t.Errorf("Current().Mpesa.CallbackURL = %q after a rejected reload", got)
+	}
+	if want := []string{"passkey -> rotated"}; !reflect.DeepEqual(reloaded, want) {
+		t.Errorf("subscribers saw %v, want %v", reloaded, want)
+	}
+}
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a problem with one config field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError lists every problem found in the config.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("invalid config, %d problems:", len(e)))
+	for _, fe := range e {
+		lines = append(lines, "  "+fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	shortCodePattern = regexp.MustCompile(`^[0-9]{5,7}$`)
+	countryPattern   = regexp.MustCompile(`^[A-Z]{2}$`)
+	currencyPattern  = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// minAPIKeyLength keeps the production api keys hard to guess.
+const minAPIKeyLength = 32
+
+// Validate checks the whole config and returns a ValidationError
+// with all the problems, or nil. servers are the server sections the
+// instance needs, they depend on the roles it runs e.g grpc for the api.
+func (c *Config) Validate(servers ...string) error {
+	v := &validator{prod: c.Prod}
+
+	for _, name := range servers {
+		if _, ok := c.Servers[name]; !ok {
+			v.add("servers."+name, "is required")
+		}
+	}
+	names := make([]string, 0, len(c.Servers))
+	for name := range c.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := c.Servers[name]
+		v.port("servers."+name+".Port", s.Port)
+		v.nonNegative("servers."+name+".Timeout", s.Timeout)
+		if s.Upstream != "" {
+			if _, port, err := net.SplitHostPort(s.Upstream); err != nil {
+				v.add("servers."+name+".Upstream", fmt.Sprintf("%q must be host:port", s.Upstream))
+			} else {
+				v.port("servers."+name+".Upstream", port)
+			}
+		}
+	}
+	v.nonNegative("ShutdownTimeout", c.ShutdownTimeout)
+
+	v.required("redis.Address", c.Redis.Address)
+	v.port("redis.Port", c.Redis.Port)
+	if c.Redis.DB < 0 || c.Redis.DB > 15 {
+		v.add("redis.DB", "must be between 0 and 15")
+	}
+	v.nonNegative("redis.Timeout", c.Redis.Timeout)
+
+	enabled := map[string]bool{
+		"mpesa":  c.Mpesa.ConsumerKey != "",
+		"airtel": c.Airtel.ClientID != "",
+		"jenga":  c.Jenga.APIKey != "",
+	}
+	defaultProvider := c.DefaultProvider
+	if defaultProvider == "" {
+		defaultProvider = "mpesa"
+	}
+	if !enabled[defaultProvider] {
+		v.add("DefaultProvider", fmt.Sprintf("%q is not configured", defaultProvider))
+	}
+
+	if enabled["mpesa"] {
+		v.required("mpesa.ConsumerSecret", c.Mpesa.ConsumerSecret)
+		v.required("mpesa.PassKey", c.Mpesa.PassKey)
+		v.match("mpesa.ShortCode", c.Mpesa.ShortCode, shortCodePattern, "must be a 5 to 7 digit shortcode")
+		if c.Mpesa.B2CShortCode != "" {
+			v.match("mpesa.B2CShortCode", c.Mpesa.B2CShortCode, shortCodePattern, "must be a 5 to 7 digit shortcode")
+		}
+		v.callbackURL("mpesa.CallbackURL", c.Mpesa.CallbackURL)
+		v.nonNegative("mpesa.Timeout", c.Mpesa.Timeout)
+		for i, a := range c.Mpesa.CallbackIPs {
+			if a == "safaricom" {
+				continue
+			}
+			if net.ParseIP(a) == nil {
+				if _, _, err := net.ParseCIDR(a); err != nil {
+					v.add(fmt.Sprintf("mpesa.CallbackIPs[%d]", i), fmt.Sprintf("%q is not an ip address or cidr range", a))
+				}
+			}
+		}
+		if c.Prod && !c.Mpesa.Live {
+			v.add("mpesa.Live", "must be true in production")
+		}
+		if c.Prod && len(c.Mpesa.CallbackIPs) == 0 {
+			v.add("mpesa.CallbackIPs", `must restrict the callbacks in production e.g ["safaricom"]`)
+		}
+	}
+
+	if enabled["airtel"] {
+		v.required("airtel.ClientSecret", c.Airtel.ClientSecret)
+		if c.Airtel.Country != "" {
+			v.match("airtel.Country", c.Airtel.Country, countryPattern, "must be an ISO 3166 country code e.g KE")
+			v.match("airtel.Currency", c.Airtel.Currency, currencyPattern, "must be an ISO 4217 currency code e.g KES")
+		}
+		if c.Airtel.BaseURL != "" {
+			v.url("airtel.BaseURL", c.Airtel.BaseURL)
+		}
+		v.nonNegative("airtel.Timeout", c.Airtel.Timeout)
+		if c.Prod && (!c.Airtel.Live || c.Airtel.BaseURL != "") {
+			v.add("airtel.Live", "must be true without a BaseURL override in production")
+		}
+	}
+
+	if enabled["jenga"] {
+		v.required("jenga.Username", c.Jenga.Username)
+		v.required("jenga.Password", c.Jenga.Password)
+		v.required("jenga.MerchantCode", c.Jenga.MerchantCode)
+		v.required("jenga.PrivateKeyPath", c.Jenga.PrivateKeyPath)
+		v.nonNegative("jenga.Timeout", c.Jenga.Timeout)
+		if c.Prod && !c.Jenga.Live {
+			v.add("jenga.Live", "must be true in production")
+		}
+	}
+
+	switch c.Currency.Provider {
+	case "", "http":
+		if c.Currency.URL != "" {
+			v.url("currency.URL", c.Currency.URL)
+		}
+	case "static":
+		v.required("currency.File", c.Currency.File)
+	default:
+		v.add("currency.Provider", fmt.Sprintf("%q must be http or static", c.Currency.Provider))
+	}
+	v.nonNegative("currency.CacheTTL", c.Currency.CacheTTL)
+	v.nonNegative("currency.Timeout", c.Currency.Timeout)
+
+	v.nonNegative("reconciliation.PendingAfter", c.Reconciliation.PendingAfter)
+	v.nonNegative("reconciliation.ExpireAfter", c.Reconciliation.ExpireAfter)
+	v.nonNegative("reconciliation.BatchSize", c.Reconciliation.BatchSize)
+	v.nonNegative("retention.CallbackDays", c.Retention.CallbackDays)
+	v.nonNegative("retention.PIIDays", c.Retention.PIIDays)
+	v.nonNegative("retention.PaymentDays", c.Retention.PaymentDays)
+
+	keyNames := make(map[string]bool, len(c.Auth.APIKeys))
+	for i, key := range c.Auth.APIKeys {
+		field := fmt.Sprintf("auth.APIKeys[%d]", i)
+		v.required(field+".Name", key.Name)
+		v.required(field+".Key", key.Key)
+		if keyNames[key.Name] {
+			v.add(field+".Name", fmt.Sprintf("%q is used by another key", key.Name))
+		}
+		keyNames[key.Name] = true
+		if v.prod && key.Key != "" && len(key.Key) < minAPIKeyLength {
+			v.add(field+".Key", fmt.Sprintf("must be at least %d characters", minAPIKeyLength))
+		}
+	}
+
+	merchants := make([]string, 0, len(c.Merchants))
+	for id := range c.Merchants {
+		merchants = append(merchants, id)
+	}
+	sort.Strings(merchants)
+	for _, id := range merchants {
+		for i, hook := range c.Merchants[id].Webhooks {
+			field := fmt.Sprintf("merchants.%s.Webhooks[%d]", id, i)
+			v.callbackURL(field+".URL", hook.URL)
+			v.required(field+".Secret", hook.Secret)
+		}
+	}
+	v.nonNegative("mpesa.Burst", c.Mpesa.Burst)
+	v.nonNegative("mpesa.BreakerFailures", c.Mpesa.BreakerFailures)
+	v.nonNegative("mpesa.BreakerCooldown", c.Mpesa.BreakerCooldown)
+	if c.Mpesa.RequestsPerSecond < 0 {
+		v.add("mpesa.RequestsPerSecond", "must not be negative")
+	}
+	v.nonNegative("rateLimits.PerAPIKey", c.RateLimits.PerAPIKey)
+	v.nonNegative("rateLimits.PerMerchant", c.RateLimits.PerMerchant)
+	v.nonNegative("rateLimits.PerPhone", c.RateLimits.PerPhone)
+	v.nonNegative("rateLimits.PendingTimeout", c.RateLimits.PendingTimeout)
+	for i, a := range c.RateLimits.TrustedProxies {
+		if net.ParseIP(a) == nil {
+			if _, _, err := net.ParseCIDR(a); err != nil {
+				v.add(fmt.Sprintf("rateLimits.TrustedProxies[%d]", i), fmt.Sprintf("%q is not an ip address or cidr range", a))
+			}
+		}
+	}
+	v.nonNegative("webhooks.Timeout", c.Webhooks.Timeout)
+	v.nonNegative("webhooks.MaxAttempts", c.Webhooks.MaxAttempts)
+
+	switch c.Tracing.Exporter {
+	case "", "stdout":
+	case "otlp":
+		v.required("tracing.Endpoint", c.Tracing.Endpoint)
+	default:
+		v.add("tracing.Exporter", fmt.Sprintf("%q must be otlp or stdout", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.add("tracing.SampleRatio", "must be between 0 and 1")
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type validator struct {
+	prod bool
+	errs ValidationError
+}
+
+func (v *validator) add(field, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: message})
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+func (v *validator) match(field, value string, pattern *regexp.Regexp, message string) {
+	if !pattern.MatchString(value) {
+		v.add(field, message)
+	}
+}
+
+func (v *validator) port(field, value string) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 65535 {
+		v.add(field, fmt.Sprintf("%q must be a port between 1 and 65535", value))
+	}
+}
+
+func (v *validator) url(field, value string) *url.URL {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		v.add(field, fmt.Sprintf("%q must be an absolute http or https url", value))
+		return nil
+	}
+	return u
+}
+
+// callbackURL is a url providers or merchants are called on, it must use https in production.
+func (v *validator) callbackURL(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+		return
+	}
+	if u := v.url(field, value); u != nil && v.prod && u.Scheme != "https" {
+		v.add(field, "must use https in production")
+	}
+}
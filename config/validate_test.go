@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+const validConfig = `
+[servers.grpc]
+port = "9090"
+[servers.http]
+port = "8080"
+[redis]
+address = "localhost"
+port = "6379"
+[mpesa]
+consumerKey = "key"
+consumerSecret = "secret"
+passKey = "passkey"
+shortCode = "174379"
+callbackURL = "https://pay.example.com/callbacks/mpesa"
+`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		servers []string
+		want    []string
+	}{
+		{name: "valid", config: validConfig, servers: []string{"grpc", "http"}},
+		{
+			name:   "no servers",
+			config: validConfig[strings.Index(validConfig, "[redis]"):],
+		},
+		{
+			name: "all problems are reported",
+			config: `
+[servers.grpc]
+port = "99999"
+[redis]
+address = "localhost"
+port = "6379"
+[mpesa]
+consumerKey = "key"
+shortCode = "17"
+callbackURL = "/callbacks/mpesa"
+callbackIPs = ["safaricom", "not-an-ip"]
+`,
+			servers: []string{"grpc", "http"},
+			want: []string{"servers.http", "servers.grpc.Port", "mpesa.ConsumerSecret", "mpesa.PassKey",
+				"mpesa.ShortCode", "mpesa.CallbackURL", "mpesa.CallbackIPs[1]"},
+		},
+		{
+			name:   "production",
+			config: "prod = true\n" + strings.Replace(validConfig, "https://", "http://", 1),
+			want:   []string{"mpesa.CallbackURL", "mpesa.Live", "mpesa.CallbackIPs"},
+		},
+		{
+			name: "production jenga",
+			config: "prod = true\n" + strings.Replace(validConfig, "[mpesa]", `[jenga]
+username = "user"
+password = "pass"
+apiKey = "key"
+merchantCode = "0011547896523"
+privateKeyPath = "/run/secrets/jenga.pem"
+[mpesa]
+live = true
+callbackIPs = ["safaricom"]`, 1),
+			want: []string{"jenga.Live"},
+		},
+		{
+			name: "api keys",
+			config: validConfig + `
+[[auth.APIKeys]]
+name = "backoffice"
+key = "secret"
+[[auth.APIKeys]]
+name = "backoffice"
+`,
+			want: []string{"auth.APIKeys[1].Key", "auth.APIKeys[1].Name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			if _, err := toml.Decode(tt.config, &c); err != nil {
+				t.Fatal(err)
+			}
+			err := c.Validate(tt.servers...)
+			var fields []string
+			var verr ValidationError
+			if errors.As(err, &verr) {
+				for _, fe := range verr {
+					fields = append(fields, fe.Field)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("Validate() fields = %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package currency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type cachedRate struct {
+	rate    *Rate
+	expires time.Time
+}
+
+// CachedProvider keeps rates from the wrapped provider for the ttl.
+type CachedProvider struct {
+	next RateProvider
+	ttl  time.Duration
+	data map[string]cachedRate
+	lock *sync.RWMutex
+}
+
+func NewCachedProvider(next RateProvider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		next: next,
+		ttl:  ttl,
+		data: make(map[string]cachedRate),
+		lock: &sync.RWMutex{},
+	}
+}
+
+func (p *CachedProvider) Rate(ctx context.Context, from, to string) (*Rate, error) {
+	key := from + "/" + to
+	p.lock.RLock()
+	v, ok := p.data[key]
+	p.lock.RUnlock()
+	if ok && time.Now().Before(v.expires) {
+		return v.rate, nil
+	}
+
+	rate, err := p.next.Rate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.data[key] = cachedRate{rate: rate, expires: time.Now().Add(p.ttl)}
+	return rate, nil
+}
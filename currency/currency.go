@@ -0,0 +1,90 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"paydex/money"
+)
+
+const (
+	KES = "KES"
+	USD = "USD"
+	EUR = "EUR"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Rate is the value of one unit of From in To.
+type Rate struct {
+	From  string
+	To    string
+	Value float64
+	// Timestamp is when the rate was published by the source.
+	Timestamp time.Time
+	Source    string
+}
+
+// RateProvider looks up exchange rates.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (*Rate, error)
+}
+
+// Conversion is the result of converting an amount.
+type Conversion struct {
+	Amount    money.Money
+	Converted money.Money
+	Rate      float64
+	Timestamp time.Time
+	Source    string
+}
+
+type Converter struct {
+	rates RateProvider
+}
+
+func NewConverter(rates RateProvider) *Converter {
+	return &Converter{rates: rates}
+}
+
+// Convert converts the amount to the currency and rounds the result
+// half up to the minor units of the currency.
+func (c *Converter) Convert(ctx context.Context, amount money.Money, to string) (*Conversion, error) {
+	from, to := amount.Currency, Normalize(to)
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("%w: %s", money.ErrInvalidAmount, amount)
+	}
+	rate := &Rate{From: from, To: to, Value: 1, Timestamp: time.Now(), Source: "identity"}
+	if from != to {
+		var err error
+		rate, err = c.rates.Rate(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate.Value, 'f', -1, 64))
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid rate %v for %s/%s", rate.Value, from, to)
+	}
+	converted, err := money.FromRat(r.Mul(r, amount.Rat()), to)
+	if err != nil {
+		return nil, err
+	}
+	return &Conversion{
+		Amount:    amount,
+		Converted: converted,
+		Rate:      rate.Value,
+		Timestamp: rate.Timestamp,
+		Source:    rate.Source,
+	}, nil
+}
+
+// Normalize returns the upper case ISO code.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
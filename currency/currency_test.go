@@ -0,0 +1,86 @@
+package currency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"paydex/money"
+)
+
+func TestConverter_Convert(t *testing.T) {
+	c := NewConverter(NewStaticProvider(map[string]map[string]float64{
+		USD: {KES: 129.55},
+		EUR: {KES: 140.1},
+	}))
+	tests := []struct {
+		name    string
+		amount  money.Money
+		to      string
+		want    money.Money
+		wantErr bool
+	}{
+		{name: "usd to kes", amount: money.Money{Minor: 1000, Currency: USD}, to: KES, want: money.Money{Minor: 129550, Currency: KES}},
+		{name: "eur to kes", amount: money.Money{Minor: 250, Currency: EUR}, to: "kes", want: money.Money{Minor: 35025, Currency: KES}},
+		{name: "inverse rate", amount: money.Money{Minor: 129550, Currency: KES}, to: USD, want: money.Money{Minor: 1000, Currency: USD}},
+		{name: "rounds half up", amount: money.Money{Minor: 1, Currency: USD}, to: KES, want: money.Money{Minor: 130, Currency: KES}},
+		{name: "same currency", amount: money.Money{Minor: 10000, Currency: KES}, to: KES, want: money.Money{Minor: 10000, Currency: KES}},
+		{name: "unknown pair", amount: money.Money{Minor: 1000, Currency: "GBP"}, to: KES, wantErr: true},
+		{name: "zero amount", amount: money.Money{Currency: USD}, to: KES, wantErr: true},
+		{name: "negative amount", amount: money.Money{Minor: -100, Currency: USD}, to: KES, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Convert(context.Background(), tt.amount, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !got.Converted.Equal(tt.want) {
+				t.Errorf("Convert() = %v, want %v", got.Converted, tt.want)
+			}
+		})
+	}
+}
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Rate(_ context.Context, from, to string) (*Rate, error) {
+	p.calls++
+	return &Rate{From: from, To: to, Value: 2, Timestamp: time.Now()}, nil
+}
+
+func TestCachedProvider_Rate(t *testing.T) {
+	next := &countingProvider{}
+	p := NewCachedProvider(next, time.Minute)
+	for i := 0; i < 3; i++ {
+		if _, err := p.Rate(context.Background(), USD, KES); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if next.calls != 1 {
+		t.Errorf("calls = %d, want 1", next.calls)
+	}
+}
+
+func TestHTTPProvider_Rate(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("base") != USD || r.URL.Query().Get("symbols") != KES {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"base":"USD","date":"2023-01-20","rates":{"KES":123.4}}`))
+	}))
+	defer s.Close()
+
+	rate, err := NewHTTPProvider(s.URL, time.Second).Rate(context.Background(), USD, KES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate.Value != 123.4 || rate.Timestamp.Format("2006-01-02") != "2023-01-20" {
+		t.Errorf("Rate() = %+v", rate)
+	}
+}
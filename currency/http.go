@@ -0,0 +1,71 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultRatesURL is the exchangerate.host latest rates endpoint.
+const DefaultRatesURL = "https://api.exchangerate.host/latest"
+
+// HTTPProvider gets rates from an exchangerate.host compatible api.
+type HTTPProvider struct {
+	URL     string
+	TimeOut time.Duration
+}
+
+func NewHTTPProvider(url string, timeOut time.Duration) *HTTPProvider {
+	if url == "" {
+		url = DefaultRatesURL
+	}
+	return &HTTPProvider{URL: url, TimeOut: timeOut}
+}
+
+type latestResponse struct {
+	Success bool               `json:"success"`
+	Base    string             `json:"base"`
+	Date    string             `json:"date"`
+	Rates   map[string]float64 `json:"rates"`
+}
+
+func (p *HTTPProvider) Rate(ctx context.Context, from, to string) (*Rate, error) {
+	q := url.Values{}
+	q.Set("base", from)
+	q.Set("symbols", to)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL+"?"+q.Encode(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := &http.Client{
+		Timeout: p.TimeOut,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("url: %s  status code: %d  body: %s", resp.Request.URL, resp.StatusCode, b)
+	}
+
+	var latest latestResponse
+	if errx := json.NewDecoder(resp.Body).Decode(&latest); errx != nil {
+		return nil, fmt.Errorf("error converting from json: %w", errx)
+	}
+	value, ok := latest.Rates[to]
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: %w", from, to, ErrRateNotFound)
+	}
+	timestamp, err := time.Parse("2006-01-02", latest.Date)
+	if err != nil {
+		timestamp = time.Now()
+	}
+	return &Rate{From: from, To: to, Value: value, Timestamp: timestamp, Source: p.URL}, nil
+}
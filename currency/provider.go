@@ -0,0 +1,32 @@
+package currency
+
+import (
+	"fmt"
+	"paydex/config"
+	"time"
+)
+
+// NewRateProvider builds the rate provider selected in the config.
+func NewRateProvider(c *config.Config) (RateProvider, error) {
+	var rates RateProvider
+	switch c.Currency.Provider {
+	case "", "http":
+		timeout := 10 * time.Second
+		if c.Currency.Timeout > 0 {
+			timeout = time.Duration(c.Currency.Timeout) * time.Second
+		}
+		rates = NewHTTPProvider(c.Currency.URL, timeout)
+	case "static":
+		p, err := LoadStaticProvider(c.Currency.File)
+		if err != nil {
+			return nil, err
+		}
+		rates = p
+	default:
+		return nil, fmt.Errorf("unknown currency provider %q", c.Currency.Provider)
+	}
+	if c.Currency.CacheTTL > 0 {
+		rates = NewCachedProvider(rates, time.Duration(c.Currency.CacheTTL)*time.Second)
+	}
+	return rates, nil
+}
@@ -0,0 +1,50 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// StaticProvider serves fixed rates e.g for tests or when rates are set by finance.
+// rates are keyed by the base then the quote currency i.e {"USD": {"KES": 129.5}}.
+type StaticProvider struct {
+	rates     map[string]map[string]float64
+	timestamp time.Time
+	source    string
+}
+
+func NewStaticProvider(rates map[string]map[string]float64) *StaticProvider {
+	return &StaticProvider{rates: rates, timestamp: time.Now(), source: "static"}
+}
+
+// LoadStaticProvider reads the rates from a json file,
+// the modification time of the file is used as the rate timestamp.
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var rates map[string]map[string]float64
+	if err := json.Unmarshal(b, &rates); err != nil {
+		return nil, fmt.Errorf("unable to decode rates file: %w", err)
+	}
+	return &StaticProvider{rates: rates, timestamp: info.ModTime(), source: path}, nil
+}
+
+// Rate returns the rate or the inverse of the opposite rate.
+func (p *StaticProvider) Rate(_ context.Context, from, to string) (*Rate, error) {
+	if v, ok := p.rates[from][to]; ok && v > 0 {
+		return &Rate{From: from, To: to, Value: v, Timestamp: p.timestamp, Source: p.source}, nil
+	}
+	if v, ok := p.rates[to][from]; ok && v > 0 {
+		return &Rate{From: from, To: to, Value: 1 / v, Timestamp: p.timestamp, Source: p.source}, nil
+	}
+	return nil, fmt.Errorf("%s/%s: %w", from, to, ErrRateNotFound)
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"paydex/store"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slog"
+)
+
+type Type string
+
+const (
+	PaymentCompleted Type = "payment.completed"
+	PaymentFailed    Type = "payment.failed"
+	PaymentExpired   Type = "payment.expired"
+)
+
+// Event is emitted when a payment reaches a final state.
+type Event struct {
+	ID         string
+	Type       Type
+	Payment    store.Payment
+	OccurredAt time.Time
+}
+
+// NewPaymentEvent builds the event matching the payment status.
+func NewPaymentEvent(p *store.Payment) Event {
+	t := PaymentFailed
+	switch p.Status {
+	case store.PaymentCompleted:
+		t = PaymentCompleted
+	case store.PaymentExpired:
+		t = PaymentExpired
+	}
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       t,
+		Payment:    *p,
+		OccurredAt: time.Now(),
+	}
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// LogPublisher logs the events, it is used when nothing else consumes them.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(_ context.Context, e Event) error {
+	slog.Info("payment event", "id", e.ID, "type", e.Type, "payment_id", e.Payment.ID, "status", e.Payment.Status)
+	return nil
+}
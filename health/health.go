@@ -0,0 +1,142 @@
+// Package health runs the readiness checks of the dependencies and
+// reports them over http and the grpc health service.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns an error when the dependency is not usable.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a check.
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the outcome of all checks, it is ok when every required check
+// passed.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs the named checks concurrently.
+type Checker struct {
+	checks map[string]Check
+	// optional checks are reported without failing the report.
+	optional map[string]bool
+	timeout  time.Duration
+}
+
+// NewChecker fails checks that take longer than timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{checks: make(map[string]Check), optional: make(map[string]bool), timeout: timeout}
+}
+
+func (c *Checker) Add(name string, check Check) {
+	c.checks[name] = check
+}
+
+// AddOptional adds a check that is reported but does not make the
+// instance unready, e.g a remote dependency with its own fallback.
+func (c *Checker) AddOptional(name string, check Check) {
+	c.checks[name] = check
+	c.optional[name] = true
+}
+
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		name, check := name, check
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := check(ctx)
+			res := Result{Status: StatusOK, Duration: time.Since(start).Round(time.Millisecond).String()}
+			if err != nil {
+				res.Status = StatusFail
+				res.Error = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = res
+			if err != nil && !c.optional[name] {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// LivenessHandler reports that the process is serving requests.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusOK})
+	})
+}
+
+// ReadinessHandler runs the checks, it responds with 503 when a required
+// one fails.
+func ReadinessHandler(c *Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Watch runs the checks every interval and sets the serving status of
+// the services on the grpc health server until ctx is done.
+func Watch(ctx context.Context, c *Checker, srv *health.Server, interval time.Duration, services ...string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if !c.Run(ctx).OK() {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		for _, service := range services {
+			srv.SetServingStatus(service, status)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	checks := NewChecker(time.Second)
+	checks.Add("store", func(context.Context) error { return nil })
+	checks.Add("mpesa", func(context.Context) error { return errors.New("invalid consumer key") })
+
+	w := httptest.NewRecorder()
+	ReadinessHandler(checks).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	var report Report
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Checks["store"].Status != StatusOK || report.Checks["mpesa"].Error != "invalid consumer key" {
+		t.Errorf("checks = %+v", report.Checks)
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	checks := NewChecker(10 * time.Millisecond)
+	checks.Add("redis", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if report := checks.Run(context.Background()); report.OK() {
+		t.Errorf("report = %+v, want a failed check", report)
+	}
+}
+
+func TestChecker_Optional(t *testing.T) {
+	checks := NewChecker(time.Second)
+	checks.Add("store", func(context.Context) error { return nil })
+	checks.AddOptional("mpesa", func(context.Context) error { return errors.New("daraja unavailable") })
+
+	report := checks.Run(context.Background())
+	if !report.OK() {
+		t.Errorf("report = %+v, want ok despite the optional check", report)
+	}
+	if report.Checks["mpesa"].Status != StatusFail {
+		t.Errorf("mpesa = %+v, want the failure reported", report.Checks["mpesa"])
+	}
+}
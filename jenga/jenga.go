@@ -1,251 +1,319 @@
 package jenga
 
-// import (
-// 	"encoding/json"
-// 	"errors"
-// 	"io/ioutil"
-// 	"net/http"
-// 	"net/url"
-// 	"reflect"
-// 	"strings"
-// )
-
-// type Jenga struct {
-// 	Live           bool
-// 	Username       string
-// 	Password       string
-// 	MerchantCode   string
-// 	ApiKey         string
-// 	PrivateKeyPath string
-// }
-
-// func NewJenga(Username, Password, ApiKey, MerchantCode, PrivateKeyPath string, live bool) Jenga {
-// 	return Jenga{
-// 		Live:         live,
-// 		MerchantCode: MerchantCode,
-
-// 		Password:       Password,
-// 		Username:       Username,
-// 		ApiKey:         ApiKey,
-// 		PrivateKeyPath: PrivateKeyPath,
-// 	}
-
-// }
-
-// ///https://sandbox.jengahq.io/account-test/v2/accounts/balances/countryCode/accountId
-
-// func (J *Jenga) GetEazzyPayMerchants(page, per_page string) (map[string]any, error) {
-// 	queryParameters := make(map[string]string)
-// 	queryParameters["page"] = page
-// 	queryParameters["per_page"] = per_page
-// 	merchants := make(map[string]any)
-// 	err := J.getAndProcessJengaRequest(J.getJengaMerchantsUrl(), "", &merchants, queryParameters, nil)
-
-// 	return merchants, err
-
-// }
-// func (J *Jenga) GetAccountBalance(countryCode, accountId string) (JengaBalance, error) {
-// 	balance := JengaBalance{}
-// 	sigString := joinStrings(countryCode, accountId)
-
-// 	err := J.getAndProcessJengaRequest(J.getAccountBalanceUrl(countryCode, accountId), sigString, &balance, nil, nil)
-// 	return balance, err
-// }
-
-// func (J *Jenga) BankToMobileMoneyTransfer(request BankToMobileMoneyRequest) (*SendMoneyResponse, error) {
-// 	var sendMoneyResponse SendMoneyResponse
-// 	request.Destination.Type = "mobile"
-// 	request.Transfer.Type = "MobileWallet"
-// 	var sigString string
-// 	if request.Destination.WalletName == Equitel {
-// 		sigString = joinStrings(request.Source.AccountNumber, request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference)
-
-// 	} else {
-// 		sigString = joinStrings(request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference, request.Source.AccountNumber)
-
-// 	}
-
-// 	err := J.sendAndProcessJengaRequest(J.getBankToMobileWalletUrl(), sigString, request, &sendMoneyResponse, nil)
-// 	return &sendMoneyResponse, err
-
-// }
-// func (J *Jenga) PesaLinkMoneyTransfer(request PesaLinkRequest) (*PesaLinkResponse, error) {
-// 	var pesaLinkResponse PesaLinkResponse
-// 	///var m map[string]any
-// 	request.Destination.Type = "bank"
-// 	request.Transfer.Type = "PesaLink"
-// 	var sigString string
-
-// 	sigString = joinStrings(request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference, request.Destination.Name, request.Source.AccountNumber)
-
-// 	err := J.sendAndProcessJengaRequest(J.getPesaLinkToBankUrl(), sigString, request, &pesaLinkResponse, nil)
-// 	return &pesaLinkResponse, err
-// }
-// func (J *Jenga) EquityToEquityMoneyTransfer(request PesaLinkRequest) (*PesaLinkResponse, error) {
-// 	request.Destination.Type = "bank"
-// 	var pesaLinkResponse PesaLinkResponse
-// 	request.Transfer.Type = "InternalFundsTransfer"
-// 	sigString := joinStrings(request.Source.AccountNumber, request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference)
-// 	err := J.sendAndProcessJengaRequest(J.getEquityToEquityUrl(), sigString, request, &pesaLinkResponse, nil)
-// 	return &pesaLinkResponse, err
-
-// }
-
-// func (J *Jenga) PurchaseAirtime(airtimeRequest AirtimeRequest) (*AirtimeResponse, error) {
-
-// 	var airTimeResponse AirtimeResponse
-// 	sigString := joinStrings(J.MerchantCode, airtimeRequest.Airtime.Telco, airtimeRequest.Airtime.Amount, airtimeRequest.Airtime.Reference) /// J.MerchantCode + airtimeRequest.Airtime.Telco + airtimeRequest.Airtime.Amount + airtimeRequest.Airtime.Reference
-
-// 	err := J.sendAndProcessJengaRequest(J.getAirTimeUrl(), sigString, airtimeRequest, &airTimeResponse, nil)
-
-// 	return &airTimeResponse, err
-
-// }
-
-// // will verify users
-// // National iD number
-// func (J *Jenga) VerifyUserKyc(identityRequestBody IdentityRequestBody) (*IdentityResponseBody, error) {
-
-// 	var identityResponseBody IdentityResponseBody
-// 	sigString := joinStrings(J.MerchantCode, identityRequestBody.Identity.DocumentNumber, identityRequestBody.Identity.CountryCode)
-// 	err := J.sendAndProcessJengaRequest(J.getKycUrl(), sigString, identityRequestBody, &identityResponseBody, nil)
-// 	return &identityResponseBody, err
-// }
-
-// func joinStrings(items ...string) string {
-// 	var joinedString string
-// 	for _, item := range items {
-// 		joinedString = joinedString + item
-// 	}
-// 	return joinedString
-// }
-// func (J *Jenga) GetAccessToken() (*JengaAccessToken, error) {
-
-// 	data := url.Values{}
-// 	data.Set("username", J.Username)
-// 	data.Set("password", J.Password)
-// 	requestBody := strings.NewReader(data.Encode())
-// 	req, err := http.NewRequest(http.MethodPost, J.getAccessTokenUrl(), requestBody)
-
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	req.Header.Add("Authorization", "Basic "+J.ApiKey)
-// 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-// 	client := &http.Client{
-// 		///Timeout: 15 * time.Second
-// 	}
-// 	resp, err := client.Do(req)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-// 		b, _ := ioutil.ReadAll(resp.Body)
-// 		return nil, &RequestError{Message: string(b), StatusCode: resp.StatusCode}
-
-// 	}
-
-// 	var token JengaAccessToken
-// 	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
-
-// 		return nil, errors.New("error converting from json")
-// 	}
-// 	return &token, nil
-
-// }
-
-// func (J *Jenga) getAndProcessJengaRequest(url, sigString string, response any, queryParameters, extraHeader map[string]string) error {
-// 	if reflect.ValueOf(response).Kind() != reflect.Ptr {
-
-// 		return errors.New("response should be a pointer")
-
-// 	}
-// 	token, err := J.GetAccessToken()
-// 	if err != nil {
-
-// 		return err
-// 	}
-
-// 	headers := make(map[string]string)
-// 	if !IsEmpty(sigString) {
-// 		s := strings.TrimSpace(sigString)
-// 		signature, err := SignSha256DataWithPrivateKey(s, J.PrivateKeyPath)
-// 		if err != nil {
-
-// 			return err
-// 		}
-
-// 		headers["signature"] = signature
-
-// 	}
-
-// 	headers["Content-Type"] = "application/json"
-// 	headers["Authorization"] = "Bearer " + token.AccessToken
-// 	for k, v := range extraHeader {
-// 		headers[k] = v
-// 	}
-// 	resp, err := getRequest(url, headers, queryParameters)
-// 	if err != nil {
-
-// 		return err
-// 	}
-// 	defer resp.Body.Close()
-// 	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-// 		b, _ := ioutil.ReadAll(resp.Body)
-// 		return &RequestError{Message: string(b), StatusCode: resp.StatusCode}
-// 	}
-
-// 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-
-// 		return errors.New("error converting from json")
-// 	}
-
-// 	return nil
-// }
-
-// // make sure response is a pointer
-// func (J *Jenga) sendAndProcessJengaRequest(url, sigString string, data any, response any, extraHeader map[string]string) error {
-
-// 	if reflect.ValueOf(response).Kind() != reflect.Ptr {
-
-// 		return errors.New("response should be a pointer")
-
-// 	}
-// 	token, err := J.GetAccessToken()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	s := strings.TrimSpace(sigString)
-// 	signature, err := SignSha256DataWithPrivateKey(s, J.PrivateKeyPath)
-
-// 	if err != nil {
-
-// 		return err
-// 	}
-// 	headers := make(map[string]string)
-
-// 	headers["signature"] = signature
-// 	headers["Content-Type"] = "application/json"
-// 	headers["Authorization"] = "Bearer " + token.AccessToken
-// 	for k, v := range extraHeader {
-// 		headers[k] = v
-// 	}
-
-// 	resp, err := postRequest(url, data, headers)
-// 	if err != nil {
-
-// 		return err
-// 	}
-// 	defer resp.Body.Close()
-// 	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-// 		b, _ := ioutil.ReadAll(resp.Body)
-// 		return &RequestError{Message: string(b), StatusCode: resp.StatusCode}
-
-// 	}
-
-// 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-// 		return errors.New("error converting from json")
-// 	}
-
-// 	return nil
-// }
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"paydex/metrics"
+	"paydex/provider"
+)
+
+type ClientOption func(*Jenga)
+
+type Jenga struct {
+	// For sandbox use false and for production use true.
+	Live           bool
+	Username       string
+	Password       string
+	MerchantCode   string
+	APIKey         string
+	PrivateKeyPath string
+	TimeOut        time.Duration
+	// the account money is sent from and whose balance is queried
+	// when the request does not provide one.
+	DefaultSource Source
+	hooks         provider.Hooks
+	// transport replaces the one of httpClient, see WithTransport.
+	transport http.RoundTripper
+	// httpClient sends the requests, it is built by New from the options.
+	httpClient *http.Client
+}
+
+func New(username, password, apiKey, merchantCode, privateKeyPath string, opts ...ClientOption) *Jenga {
+	client := &Jenga{
+		Live:           false,
+		Username:       username,
+		Password:       password,
+		APIKey:         apiKey,
+		MerchantCode:   merchantCode,
+		PrivateKeyPath: privateKeyPath,
+		TimeOut:        20 * time.Second,
+		DefaultSource:  Source{CountryCode: KENYA},
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.httpClient = client.newHTTPClient()
+	return client
+}
+
+// newHTTPClient wraps the transport, provider.Transport by default, with
+// the instrumentation and the hooks.
+func (j *Jenga) newHTTPClient() *http.Client {
+	client := &http.Client{}
+	if j.httpClient != nil {
+		c := *j.httpClient
+		client = &c
+	}
+	if client.Timeout == 0 {
+		client.Timeout = j.TimeOut
+	}
+	if j.transport != nil {
+		client.Transport = j.transport
+	}
+	if client.Transport == nil {
+		client.Transport = provider.Transport
+	}
+	client.Transport = j.hooks.Transport(instrument(client.Transport))
+	return client
+}
+
+// WithLiveMode changes from production to sandbox and viceversa.
+func WithLiveMode(mode bool) ClientOption {
+	return func(j *Jenga) {
+		j.Live = mode
+	}
+}
+
+// WithTimeout sets the timeout of each http request to jenga
+// the default is 20 seconds.
+func WithTimeout(timeOut time.Duration) ClientOption {
+	return func(j *Jenga) {
+		j.TimeOut = timeOut
+	}
+}
+
+// WithHTTPClient sends the requests with a copy of c, its transport is
+// wrapped with the metrics and the hooks. The client timeout defaults to
+// the one of WithTimeout.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(j *Jenga) {
+		j.httpClient = c
+	}
+}
+
+// WithTransport sends the requests through rt instead of the connection
+// pool shared by the providers, e.g to stub jenga in tests.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(j *Jenga) {
+		j.transport = rt
+	}
+}
+
+// WithHooks calls the hooks around each request e.g to log them.
+func WithHooks(h provider.Hooks) ClientOption {
+	return func(j *Jenga) {
+		j.hooks = h
+	}
+}
+
+// WithSourceAccount sets the default equity account used for transfers.
+func WithSourceAccount(countryCode, name, accountNumber string) ClientOption {
+	return func(j *Jenga) {
+		j.DefaultSource = Source{
+			CountryCode:   countryCode,
+			Name:          name,
+			AccountNumber: accountNumber,
+		}
+	}
+}
+
+func (j *Jenga) GetEazzyPayMerchants(ctx context.Context, page, perPage string) (map[string]any, error) {
+	queryParameters := make(map[string]string)
+	queryParameters["page"] = page
+	queryParameters["per_page"] = perPage
+	merchants := make(map[string]any)
+	err := j.getAndProcessJengaRequest(ctx, j.getJengaMerchantsURL(), "", &merchants, queryParameters, nil)
+	return merchants, err
+}
+
+func (j *Jenga) GetAccountBalance(ctx context.Context, countryCode, accountID string) (*JengaBalance, error) {
+	var balance JengaBalance
+	sigString := joinStrings(countryCode, accountID)
+	err := j.getAndProcessJengaRequest(ctx, j.getAccountBalanceURL(countryCode, accountID), sigString, &balance, nil, nil)
+	return &balance, err
+}
+
+func (j *Jenga) BankToMobileMoneyTransfer(ctx context.Context, request BankToMobileMoneyRequest) (*SendMoneyResponse, error) {
+	var sendMoneyResponse SendMoneyResponse
+	request.Destination.Type = "mobile"
+	request.Transfer.Type = "MobileWallet"
+	var sigString string
+	if request.Destination.WalletName == Equitel {
+		sigString = joinStrings(request.Source.AccountNumber, request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference)
+	} else {
+		sigString = joinStrings(request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference, request.Source.AccountNumber)
+	}
+
+	err := j.sendAndProcessJengaRequest(ctx, j.getBankToMobileWalletURL(), sigString, request, &sendMoneyResponse, nil)
+	return &sendMoneyResponse, err
+}
+
+func (j *Jenga) PesaLinkMoneyTransfer(ctx context.Context, request PesaLinkRequest) (*PesaLinkResponse, error) {
+	var pesaLinkResponse PesaLinkResponse
+	request.Destination.Type = "bank"
+	request.Transfer.Type = "PesaLink"
+	sigString := joinStrings(request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference, request.Destination.Name, request.Source.AccountNumber)
+
+	err := j.sendAndProcessJengaRequest(ctx, j.getPesaLinkToBankURL(), sigString, request, &pesaLinkResponse, nil)
+	return &pesaLinkResponse, err
+}
+
+func (j *Jenga) EquityToEquityMoneyTransfer(ctx context.Context, request PesaLinkRequest) (*PesaLinkResponse, error) {
+	var pesaLinkResponse PesaLinkResponse
+	request.Destination.Type = "bank"
+	request.Transfer.Type = "InternalFundsTransfer"
+	sigString := joinStrings(request.Source.AccountNumber, request.Transfer.Amount, request.Transfer.CurrencyCode, request.Transfer.Reference)
+
+	err := j.sendAndProcessJengaRequest(ctx, j.getEquityToEquityURL(), sigString, request, &pesaLinkResponse, nil)
+	return &pesaLinkResponse, err
+}
+
+// PurchaseAirtime buys airtime, the telco is detected from the mobile number when empty.
+func (j *Jenga) PurchaseAirtime(ctx context.Context, airtimeRequest AirtimeRequest) (*AirtimeResponse, error) {
+	if airtimeRequest.Airtime.Telco == "" {
+		telco, err := Telco(airtimeRequest.Customer.MobileNumber, airtimeRequest.Customer.CountryCode)
+		if err != nil {
+			return nil, err
+		}
+		airtimeRequest.Airtime.Telco = telco
+	}
+	var airTimeResponse AirtimeResponse
+	sigString := joinStrings(j.MerchantCode, airtimeRequest.Airtime.Telco, airtimeRequest.Airtime.Amount, airtimeRequest.Airtime.Reference)
+
+	err := j.sendAndProcessJengaRequest(ctx, j.getAirTimeURL(), sigString, airtimeRequest, &airTimeResponse, nil)
+	return &airTimeResponse, err
+}
+
+// VerifyUserKyc will verify users National iD number.
+func (j *Jenga) VerifyUserKyc(ctx context.Context, identityRequestBody IdentityRequestBody) (*IdentityResponseBody, error) {
+	var identityResponseBody IdentityResponseBody
+	sigString := joinStrings(j.MerchantCode, identityRequestBody.Identity.DocumentNumber, identityRequestBody.Identity.CountryCode)
+	err := j.sendAndProcessJengaRequest(ctx, j.getKycURL(), sigString, identityRequestBody, &identityResponseBody, nil)
+	return &identityResponseBody, err
+}
+
+func joinStrings(items ...string) string {
+	return strings.Join(items, "")
+}
+
+// GetAccessToken will get the token to be used to query data.
+func (j *Jenga) GetAccessToken(ctx context.Context) (*JengaAccessToken, error) {
+	token, err := j.requestAccessToken(ctx)
+	metrics.TokenRefreshed(ProviderName, err)
+	return token, err
+}
+
+func (j *Jenga) requestAccessToken(ctx context.Context) (*JengaAccessToken, error) {
+	data := url.Values{}
+	data.Set("username", j.Username)
+	data.Set("password", j.Password)
+
+	ctx, cancelFunc := context.WithTimeout(ctx, j.TimeOut)
+	defer cancelFunc()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.getAccessTokenURL(), strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Basic "+j.APIKey)
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &RequestError{Message: string(b), StatusCode: resp.StatusCode, URL: req.URL.String()}
+	}
+
+	var token JengaAccessToken
+	if errx := json.NewDecoder(resp.Body).Decode(&token); errx != nil {
+		return nil, errors.New("error converting from json")
+	}
+	return &token, nil
+}
+
+func (j *Jenga) getAndProcessJengaRequest(ctx context.Context, url, sigString string, response any, queryParameters, extraHeader map[string]string) error {
+	if reflect.ValueOf(response).Kind() != reflect.Ptr {
+		return errors.New("response should be a pointer")
+	}
+	token, err := j.GetAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	if !IsEmpty(sigString) {
+		signature, errx := SignSha256DataWithPrivateKey(strings.TrimSpace(sigString), j.PrivateKeyPath)
+		if errx != nil {
+			return errx
+		}
+		headers["signature"] = signature
+	}
+	headers["Content-Type"] = "application/json"
+	headers["Authorization"] = "Bearer " + token.AccessToken
+	for k, v := range extraHeader {
+		headers[k] = v
+	}
+
+	resp, err := getRequest(ctx, j.httpClient, url, headers, queryParameters)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		b, _ := io.ReadAll(resp.Body)
+		return &RequestError{Message: string(b), StatusCode: resp.StatusCode, URL: url}
+	}
+
+	if errx := json.NewDecoder(resp.Body).Decode(response); errx != nil {
+		return errors.New("error converting from json")
+	}
+	return nil
+}
+
+// make sure response is a pointer.
+func (j *Jenga) sendAndProcessJengaRequest(ctx context.Context, url, sigString string, data, response any, extraHeader map[string]string) error {
+	if reflect.ValueOf(response).Kind() != reflect.Ptr {
+		return errors.New("response should be a pointer")
+	}
+	token, err := j.GetAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	signature, err := SignSha256DataWithPrivateKey(strings.TrimSpace(sigString), j.PrivateKeyPath)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	headers["signature"] = signature
+	headers["Content-Type"] = "application/json"
+	headers["Authorization"] = "Bearer " + token.AccessToken
+	for k, v := range extraHeader {
+		headers[k] = v
+	}
+
+	resp, err := postRequest(ctx, j.httpClient, url, data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		b, _ := io.ReadAll(resp.Body)
+		return &RequestError{Message: string(b), StatusCode: resp.StatusCode, URL: url}
+	}
+
+	if errx := json.NewDecoder(resp.Body).Decode(response); errx != nil {
+		return errors.New("error converting from json")
+	}
+	return nil
+}
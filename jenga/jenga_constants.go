@@ -4,5 +4,6 @@ const Safaricom string = "Safaricom"
 const Equitel string = "Equitel"
 const Airtel string = "Airtel"
 const JengaMpesa string = "Mpesa"
+const JengaAirtel string = "Airtel"
 const KENYA string = "KE"
 const KenyaCurrency string = "KES"
@@ -6,8 +6,9 @@ type JengaBalance struct {
 	Balances []Balances `json:"balances"`
 }
 type Balances struct {
-	Amount float64 `json:"amount,string"`
-	Type   string  `json:"type"`
+	// Amount is a decimal string e.g "1000.50".
+	Amount string `json:"amount"`
+	Type   string `json:"type"`
 }
 
 type JengaAccessToken struct {
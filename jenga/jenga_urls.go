@@ -1,61 +1,55 @@
 package jenga
 
-// import "fmt"
-
-// const jengaTokenUrl = "identity/v2/token"
-// const jengaKycUrl = "customer/v2/identity/verify"
-// const jengaAirTimeUrl = "transaction/v2/airtime"
-// const jengaMerchantsUrl = "transaction/v2/merchants"
-// const jengaBankToMobileWalletUrl = "transaction/v2/remittance#sendmobile"
-// const pesaLinkToBankUrl = "transaction/v2/remittance"
-// const equityToequity = "transaction/v2/remittance#sendeqtybank"
-// const accountBalance = "account/v2/accounts/balances/%s/%s"
-
-// const JengaLiveUrl = "https://api.jengahq.io/"
-// const JengaSandboxUrl = "https://uat.jengahq.io/"
-
-// func (J *Jenga) getBaseUrl() string {
-// 	if !J.Live {
-
-// 		return JengaSandboxUrl
-// 	}
-
-// 	return JengaLiveUrl
-
-// }
-// func (J *Jenga) getAccountBalanceUrl(countryCode, accountId string) string {
-// 	url := fmt.Sprintf(accountBalance, countryCode, accountId)
-// 	return J.getBaseUrl() + url
-
-// }
-
-// func (J *Jenga) getJengaMerchantsUrl() string {
-// 	return J.getBaseUrl() + jengaMerchantsUrl
-// }
-// func (J *Jenga) getBankToMobileWalletUrl() string {
-
-// 	return J.getBaseUrl() + jengaBankToMobileWalletUrl
-// }
-// func (J *Jenga) getPesaLinkToBankUrl() string {
-
-// 	return J.getBaseUrl() + pesaLinkToBankUrl
-// }
-
-// func (J *Jenga) getEquityToEquityUrl() string {
-
-// 	return J.getBaseUrl() + equityToequity
-
-// }
-
-// func (J *Jenga) getAirTimeUrl() string {
-// 	return J.getBaseUrl() + jengaAirTimeUrl
-// }
-// func (J *Jenga) getAccessTokenUrl() string {
-
-// 	return J.getBaseUrl() + jengaTokenUrl
-// }
-
-// func (J *Jenga) getKycUrl() string {
-// 	return J.getBaseUrl() + jengaKycUrl
-
-// }
+import "fmt"
+
+const jengaTokenURL = "identity/v2/token"
+const jengaKycURL = "customer/v2/identity/verify"
+const jengaAirTimeURL = "transaction/v2/airtime"
+const jengaMerchantsURL = "transaction/v2/merchants"
+const jengaBankToMobileWalletURL = "transaction/v2/remittance#sendmobile"
+const pesaLinkToBankURL = "transaction/v2/remittance"
+const equityToequity = "transaction/v2/remittance#sendeqtybank"
+const accountBalance = "account/v2/accounts/balances/%s/%s"
+
+const JengaLiveURL = "https://api.jengahq.io/"
+const JengaSandboxURL = "https://uat.jengahq.io/"
+
+func (j *Jenga) getBaseURL() string {
+	if !j.Live {
+		return JengaSandboxURL
+	}
+	return JengaLiveURL
+}
+
+func (j *Jenga) getAccountBalanceURL(countryCode, accountID string) string {
+	url := fmt.Sprintf(accountBalance, countryCode, accountID)
+	return j.getBaseURL() + url
+}
+
+func (j *Jenga) getJengaMerchantsURL() string {
+	return j.getBaseURL() + jengaMerchantsURL
+}
+
+func (j *Jenga) getBankToMobileWalletURL() string {
+	return j.getBaseURL() + jengaBankToMobileWalletURL
+}
+
+func (j *Jenga) getPesaLinkToBankURL() string {
+	return j.getBaseURL() + pesaLinkToBankURL
+}
+
+func (j *Jenga) getEquityToEquityURL() string {
+	return j.getBaseURL() + equityToequity
+}
+
+func (j *Jenga) getAirTimeURL() string {
+	return j.getBaseURL() + jengaAirTimeURL
+}
+
+func (j *Jenga) getAccessTokenURL() string {
+	return j.getBaseURL() + jengaTokenURL
+}
+
+func (j *Jenga) getKycURL() string {
+	return j.getBaseURL() + jengaKycURL
+}
@@ -0,0 +1,46 @@
+package jenga
+
+import (
+	"fmt"
+
+	"paydex/phone"
+)
+
+// telcos are the names jenga uses for airtime purchases.
+var telcos = map[phone.Operator]string{
+	phone.Safaricom: Safaricom,
+	phone.Airtel:    Airtel,
+	phone.Equitel:   Equitel,
+}
+
+// wallets are the mobile money wallets jenga can send money to.
+var wallets = map[phone.Operator]string{
+	phone.Safaricom: JengaMpesa,
+	phone.Airtel:    JengaAirtel,
+}
+
+// Telco returns the jenga telco of the mobile number.
+func Telco(mobileNumber, countryCode string) (string, error) {
+	n, err := phone.Parse(mobileNumber, countryCode)
+	if err != nil {
+		return "", err
+	}
+	telco, ok := telcos[n.Operator]
+	if !ok {
+		return "", fmt.Errorf("jenga: airtime is not supported for %s numbers", n.Operator)
+	}
+	return telco, nil
+}
+
+// wallet returns the wallet and the local format jenga expects for the mobile number.
+func wallet(mobileNumber, countryCode string) (string, string, error) {
+	n, err := phone.Parse(mobileNumber, countryCode)
+	if err != nil {
+		return "", "", err
+	}
+	name, ok := wallets[n.Operator]
+	if !ok {
+		return "", "", fmt.Errorf("jenga: no mobile wallet for %s numbers", n.Operator)
+	}
+	return name, n.Local(), nil
+}
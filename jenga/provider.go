@@ -0,0 +1,101 @@
+package jenga
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"paydex/money"
+	"paydex/provider"
+)
+
+// ProviderName is the name jenga is registered under.
+const ProviderName = "jenga"
+
+var _ provider.Provider = (*Jenga)(nil)
+
+func (j *Jenga) Name() string {
+	return ProviderName
+}
+
+// Collect is not supported, jenga only sends money out of the account.
+func (j *Jenga) Collect(context.Context, provider.CollectRequest) (*provider.CollectResult, error) {
+	return nil, provider.ErrUnsupported
+}
+
+// Payout sends money from the default source account to a mobile wallet.
+func (j *Jenga) Payout(ctx context.Context, req provider.PayoutRequest) (*provider.PayoutResult, error) {
+	if !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("jenga: %w: %s", money.ErrInvalidAmount, req.Amount)
+	}
+	walletName, mobileNumber, err := wallet(req.PhoneNumber, KENYA)
+	if err != nil {
+		return nil, err
+	}
+	res, err := j.BankToMobileMoneyTransfer(ctx, BankToMobileMoneyRequest{
+		Source: j.DefaultSource,
+		Destination: MobileMoneyDestination{
+			Destination: Destination{
+				CountryCode:  KENYA,
+				Name:         req.Name,
+				MobileNumber: mobileNumber,
+			},
+			WalletName: walletName,
+		},
+		Transfer: Transfer{
+			Amount:       req.Amount.Decimal(),
+			CurrencyCode: req.Amount.Currency,
+			Reference:    req.Reference,
+			Date:         time.Now().Format("2006-01-02"),
+			Description:  req.Description,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	status := provider.StatusPending
+	if strings.EqualFold(res.Status, "success") {
+		status = provider.StatusCompleted
+	}
+	return &provider.PayoutResult{
+		TransactionID: res.TransactionID,
+		Status:        status,
+		Message:       res.ResponseMsg,
+	}, nil
+}
+
+// Status is not supported yet.
+func (j *Jenga) Status(context.Context, provider.StatusRequest) (*provider.StatusResult, error) {
+	return nil, provider.ErrUnsupported
+}
+
+// Balance returns the balances of the requested account
+// or the default source account.
+func (j *Jenga) Balance(ctx context.Context, req provider.BalanceRequest) (*provider.BalanceResult, error) {
+	countryCode, accountID := req.CountryCode, req.AccountID
+	if countryCode == "" {
+		countryCode = j.DefaultSource.CountryCode
+	}
+	if accountID == "" {
+		accountID = j.DefaultSource.AccountNumber
+	}
+	res, err := j.GetAccountBalance(ctx, countryCode, accountID)
+	if err != nil {
+		return nil, err
+	}
+	result := &provider.BalanceResult{}
+	for _, b := range res.Balances {
+		amount, err := money.Parse(b.Amount, res.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("jenga: %s balance: %w", b.Type, err)
+		}
+		result.Balances = append(result.Balances, provider.Balance{Type: b.Type, Amount: amount})
+	}
+	return result, nil
+}
+
+// Refund is not supported by jenga.
+func (j *Jenga) Refund(context.Context, provider.RefundRequest) (*provider.RefundResult, error) {
+	return nil, provider.ErrUnsupported
+}
@@ -0,0 +1,76 @@
+package jenga
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"paydex/metrics"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// instrument traces the jenga requests and records their latency and status.
+func instrument(base http.RoundTripper) http.RoundTripper {
+	return metrics.Transport(ProviderName, endpoint, otelhttp.NewTransport(base,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "jenga " + endpoint(r)
+		})))
+}
+
+// endpoint drops the account from the balance path to keep the metric labels bounded.
+func endpoint(r *http.Request) string {
+	const balances = "/account/v2/accounts/balances/"
+	if strings.HasPrefix(r.URL.Path, balances) {
+		return balances[:len(balances)-1]
+	}
+	return r.URL.Path
+}
+
+type RequestError struct {
+	StatusCode int
+	Message    string
+	URL        string
+}
+
+func (r *RequestError) Error() string {
+	return fmt.Sprintf("url: %s  code: %d  body  : %s", r.URL, r.StatusCode, r.Message)
+}
+
+func IsEmpty(s string) bool {
+	return len(strings.TrimSpace(s)) == 0
+}
+
+func postRequest(ctx context.Context, client *http.Client, url string, data any, headers map[string]string) (*http.Response, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(b))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return client.Do(req)
+}
+
+func getRequest(ctx context.Context, client *http.Client, url string, headers, queryParameters map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	q := req.URL.Query()
+	for key, value := range queryParameters {
+		q.Add(key, value)
+	}
+	req.URL.RawQuery = q.Encode()
+	return client.Do(req)
+}
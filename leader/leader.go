@@ -0,0 +1,133 @@
+// Package leader elects one instance among the replicas with a redis
+// lease e.g to enqueue the periodic tasks once.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/exp/slog"
+)
+
+// renew extends the lease when it is still held by the caller.
+var renew = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// release deletes the lease when it is still held by the caller.
+var release = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Lock is a lease on a redis key held by one instance at a time, it
+// expires after ttl unless the holder renews it.
+type Lock struct {
+	client redis.UniversalClient
+	key    string
+	id     string
+	ttl    time.Duration
+}
+
+// New identifies the instance by its host name, pid and a random suffix.
+func New(client redis.UniversalClient, key string, ttl time.Duration) *Lock {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return &Lock{
+		client: client,
+		key:    key,
+		id:     fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix)),
+		ttl:    ttl,
+	}
+}
+
+// ID is the holder id stored in the key while the lock is held.
+func (l *Lock) ID() string {
+	return l.id
+}
+
+// Acquire takes the lease when no one holds it.
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	return l.client.SetNX(ctx, l.key, l.id, l.ttl).Result()
+}
+
+// Renew extends the lease, it reports false when the lease was lost.
+func (l *Lock) Renew(ctx context.Context) (bool, error) {
+	n, err := renew.Run(ctx, l.client, []string{l.key}, l.id, l.ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// Release gives up the lease so another instance can take over without
+// waiting for it to expire.
+func (l *Lock) Release(ctx context.Context) error {
+	return release.Run(ctx, l.client, []string{l.key}, l.id).Err()
+}
+
+// Run tries to take the lease until ctx is done, lead runs while the lease
+// is held and its context is cancelled as soon as the lease is lost.
+func (l *Lock) Run(ctx context.Context, lead func(ctx context.Context) error) error {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		held, err := l.Acquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("leader election failed", err, "key", l.key)
+		}
+		if held {
+			if err := l.hold(ctx, ticker, lead); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// hold runs lead and renews the lease until lead returns or the lease is lost.
+func (l *Lock) hold(ctx context.Context, ticker *time.Ticker, lead func(ctx context.Context) error) error {
+	slog.Info("leadership acquired", "key", l.key, "id", l.id)
+	leadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- lead(leadCtx) }()
+
+	renewed := time.Now()
+	for {
+		select {
+		case err := <-done:
+			// ctx may be done already, the release must still reach redis.
+			releaseCtx, cancelRelease := context.WithTimeout(context.Background(), time.Second)
+			defer cancelRelease()
+			if rerr := l.Release(releaseCtx); rerr != nil {
+				slog.Error("failed to release leadership", rerr, "key", l.key)
+			}
+			slog.Info("leadership released", "key", l.key, "id", l.id)
+			return err
+		case <-ticker.C:
+			held, err := l.Renew(ctx)
+			switch {
+			case err == nil && held:
+				renewed = time.Now()
+			case err == nil || time.Since(renewed) >= l.ttl:
+				// another instance may hold the lease, stop leading at once.
+				slog.Warn("leadership lost", "key", l.key, "id", l.id)
+				cancel()
+				return <-done
+			default:
+				slog.Error("failed to renew leadership", err, "key", l.key)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestLock(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+	a := New(client, "paydex:leader:test", time.Minute)
+	b := New(client, "paydex:leader:test", time.Minute)
+
+	if held, err := a.Acquire(ctx); err != nil || !held {
+		t.Fatalf("a.Acquire() = %v, %v, want the lease", held, err)
+	}
+	if held, _ := b.Acquire(ctx); held {
+		t.Error("b.Acquire() took a held lease")
+	}
+	if held, _ := b.Renew(ctx); held {
+		t.Error("b.Renew() renewed a lease it does not hold")
+	}
+	if err := b.Release(ctx); err != nil || !mr.Exists("paydex:leader:test") {
+		t.Errorf("b.Release() = %v, it must not release a lease it does not hold", err)
+	}
+	if held, err := a.Renew(ctx); err != nil || !held {
+		t.Errorf("a.Renew() = %v, %v, want the lease renewed", held, err)
+	}
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if held, _ := b.Acquire(ctx); !held {
+		t.Error("b.Acquire() did not take the released lease")
+	}
+}
+
+func TestLock_Run(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	l := New(client, "paydex:leader:test", 30*time.Millisecond)
+
+	leading := make(chan context.Context, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- l.Run(ctx, func(ctx context.Context) error {
+			leading <- ctx
+			<-ctx.Done()
+			return nil
+		})
+	}()
+
+	first := <-leading
+	// another instance takes over e.g after a network partition.
+	mr.Set("paydex:leader:test", "other")
+	select {
+	case <-first.Done():
+	case <-time.After(time.Second):
+		t.Fatal("lead was not stopped after the lease was lost")
+	}
+
+	mr.Del("paydex:leader:test")
+	<-leading
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("paydex:leader:test") {
+		t.Error("the lease was not released on shutdown")
+	}
+}
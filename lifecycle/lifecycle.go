@@ -0,0 +1,82 @@
+// Package lifecycle runs the servers of the process and stops them
+// together on a signal or when one of them fails.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// DefaultTimeout is how long the components get to stop when none is configured.
+const DefaultTimeout = 30 * time.Second
+
+// Component is a long running part of the process e.g a server.
+type Component struct {
+	Name string
+	// Start runs the component and blocks until it stopped.
+	Start func() error
+	// Stop stops the component, it returns early when ctx is done.
+	Stop func(ctx context.Context) error
+}
+
+// Run starts the components and blocks until ctx is done or a component
+// fails, the components are then stopped in reverse order within timeout.
+// It returns the error that stopped the components, if any.
+func Run(ctx context.Context, timeout time.Duration, components ...Component) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(components))
+	for _, c := range components {
+		c := c
+		go func() {
+			slog.Info("starting", "component", c.Name)
+			results <- result{name: c.Name, err: c.Start()}
+		}()
+	}
+
+	running := len(components)
+	var cause error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", timeout)
+	case r := <-results:
+		running--
+		cause = fmt.Errorf("%s stopped unexpectedly", r.name)
+		if r.err != nil {
+			cause = fmt.Errorf("%s: %w", r.name, r.err)
+		}
+		slog.Error("shutting down", cause)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		if err := c.Stop(stopCtx); err != nil {
+			slog.Error("failed to stop", err, "component", c.Name)
+		}
+	}
+	for running > 0 {
+		select {
+		case r := <-results:
+			running--
+			if r.err != nil {
+				slog.Error("stopped with error", r.err, "component", r.name)
+			}
+		case <-stopCtx.Done():
+			if cause == nil {
+				cause = fmt.Errorf("%d components did not stop within %s", running, timeout)
+			}
+			return cause
+		}
+	}
+	return cause
+}
@@ -0,0 +1,65 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (r *recorder) component(name string, startErr error) Component {
+	done := make(chan struct{})
+	return Component{
+		Name: name,
+		Start: func() error {
+			if startErr != nil {
+				return startErr
+			}
+			<-done
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.stopped = append(r.stopped, name)
+			if startErr == nil {
+				close(done)
+			}
+			return nil
+		},
+	}
+}
+
+func TestRun_StopsInReverseOrder(t *testing.T) {
+	r := &recorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := Run(ctx, time.Second, r.component("grpc", nil), r.component("http", nil), r.component("worker", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"worker", "http", "grpc"}; !reflect.DeepEqual(r.stopped, want) {
+		t.Errorf("stopped = %v, want %v", r.stopped, want)
+	}
+}
+
+func TestRun_FailureStopsTheOthers(t *testing.T) {
+	r := &recorder{}
+	listenErr := errors.New("address already in use")
+	err := Run(context.Background(), time.Second, r.component("grpc", nil), r.component("http", listenErr))
+	if !errors.Is(err, listenErr) {
+		t.Fatalf("Run() error = %v, want %v", err, listenErr)
+	}
+	if len(r.stopped) != 2 {
+		t.Errorf("stopped = %v, want both components", r.stopped)
+	}
+}
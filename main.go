@@ -1,23 +1,88 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"paydex/callback"
+	"paydex/cli"
 	"paydex/config"
+	"paydex/currency"
+	"paydex/lifecycle"
 	"paydex/pkg/logger"
 	"paydex/pkg/version"
+	"paydex/retention"
 	"paydex/services"
+	"paydex/store"
+	"paydex/tracing"
+	"paydex/webhook"
 	"paydex/worker"
+	"syscall"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/hibiken/asynq"
 )
 
+const serveUsage = `usage: paydex [serve-api|serve-gateway|worker|scheduler|all] [flags]
+
+  serve-api      serve the grpc api
+  serve-gateway  serve the rest gateway, swagger ui and provider callbacks
+  worker         process the queued tasks
+  scheduler      enqueue the periodic tasks, one instance at a time leads
+  all            all of the above, the default
+
+see also paydex config and the operator cli, paydex stk|payment|payout|balance|reverse|callbacks.`
+
+// serveCommands are the roles started by each subcommand.
+var serveCommands = map[string][]services.Role{
+	"serve-api":     {services.RoleAPI},
+	"serve-gateway": {services.RoleGateway},
+	"worker":        {services.RoleWorker},
+	"scheduler":     {services.RoleScheduler},
+	"all":           services.AllRoles,
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && isClientCommand(os.Args[1]) {
+		if err := cli.Run(context.Background(), os.Args[1:], os.Stdout, os.LookupEnv); err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	roles, args := services.AllRoles, os.Args[1:]
+	if len(args) > 0 {
+		if r, ok := serveCommands[args[0]]; ok {
+			roles, args = r, args[1:]
+		}
+	}
+
 	var loc string
-	flag.StringVar(&loc, "config", "config file", "provide config file location")
+	var retentionReport bool
+	var replayCallback string
+	flag.StringVar(&loc, "config", "", "provide config file location")
+	flag.BoolVar(&retentionReport, "retention-report", false, "print what the retention policy would purge and exit")
+	flag.StringVar(&replayCallback, "replay-callback", "", "process the stored callback with the id again and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "%s\n\nflags:\n", serveUsage)
+		flag.PrintDefaults()
+	}
 
-	flag.Parse()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		log.Fatal(err)
+	}
 
 	l := logger.GetLogger()
 	log.Printf("App Version [%s]", version.Get())
@@ -26,26 +91,94 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := conf.Validate(services.RequiredServers(&conf, roles...)...); err != nil {
+		log.Fatal(err)
+	}
+	// exits once the deferred shutdown of the tracer ran.
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+	shutdownTracing, err := tracing.Setup(context.Background(), &conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if errx := shutdownTracing(context.Background()); errx != nil {
+			log.Print(errx)
+		}
+	}()
 	dsn := fmt.Sprintf("%s:%s", conf.Redis.Address, conf.Redis.Port)
-	workerService := worker.NewRedisTaskDistributor(asynq.RedisClientOpt{Addr: dsn})
+	workerService := worker.NewRedisTaskDistributor(asynq.RedisClientOpt{Addr: dsn}, &conf)
 	if err != nil {
 		log.Fatal(err)
 	}
-	server := services.NewServer(workerService, &conf, l, asynq.RedisClientOpt{Addr: dsn})
-
-	go func() {
-		if errx := server.RunGrpcServer(); errx != nil {
+	guard := worker.NewMpesaGuard(asynq.RedisClientOpt{Addr: dsn}, &conf)
+	providers := worker.NewProviderRegistry(&conf, guard)
+	rates, err := currency.NewRateProvider(&conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	paymentStore := store.NewRedisStore(redis.NewClient(&redis.Options{Addr: dsn, DB: conf.Redis.DB}))
+	if retentionReport {
+		purger := retention.NewPurger(paymentStore, retention.PolicyFromConfig(&conf))
+		report, errx := purger.Run(context.Background(), time.Now(), true)
+		if errx != nil {
 			log.Fatal(errx)
 		}
-	}()
-
-	go func() {
-		if errx := server.RunTaskProcessor(); errx != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if errx := enc.Encode(report); errx != nil {
 			log.Fatal(errx)
 		}
-	}()
+		return
+	}
+	publisher := webhook.NewPublisher(paymentStore, webhook.SubscriptionsFromConfig(&conf), workerService)
+	if replayCallback != "" {
+		processor := callback.NewProcessor(paymentStore, callback.NewMpesa(paymentStore, providers, publisher), callback.NewAirtel(paymentStore, providers, publisher))
+		c, errx := processor.Process(context.Background(), replayCallback)
+		if c == nil {
+			log.Fatal(errx)
+		}
+		if errx != nil {
+			log.Print(errx)
+		}
+		c.Body, c.Headers = nil, nil
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if errx := enc.Encode(c); errx != nil {
+			log.Fatal(errx)
+		}
+		return
+	}
+	server := services.NewServer(workerService, providers, guard, currency.NewConverter(rates), paymentStore, publisher, &conf, l, asynq.RedisClientOpt{Addr: dsn})
 
-	if errx := server.RunHTTPServer(); errx != nil {
-		log.Fatal(errx)
+	components, err := server.Components(roles...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reloader := config.NewReloader(loc, &conf)
+	reloader.OnReload(func(_, c *config.Config) { publisher.Reload(c) })
+	components = append(components, server.ConfigWatcher(reloader))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	if errx := lifecycle.Run(ctx, time.Duration(conf.ShutdownTimeout)*time.Second, components...); errx != nil {
+		log.Print(errx)
+		exitCode = 1
+	}
+	if errx := workerService.Close(); errx != nil {
+		log.Print(errx)
+	}
+}
+
+// isClientCommand tells the commands of the operator cli from the server flags.
+func isClientCommand(name string) bool {
+	for _, c := range cli.Commands {
+		if name == c {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
+)
+
+// TaskMiddleware records the processing time of the tasks.
+func TaskMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		start := time.Now()
+		err := next.ProcessTask(ctx, task)
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		TaskDuration.WithLabelValues(task.Type(), result).Observe(time.Since(start).Seconds())
+		return err
+	})
+}
+
+var (
+	queueSizeDesc = prometheus.NewDesc(namespace+"_queue_tasks",
+		"Tasks in the queue by state.", []string{"queue", "state"}, nil)
+	queueLatencyDesc = prometheus.NewDesc(namespace+"_queue_latency_seconds",
+		"Age of the oldest pending task in the queue.", []string{"queue"}, nil)
+	queuePausedDesc = prometheus.NewDesc(namespace+"_queue_paused",
+		"Whether the queue is paused.", []string{"queue"}, nil)
+)
+
+// QueueCollector reads the queue depth and latency from redis on every scrape.
+type QueueCollector struct {
+	inspector *asynq.Inspector
+	queues    []string
+}
+
+func NewQueueCollector(inspector *asynq.Inspector, queues ...string) *QueueCollector {
+	return &QueueCollector{inspector: inspector, queues: queues}
+}
+
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueSizeDesc
+	ch <- queueLatencyDesc
+	ch <- queuePausedDesc
+}
+
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, q := range c.queues {
+		info, err := c.inspector.GetQueueInfo(q)
+		if err != nil {
+			// the queue does not exist until a task is enqueued.
+			slog.Debug("unable to inspect queue", "queue", q, "error", err)
+			continue
+		}
+		for state, n := range map[string]int{
+			"pending":   info.Pending,
+			"active":    info.Active,
+			"scheduled": info.Scheduled,
+			"retry":     info.Retry,
+			"archived":  info.Archived,
+		} {
+			ch <- prometheus.MustNewConstMetric(queueSizeDesc, prometheus.GaugeValue, float64(n), q, state)
+		}
+		ch <- prometheus.MustNewConstMetric(queueLatencyDesc, prometheus.GaugeValue, info.Latency.Seconds(), q)
+		paused := 0.0
+		if info.Paused {
+			paused = 1
+		}
+		ch <- prometheus.MustNewConstMetric(queuePausedDesc, prometheus.GaugeValue, paused, q)
+	}
+}
@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor records the requests and latency of unary rpcs.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		RPCRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		RPCDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		return res, err
+	}
+}
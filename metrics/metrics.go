@@ -0,0 +1,127 @@
+// Package metrics exposes the prometheus metrics of the api and the worker.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "paydex"
+
+var (
+	RPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC requests by method and status code.",
+	}, []string{"method", "code"})
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC request latency by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// StkPushResults counts the payments that reached a final status, by
+	// callback, reconciliation or a rejected push.
+	StkPushResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stk_push_results_total",
+		Help:      "STK push outcomes by provider, final status and provider result code.",
+	}, []string{"provider", "status", "result_code"})
+
+	ProviderRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_requests_total",
+		Help:      "HTTP requests to payment providers by endpoint and status code, code is 0 when no response was received.",
+	}, []string{"provider", "endpoint", "code"})
+	ProviderDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Latency of HTTP requests to payment providers by endpoint.",
+		// daraja regularly takes seconds to respond.
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 20, 30},
+	}, []string{"provider", "endpoint"})
+	TokenRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_token_refreshes_total",
+		Help:      "Access token requests to payment providers by result.",
+	}, []string{"provider", "result"})
+	// ThrottleWait is how long the requests to a provider waited for the shared rate limit.
+	ThrottleWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_throttle_wait_seconds",
+		Help:      "Time the requests to payment providers waited for the rate limit.",
+		Buckets:   []float64{0, .01, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"provider"})
+	// CircuitState is 0 when the circuit breaker of a provider is closed, 1 half open and 2 open.
+	CircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_circuit_state",
+		Help:      "Circuit breaker state of payment providers, 0 closed, 1 half open, 2 open.",
+	}, []string{"provider"})
+
+	TaskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_duration_seconds",
+		Help:      "Task processing time by task type and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type", "result"})
+)
+
+// Handler serves the metrics of the default registry along with those
+// of the given registries.
+func Handler(registries ...prometheus.Gatherer) http.Handler {
+	gatherers := append(prometheus.Gatherers{prometheus.DefaultGatherer}, registries...)
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}))
+}
+
+// StkPushResolved records a payment that reached a final status.
+func StkPushResolved(provider, status, resultCode string) {
+	StkPushResults.WithLabelValues(provider, status, resultCode).Inc()
+}
+
+// TokenRefreshed records a token request.
+func TokenRefreshed(provider string, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	TokenRefreshes.WithLabelValues(provider, result).Inc()
+}
+
+// Transport records the latency and status of the requests
+// made through base, http.DefaultTransport when nil.
+func Transport(provider string, endpoint func(*http.Request) string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if endpoint == nil {
+		endpoint = func(r *http.Request) string { return r.URL.Path }
+	}
+	return &transport{provider: provider, endpoint: endpoint, base: base}
+}
+
+type transport struct {
+	provider string
+	endpoint func(*http.Request) string
+	base     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(r)
+	endpoint := t.endpoint(r)
+	code := 0
+	if err == nil {
+		code = res.StatusCode
+	}
+	ProviderRequests.WithLabelValues(t.provider, endpoint, strconv.Itoa(code)).Inc()
+	ProviderDuration.WithLabelValues(t.provider, endpoint).Observe(time.Since(start).Seconds())
+	return res, err
+}
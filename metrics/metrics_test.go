@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: Transport("test", nil, nil)}
+	res, err := client.Get(srv.URL + "/mpesa/stkpush/v1/processrequest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if got := testutil.ToFloat64(ProviderRequests.WithLabelValues("test", "/mpesa/stkpush/v1/processrequest", "503")); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(ProviderDuration); got != 1 {
+		t.Errorf("duration series = %v, want 1", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "test_gauge", Help: "test"}))
+	for i := 0; i < 2; i++ {
+		// a handler per call, as when the components are built again.
+		res := httptest.NewRecorder()
+		Handler(registry).ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if !strings.Contains(res.Body.String(), "paydex_test_gauge") {
+			t.Fatalf("metrics do not include the registry:\n%s", res.Body.String())
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+)
+
+// exponents is the number of minor unit digits of the supported ISO currencies.
+var exponents = map[string]int{
+	"KES": 2,
+	"UGX": 0,
+	"TZS": 2,
+	"RWF": 0,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+}
+
+// Money is an amount in the minor units of its ISO currency
+// e.g 1050 KES is 10.50 shillings.
+type Money struct {
+	Minor    int64  `json:"minor"`
+	Currency string `json:"currency"`
+}
+
+// New returns the amount in minor units of the currency.
+func New(minor int64, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if _, ok := exponents[currency]; !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// Parse reads a decimal amount e.g "10.50", amounts with more decimals
+// than the currency has minor units are rejected rather than rounded.
+func Parse(amount, currency string) (Money, error) {
+	m, err := New(0, currency)
+	if err != nil {
+		return m, err
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok || strings.ContainsAny(amount, "eE/") {
+		return m, fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+	}
+	minor := new(big.Rat).Mul(r, scale(exponents[m.Currency]))
+	if !minor.IsInt() || !minor.Num().IsInt64() {
+		return m, fmt.Errorf("%w %q: %s has %d decimals", ErrInvalidAmount, amount, m.Currency, exponents[m.Currency])
+	}
+	m.Minor = minor.Num().Int64()
+	return m, nil
+}
+
+// FromRat rounds the amount half up to the minor units of the currency.
+func FromRat(amount *big.Rat, currency string) (Money, error) {
+	m, err := New(0, currency)
+	if err != nil {
+		return m, err
+	}
+	minor := roundHalfUp(new(big.Rat).Mul(amount, scale(exponents[m.Currency])))
+	if !minor.IsInt64() {
+		return m, fmt.Errorf("%w: %s overflows", ErrInvalidAmount, amount.FloatString(2))
+	}
+	m.Minor = minor.Int64()
+	return m, nil
+}
+
+// FromFloat converts provider amounts such as balances that are sent as floats.
+func FromFloat(amount float64, currency string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	if !ok {
+		return Money{}, fmt.Errorf("%w %v", ErrInvalidAmount, amount)
+	}
+	return FromRat(r, currency)
+}
+
+// legacyCurrency is the currency of the amounts stored without one.
+const legacyCurrency = "KES"
+
+// UnmarshalLegacy reads an amount encoded as money or, as the payments and
+// tasks stored before the amounts were typed, as a decimal string with the
+// currency in a separate field.
+func UnmarshalLegacy(data []byte, currency string) (Money, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return Money{}, nil
+	}
+	if data[0] != '"' {
+		var m Money
+		err := json.Unmarshal(data, &m)
+		return m, err
+	}
+	var amount string
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return Money{}, err
+	}
+	if amount == "" {
+		return Money{}, nil
+	}
+	if currency == "" {
+		currency = legacyCurrency
+	}
+	// rounded rather than rejected, a stored amount must still load.
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+	}
+	return FromRat(r, currency)
+}
+
+// Exponent returns the number of minor unit digits of the currency.
+func (m Money) Exponent() int {
+	return exponents[m.Currency]
+}
+
+// Rat returns the amount in major units.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Minor), scale(m.Exponent()).Num())
+}
+
+// Decimal formats the amount in major units e.g "10.50".
+func (m Money) Decimal() string {
+	return m.Rat().FloatString(m.Exponent())
+}
+
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// Whole returns the amount in whole major units and fails when the amount
+// has a fractional part e.g mpesa only accepts whole shillings.
+func (m Money) Whole() (int64, error) {
+	r := m.Rat()
+	if !r.IsInt() {
+		return 0, fmt.Errorf("%w: %s is not a whole amount", ErrInvalidAmount, m)
+	}
+	return r.Num().Int64(), nil
+}
+
+// RoundWhole rounds the amount half up to whole major units.
+func (m Money) RoundWhole() Money {
+	whole := roundHalfUp(m.Rat())
+	return Money{Minor: whole.Int64() * scale(m.Exponent()).Num().Int64(), Currency: m.Currency}
+}
+
+// Add adds amounts of the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}, nil
+}
+
+// Equal reports whether both the amount and currency match.
+func (m Money) Equal(o Money) bool {
+	return m.Minor == o.Minor && m.Currency == o.Currency
+}
+
+func scale(exponent int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+}
+
+func roundHalfUp(r *big.Rat) *big.Int {
+	half := big.NewRat(1, 2)
+	if r.Sign() < 0 {
+		half.Neg(half)
+	}
+	v := new(big.Rat).Add(r, half)
+	return new(big.Int).Quo(v.Num(), v.Denom())
+}
@@ -0,0 +1,82 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{amount: "10.50", currency: "kes", want: Money{Minor: 1050, Currency: "KES"}},
+		{amount: "10", currency: "KES", want: Money{Minor: 1000, Currency: "KES"}},
+		{amount: "1000", currency: "UGX", want: Money{Minor: 1000, Currency: "UGX"}},
+		{amount: "10.505", currency: "KES", wantErr: ErrInvalidAmount},
+		{amount: "10.5", currency: "UGX", wantErr: ErrInvalidAmount},
+		{amount: "1e3", currency: "KES", wantErr: ErrInvalidAmount},
+		{amount: "ten", currency: "KES", wantErr: ErrInvalidAmount},
+		{amount: "10", currency: "XYZ", wantErr: ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount+tt.currency, func(t *testing.T) {
+			got, err := Parse(tt.amount, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !got.Equal(tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Whole(t *testing.T) {
+	if got, err := (Money{Minor: 1000, Currency: "KES"}).Whole(); err != nil || got != 10 {
+		t.Errorf("Whole() = %v, %v, want 10", got, err)
+	}
+	if _, err := (Money{Minor: 1050, Currency: "KES"}).Whole(); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Whole() error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if got := (Money{Minor: 1050, Currency: "KES"}).RoundWhole(); got.Minor != 1100 {
+		t.Errorf("RoundWhole() = %v, want KES 11.00", got)
+	}
+}
+
+func TestFromRat(t *testing.T) {
+	got, err := FromRat(big.NewRat(10005, 1000), "KES")
+	if err != nil || got.Minor != 1001 {
+		t.Errorf("FromRat() = %v, %v, want KES 10.01", got, err)
+	}
+	if _, err := (Money{Minor: 1, Currency: "KES"}).Add(Money{Minor: 1, Currency: "USD"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestUnmarshalLegacy(t *testing.T) {
+	tests := []struct {
+		data     string
+		currency string
+		want     Money
+	}{
+		{data: `{"minor": 1050, "currency": "KES"}`, want: Money{Minor: 1050, Currency: "KES"}},
+		{data: `"10.50"`, currency: "KES", want: Money{Minor: 1050, Currency: "KES"}},
+		{data: `"25"`, currency: "USD", want: Money{Minor: 2500, Currency: "USD"}},
+		{data: `"10"`, want: Money{Minor: 1000, Currency: "KES"}},
+		{data: `""`},
+		{data: `null`},
+	}
+	for _, tt := range tests {
+		got, err := UnmarshalLegacy([]byte(tt.data), tt.currency)
+		if err != nil {
+			t.Fatalf("UnmarshalLegacy(%s) error = %v", tt.data, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("UnmarshalLegacy(%s) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
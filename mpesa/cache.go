@@ -5,35 +5,45 @@ import (
 	"time"
 )
 
+// Cache holds the access token of a client.
 type Cache struct {
-	data map[string]*AccessTokenResponse
-	lock *sync.RWMutex
+	token *AccessTokenResponse
+	lock  *sync.RWMutex
 }
 
 func NewCache() *Cache {
-	return &Cache{
-		data: make(map[string]*AccessTokenResponse),
-		lock: &sync.RWMutex{},
-	}
+	return &Cache{lock: &sync.RWMutex{}}
 }
 
-// Get retrieves the token from cache.
-func (c *Cache) Get(key string) (*AccessTokenResponse, bool) {
+// Get returns the token unless it expires within tokenExpirySkew,
+// it could expire before daraja receives the request.
+func (c *Cache) Get() (*AccessTokenResponse, bool) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	v, ok := c.data[key]
-	if !ok {
-		return nil, false
-	}
-	if time.Until(v.ExpireTime).Minutes() <= 0 {
+	if c.token == nil || !c.token.validFor(tokenExpirySkew) {
 		return nil, false
 	}
-	return v, true
+	return c.token, true
 }
 
-// Set Adds the token to cache.
+// Set replaces the token.
 func (c *Cache) Set(val *AccessTokenResponse) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	c.data["token"] = val
+	c.token = val
+}
+
+// Invalidate drops the token when it is still accessToken, a token
+// refreshed in the meantime is kept.
+func (c *Cache) Invalidate(accessToken string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.token != nil && c.token.AccessToken == accessToken {
+		c.token = nil
+	}
+}
+
+// validFor tells whether the token is still valid in d.
+func (t *AccessTokenResponse) validFor(d time.Duration) bool {
+	return time.Until(t.ExpireTime) > d
 }
@@ -0,0 +1,182 @@
+package mpesa
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"paydex/money"
+	"paydex/provider"
+)
+
+// SafaricomCallbackIPs are the addresses daraja posts callbacks from.
+// see https://developer.safaricom.co.ke/Documentation
+var SafaricomCallbackIPs = []string{
+	"196.201.214.200",
+	"196.201.214.206",
+	"196.201.213.114",
+	"196.201.214.207",
+	"196.201.214.208",
+	"196.201.213.44",
+	"196.201.212.127",
+	"196.201.212.138",
+	"196.201.212.129",
+	"196.201.212.136",
+	"196.201.212.74",
+	"196.201.212.69",
+}
+
+// ErrCallbackRejected is returned for the callbacks that are not authentic.
+var ErrCallbackRejected = errors.New("mpesa: callback rejected")
+
+// CallbackQuery are the parameters we embedded in the CallBackURL.
+type CallbackQuery struct {
+	PaymentID string
+	Token     string
+	// TokenHash replaces the token in the archived callbacks.
+	TokenHash string
+}
+
+// ParseStkCallback decodes the body posted to the stk push CallBackURL.
+func ParseStkCallback(r io.Reader) (*StkCallback, error) {
+	var body StkPushCallBackResponseBody
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return nil, err
+	}
+	if IsEmpty(body.Body.StkCallback.CheckoutRequestID) {
+		return nil, errors.New("callback checkout request id is required")
+	}
+	return &body.Body.StkCallback, nil
+}
+
+// ParseResultCallback decodes the body posted to the ResultURL
+// of b2c payments and reversals.
+func ParseResultCallback(r io.Reader) (*Result, error) {
+	var body B2CCallBackData
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return nil, err
+	}
+	if IsEmpty(body.Result.ConversationID) {
+		return nil, errors.New("result conversation id is required")
+	}
+	return &body.Result, nil
+}
+
+// Status maps the callback result code to the provider status.
+func (c *StkCallback) Status() provider.Status {
+	if c.ResultCode == 0 {
+		return provider.StatusCompleted
+	}
+	return provider.StatusFailed
+}
+
+// Amount is the amount paid, only successful callbacks have it.
+func (c *StkCallback) Amount() (money.Money, bool) {
+	v, ok := c.item("Amount").(float64)
+	if !ok {
+		return money.Money{}, false
+	}
+	m, err := money.FromFloat(v, Currency)
+	return m, err == nil
+}
+
+// ReceiptNumber is the mpesa receipt of a successful payment.
+func (c *StkCallback) ReceiptNumber() string {
+	s, _ := c.item("MpesaReceiptNumber").(string)
+	return s
+}
+
+// PhoneNumber is the number that paid, it may be masked by safaricom.
+func (c *StkCallback) PhoneNumber() string {
+	switch v := c.item("PhoneNumber").(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return ""
+}
+
+func (c *StkCallback) item(name string) any {
+	for _, i := range c.CallbackMetadata.Item {
+		if i.Name == name {
+			return i.Value
+		}
+	}
+	return nil
+}
+
+// ParseNetworks reads ip addresses and cidr ranges.
+func ParseNetworks(addresses []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(addresses))
+	for _, a := range addresses {
+		if !strings.Contains(a, "/") {
+			ip := net.ParseIP(a)
+			if ip == nil {
+				return nil, errors.New("invalid ip address " + a)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// SourceFilter restricts callbacks to the networks they are expected from.
+type SourceFilter struct {
+	// Networks allowed to post callbacks, all sources are allowed when empty.
+	Networks []*net.IPNet
+	// ForwardedFor reads the source from the X-Forwarded-For header.
+	ForwardedFor bool
+}
+
+// Allowed reports whether the request comes from an allowed network.
+func (f SourceFilter) Allowed(r *http.Request) bool {
+	if len(f.Networks) == 0 {
+		return true
+	}
+	addr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); f.ForwardedFor && forwarded != "" {
+		// the last address is the one our proxy saw.
+		parts := strings.Split(forwarded, ",")
+		addr = strings.TrimSpace(parts[len(parts)-1])
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range f.Networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCallbackQuery reads the parameters embedded in the CallBackURL.
+func ParseCallbackQuery(values url.Values) CallbackQuery {
+	return CallbackQuery{PaymentID: values.Get("payment_id"), Token: values.Get("token"), TokenHash: values.Get("token_hash")}
+}
+
+// Acknowledge writes the response daraja expects for a received callback.
+func Acknowledge(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"ResultCode":0,"ResultDesc":"Accepted"}`))
+}
@@ -12,11 +12,15 @@ const (
 	TransactionStatusQuery = "TransactionStatusQuery"
 	BusinessBuyGoods       = "BusinessBuyGoods"
 
+	// Currency is the only currency daraja accepts, in whole units.
+	Currency = "KES"
+
 	// Identifier Types
 	// see https://developer.safaricom.co.ke/docs#identifier-types
 	PayBillIdentifier    = "4"
 	TillNumberIdentifier = "2"
 	MSISDNIdentifier     = "1"
+	ShortCodeIdentifier  = "11"
 	AccessToken          = "access_token"
 	B2CAccessToken       = "B2c_access_token"
 
@@ -2,10 +2,13 @@ package mpesa
 
 import (
 	"errors"
-	"regexp"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
+
+	"paydex/money"
+	"paydex/phone"
 )
 
 type B2CRequestBody struct {
@@ -36,6 +39,7 @@ type B2CRequestBody struct {
 	Occasion string
 }
 
+// Validate checks the request and normalizes PartyB to the 254 format.
 func (s *B2CRequestBody) Validate() error {
 	if IsEmpty(s.PartyA) {
 		return errors.New("business short code is required")
@@ -66,13 +70,56 @@ func (s *B2CRequestBody) Validate() error {
 		return errors.New("remark  is required")
 	}
 
-	// validate amount.
-	i, err := strconv.Atoi(s.Amount)
-	if err != nil || i < 1 {
-		return errors.New("amount should be a string number that is greater than 0")
+	if err := validateAmount(s.Amount); err != nil {
+		return err
+	}
+	partyB, err := normalizePhoneNumber(s.PartyB)
+	if err != nil {
+		return err
+	}
+	s.PartyB = partyB
+	return nil
+}
+
+type ReversalRequestBody struct {
+	Initiator          string
+	SecurityCredential string
+	// use only [TransactionReversal].
+	CommandID string
+	// The mpesa receipt number of the transaction being reversed.
+	TransactionID string
+	Amount        string
+	// Organization’s shortcode that received the transaction.
+	ReceiverParty          string
+	RecieverIdentifierType string
+	ResultURL              string
+	QueueTimeOutURL        string
+	Remarks                string
+	// Optional
+	Occasion string
+}
+
+func (s *ReversalRequestBody) Validate() error {
+	if IsEmpty(s.TransactionID) {
+		return errors.New("transaction id is required")
+	}
+	if IsEmpty(s.ReceiverParty) {
+		return errors.New("receiver party is required")
+	}
+	if IsEmpty(s.Initiator) {
+		return errors.New("initiator name is required")
+	}
+	if IsEmpty(s.ResultURL) {
+		return errors.New("result url  is required")
+	}
+	if IsEmpty(s.QueueTimeOutURL) {
+		return errors.New("QueueTimeOutURL  is required")
+	}
+	if IsEmpty(s.Remarks) {
+		return errors.New("remark  is required")
 	}
-	if !CheckKenyaInternationalPhoneNumber(s.PartyB) {
-		return errors.New("the phone number should be in the format 254000000000 i.e(254 followed by 9 digits)")
+	if err := validateAmount(s.Amount); err != nil {
+		return err
 	}
 	return nil
 }
@@ -134,6 +181,7 @@ type StKPushRequestBody struct {
 	TransactionDesc  string
 }
 
+// Validate checks the request and normalizes PhoneNumber to the 254 format.
 func (s *StKPushRequestBody) Validate() error {
 	if IsEmpty(s.BusinessShortCode) {
 		return errors.New("business short code is required")
@@ -144,14 +192,14 @@ func (s *StKPushRequestBody) Validate() error {
 	if IsEmpty(s.PhoneNumber) {
 		return errors.New("phone number is required")
 	}
-	// validate amount.
-	i, err := strconv.Atoi(s.Amount)
-	if err != nil || i < 1 {
-		return errors.New("amount should be a string number that is greater than 0")
+	if err := validateAmount(s.Amount); err != nil {
+		return err
 	}
-	if !CheckKenyaInternationalPhoneNumber(s.PhoneNumber) {
-		return errors.New("the phone number should be in the format 254000000000 i.e(254 followed by 9 digits)")
+	phoneNumber, err := normalizePhoneNumber(s.PhoneNumber)
+	if err != nil {
+		return err
 	}
+	s.PhoneNumber = phoneNumber
 	return nil
 }
 
@@ -216,7 +264,44 @@ type AccessTokenResponse struct {
 func IsEmpty(s string) bool {
 	return len(strings.TrimSpace(s)) == 0
 }
-func CheckKenyaInternationalPhoneNumber(phone string) bool {
-	re := regexp.MustCompile(`(^254)\d{9}$`)
-	return re.MatchString(phone)
+
+// CheckKenyaInternationalPhoneNumber reports whether the phone number
+// is a kenyan mobile number in the 254 followed by 9 digits format.
+func CheckKenyaInternationalPhoneNumber(phoneNumber string) bool {
+	n, err := phone.Parse(phoneNumber, phone.Kenya)
+	return err == nil && n.MSISDN() == phoneNumber
+}
+
+// normalizePhoneNumber converts the formats customers enter
+// e.g 0712345678 or +254712345678 to the 254712345678 format daraja expects.
+func normalizePhoneNumber(phoneNumber string) (string, error) {
+	n, err := phone.Parse(phoneNumber, phone.Kenya)
+	if err != nil {
+		return "", fmt.Errorf("the phone number should be a kenyan mobile number e.g 254712345678: %w", err)
+	}
+	return n.MSISDN(), nil
+}
+
+// validateAmount checks the amount is a positive number of whole shillings.
+func validateAmount(amount string) error {
+	m, err := money.Parse(amount, Currency)
+	if err != nil || !m.IsPositive() {
+		return errors.New("amount should be a string number that is greater than 0")
+	}
+	if _, err := m.Whole(); err != nil {
+		return errors.New("amount should be in whole shillings")
+	}
+	return nil
+}
+
+// formatAmount converts the amount to the whole shillings daraja expects.
+func formatAmount(m money.Money) (string, error) {
+	if m.Currency != Currency {
+		return "", fmt.Errorf("mpesa: %w: %s", money.ErrCurrencyMismatch, m.Currency)
+	}
+	whole, err := m.Whole()
+	if err != nil {
+		return "", fmt.Errorf("mpesa: %w", err)
+	}
+	return strconv.FormatInt(whole, 10), nil
 }
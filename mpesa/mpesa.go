@@ -7,13 +7,27 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
+	"paydex/breaker"
+	"paydex/metrics"
+	"paydex/provider"
+
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type ClientOption func(*Mpesa)
 
+// instrument traces the daraja requests and records their latency and status.
+func instrument(base http.RoundTripper) http.RoundTripper {
+	return metrics.Transport(ProviderName, nil, otelhttp.NewTransport(base,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "mpesa " + r.URL.Path
+		})))
+}
+
 type Mpesa struct {
 	// if set to true the access token will be reused.
 	// until it expires.
@@ -44,6 +58,25 @@ type Mpesa struct {
 	// for b2c.
 	DefaultSecurityCredential string
 	cache                     *Cache
+	// tokens shares the access token between the instances, see WithTokenStore.
+	tokens TokenStore
+	// flight is the token request in flight, see refreshToken.
+	flightLock sync.Mutex
+	flight     *tokenFlight
+	// limiter and breaker guard the requests, see WithRateLimit and WithBreaker.
+	limiter Waiter
+	breaker *breaker.Breaker
+	hooks   provider.Hooks
+	// transport replaces the one of httpClient, see WithTransport.
+	transport http.RoundTripper
+	// httpClient sends the requests, it is built by New from the options.
+	httpClient *http.Client
+}
+
+// Waiter blocks until a request may be sent, e.g a ratelimit.Bucket
+// shared by the instances.
+type Waiter interface {
+	Wait(ctx context.Context) error
 }
 
 func New(consumerKey, consumerSecret string, opts ...ClientOption) *Mpesa {
@@ -60,10 +93,56 @@ func New(consumerKey, consumerSecret string, opts ...ClientOption) *Mpesa {
 	for _, opt := range opts {
 		opt(client)
 	}
+	client.httpClient = client.newHTTPClient()
 
 	return client
 }
 
+// newHTTPClient wraps the transport, provider.Transport by default, with
+// the instrumentation, the hooks and the guards.
+func (m *Mpesa) newHTTPClient() *http.Client {
+	client := &http.Client{}
+	if m.httpClient != nil {
+		c := *m.httpClient
+		client = &c
+	}
+	if client.Timeout == 0 {
+		client.Timeout = m.TimeOut
+	}
+	if m.transport != nil {
+		client.Transport = m.transport
+	}
+	if client.Transport == nil {
+		client.Transport = provider.Transport
+	}
+	client.Transport = m.guard(m.hooks.Transport(instrument(client.Transport)))
+	return client
+}
+
+// guard waits for the limiter before the breaker so a request waiting
+// for its turn is not counted as a timeout of daraja.
+func (m *Mpesa) guard(rt http.RoundTripper) http.RoundTripper {
+	if m.breaker != nil {
+		rt = m.breaker.Transport(rt)
+	}
+	if m.limiter != nil {
+		rt = &throttled{limiter: m.limiter, base: rt}
+	}
+	return rt
+}
+
+type throttled struct {
+	limiter Waiter
+	base    http.RoundTripper
+}
+
+func (t *throttled) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(r.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(r)
+}
+
 // WithCache wether to cache the access token.
 func WithCache(cache bool) ClientOption {
 	return func(m *Mpesa) {
@@ -71,11 +150,28 @@ func WithCache(cache bool) ClientOption {
 	}
 }
 
+// WithC2BShortCode will set the default shortcode
+// customers pay to if you do not provide any.
+func WithC2BShortCode(shortCode string) ClientOption {
+	return func(m *Mpesa) {
+		m.DefaultC2BShortCode = shortCode
+	}
+}
+
 // WithB2CShortCode will set the default shortcode
 // to use if you do not provide any.
 func WithB2CShortCode(shortCode string) ClientOption {
 	return func(m *Mpesa) {
-		m.DefaultC2BShortCode = shortCode
+		m.DefaultB2CShortCode = shortCode
+	}
+}
+
+// WithInitiator sets the credentials used for b2c, reversal
+// and other initiator based requests.
+func WithInitiator(name, securityCredential string) ClientOption {
+	return func(m *Mpesa) {
+		m.DefaultInitiatorName = name
+		m.DefaultSecurityCredential = securityCredential
 	}
 }
 
@@ -96,6 +192,46 @@ func WithTimeout(timeOut time.Duration) ClientOption {
 	}
 }
 
+// WithHTTPClient sends the requests with a copy of c, its transport is
+// wrapped with the metrics, the hooks and the guards. The client timeout
+// defaults to the one of WithTimeout.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(m *Mpesa) {
+		m.httpClient = c
+	}
+}
+
+// WithTransport sends the requests through rt instead of the connection
+// pool shared by the providers, e.g to stub daraja in tests.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(m *Mpesa) {
+		m.transport = rt
+	}
+}
+
+// WithHooks calls the hooks around each request e.g to log them.
+func WithHooks(h provider.Hooks) ClientOption {
+	return func(m *Mpesa) {
+		m.hooks = h
+	}
+}
+
+// WithRateLimit sends the requests, the token requests included,
+// once w lets them through.
+func WithRateLimit(w Waiter) ClientOption {
+	return func(m *Mpesa) {
+		m.limiter = w
+	}
+}
+
+// WithBreaker fails the requests with breaker.ErrOpen while b is open,
+// share b between the clients of a process.
+func WithBreaker(b *breaker.Breaker) ClientOption {
+	return func(m *Mpesa) {
+		m.breaker = b
+	}
+}
+
 // WithLiveMode changes from production to sandbox and viceversa
 // at runtime.
 func WithLiveMode(mode bool) ClientOption {
@@ -105,13 +241,25 @@ func WithLiveMode(mode bool) ClientOption {
 }
 
 // B2CRequest Sends Money from a business to the Customer.
-func (m *Mpesa) B2CRequest(b2c B2CRequestBody) (*MpesaResult, error) {
+func (m *Mpesa) B2CRequest(ctx context.Context, b2c B2CRequestBody) (*MpesaResult, error) {
 	err := b2c.Validate()
 	if err != nil {
 		return nil, err
 	}
 	var mpesaResult MpesaResult
-	err = m.sendAndProcessStkPushRequest(context.Background(), m.getMpesaURL(string(b2cURL)), b2c, &mpesaResult)
+	err = m.sendAndProcessStkPushRequest(ctx, m.getMpesaURL(string(b2cURL)), b2c, &mpesaResult)
+	return &mpesaResult, err
+}
+
+// ReversalRequest reverses a completed mpesa transaction
+// the outcome is sent to the ResultURL.
+func (m *Mpesa) ReversalRequest(ctx context.Context, body ReversalRequestBody) (*MpesaResult, error) {
+	err := body.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var mpesaResult MpesaResult
+	err = m.sendAndProcessStkPushRequest(ctx, m.getMpesaURL(string(reversal)), body, &mpesaResult)
 	return &mpesaResult, err
 }
 
@@ -170,14 +318,20 @@ func (m *Mpesa) sendAndProcessStkPushRequest(ctx context.Context, url string, da
 	if err != nil {
 		return err
 	}
-	headers := make(map[string]string)
-	headers["Content-Type"] = "application/json"
-	headers["Authorization"] = "Bearer " + token.AccessToken
-
-	resp, err := postRequest(ctx, url, data, headers, m.TimeOut)
+	resp, err := m.post(ctx, url, data, token)
 	if err != nil {
 		return err
 	}
+	if tokenRejected(resp) {
+		// daraja revoked the token before its expiry, retry once with a new one.
+		m.invalidateToken(ctx, token.AccessToken)
+		if token, err = m.GetAccessToken(ctx); err != nil {
+			return err
+		}
+		if resp, err = m.post(ctx, url, data, token); err != nil {
+			return err
+		}
+	}
 	defer func(Body io.ReadCloser) {
 		errx := Body.Close()
 		if errx != nil {
@@ -194,17 +348,30 @@ func (m *Mpesa) sendAndProcessStkPushRequest(ctx context.Context, url string, da
 	return nil
 }
 
-// GetAccessToken will get the token to be used to query data.
-func (m *Mpesa) GetAccessToken(ctx context.Context) (*AccessTokenResponse, error) {
-	// if we have allowed caching.
-	if m.CacheAccessToken {
-		if m2, ok := m.cache.Get("token"); ok {
-			return m2, nil
-		}
-		goto token
+func (m *Mpesa) post(ctx context.Context, url string, data any, token *AccessTokenResponse) (*http.Response, error) {
+	headers := make(map[string]string)
+	headers["Content-Type"] = "application/json"
+	headers["Authorization"] = "Bearer " + token.AccessToken
+	return postRequest(ctx, m.httpClient, url, data, headers)
+}
+
+// tokenRejected tells whether daraja rejected the access token, it
+// answers 401 or 404 with the "Invalid Access Token" error. The body of
+// a rejection is closed, the one of another error can still be read.
+func tokenRejected(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusNotFound {
+		return false
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || bytes.Contains(b, []byte("Invalid Access Token")) {
+		return true
 	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return false
+}
 
-token:
+func (m *Mpesa) requestAccessToken(ctx context.Context) (*AccessTokenResponse, error) {
 	ctx, cancelFunc := context.WithTimeout(ctx, m.TimeOut)
 	defer cancelFunc()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.getMpesaURL(string(tokenURL)), nil)
@@ -215,10 +382,7 @@ token:
 	req.SetBasicAuth(m.ConsumerKey, m.ConsumerSecret)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/json")
-	client := &http.Client{
-		Timeout: m.TimeOut,
-	}
-	resp, err := client.Do(req)
+	resp, err := m.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -233,25 +397,24 @@ token:
 	// Check the reponse code and return early.
 	if resp.StatusCode != http.StatusOK {
 		b, _ := io.ReadAll(resp.Body)
-		return nil, errors.WithMessagef(nil, "url: %s  status code: %d  body: %s", resp.Request.URL, resp.StatusCode, b)
+		return nil, errors.Errorf("url: %s  status code: %d  body: %s", resp.Request.URL, resp.StatusCode, b)
 	}
 
 	var token AccessTokenResponse
 	if errx := json.NewDecoder(resp.Body).Decode(&token); errx != nil {
 		return nil, errors.Wrap(errx, "error converting from json")
 	}
-	// cache the token.
-	m.cache.Set(&AccessTokenResponse{AccessToken: token.AccessToken, ExpireTime: time.Now().Add(time.Minute * 50)})
+	token.ExpireTime = expireTime(token.ExpiresIn)
 	return &token, nil
 }
 
-func postRequest(ctx context.Context, url string, data any, headers map[string]string, timeOut time.Duration) (*http.Response, error) {
+// postRequest sends data as json, the client timeout bounds the request
+// and the read of the response body.
+func postRequest(ctx context.Context, client *http.Client, url string, data any, headers map[string]string) (*http.Response, error) {
 	b, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancelFunc := context.WithTimeout(ctx, timeOut)
-	defer cancelFunc()
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(b))
 	if err != nil {
 		return nil, err
@@ -259,9 +422,6 @@ func postRequest(ctx context.Context, url string, data any, headers map[string]s
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
-	client := &http.Client{
-		Timeout: timeOut,
-	}
 	return client.Do(req)
 }
 
@@ -0,0 +1,221 @@
+package mpesa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"paydex/breaker"
+	"paydex/provider"
+)
+
+// daraja stubs the sandbox, the requests are redirected to srv.
+func daraja(t *testing.T, handler http.HandlerFunc) http.RoundTripper {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		return http.DefaultTransport.RoundTrip(r)
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+var push = StKPushRequestBody{BusinessShortCode: "174379", Amount: "10", PhoneNumber: "254712345678", CallBackURL: "https://example.com/callback"}
+
+func TestStkPushRequest_Transport(t *testing.T) {
+	var paths []string
+	rt := daraja(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/oauth/v1/generate" {
+			w.Write([]byte(`{"access_token": "token", "expires_in": "3599"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"CheckoutRequestID": "ws_CO_1", "ResponseCode": "0"}`))
+	})
+	var hooked []string
+	m := New("key", "secret", WithPassKey("passkey"), WithTransport(rt), WithHooks(provider.Hooks{
+		Response: func(r *http.Request, res *http.Response, err error, _ time.Duration) {
+			hooked = append(hooked, r.URL.Path)
+		},
+	}))
+
+	res, err := m.StkPushRequest(context.Background(), push)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.CheckoutRequestID != "ws_CO_1" {
+		t.Errorf("CheckoutRequestID = %q, want ws_CO_1", res.CheckoutRequestID)
+	}
+	if len(paths) != 2 || len(hooked) != 2 {
+		t.Errorf("daraja got %v, hooks saw %v, want the token and the push requests", paths, hooked)
+	}
+}
+
+func TestStkPushRequest_Breaker(t *testing.T) {
+	requests := 0
+	rt := daraja(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	m := New("key", "secret", WithPassKey("passkey"), WithHTTPClient(&http.Client{Transport: rt}), WithBreaker(breaker.New(2, time.Hour)))
+
+	for i := 0; i < 2; i++ {
+		if _, err := m.StkPushRequest(context.Background(), push); err == nil {
+			t.Fatal("StkPushRequest() succeeded on a 503")
+		}
+	}
+	if _, err := m.StkPushRequest(context.Background(), push); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("StkPushRequest() error = %v, want ErrOpen", err)
+	}
+	if err := m.Ping(context.Background()); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("Ping() = %v, want ErrOpen", err)
+	}
+	if requests != 2 {
+		t.Errorf("daraja got %d requests, want none once open", requests)
+	}
+}
+
+func TestPing_Probe(t *testing.T) {
+	down := true
+	rt := daraja(t, func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"access_token": "token", "expires_in": "3599"}`))
+	})
+	b := breaker.New(1, time.Millisecond)
+	m := New("key", "secret", WithTransport(rt), WithBreaker(b))
+
+	if err := m.Ping(context.Background()); err == nil {
+		t.Fatal("Ping() succeeded on a 503")
+	}
+	down = false
+	time.Sleep(2 * time.Millisecond)
+	if err := m.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v, want the probe to close the circuit", err)
+	}
+	if b.State() != breaker.Closed {
+		t.Errorf("state = %s, want closed", b.State())
+	}
+}
+
+func TestGetAccessToken_SingleFlight(t *testing.T) {
+	var requests int32
+	rt := daraja(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"access_token": "token", "expires_in": "120"}`))
+	})
+	m := New("key", "secret", WithTransport(rt))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.GetAccessToken(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("daraja got %d token requests, want 1", n)
+	}
+	token, err := m.GetAccessToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(token.ExpireTime); until <= 110*time.Second || until > 120*time.Second {
+		t.Errorf("token expires in %s, want the 120s of expires_in", until)
+	}
+
+	// within the refresh margin the token is used while a new one is requested.
+	m.cache.Set(&AccessTokenResponse{AccessToken: "expiring", ExpireTime: time.Now().Add(time.Minute)})
+	if token, _ := m.GetAccessToken(context.Background()); token.AccessToken != "expiring" {
+		t.Errorf("AccessToken = %q, want the cached token", token.AccessToken)
+	}
+	<-m.refreshToken().done
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("daraja got %d token requests, want a background refresh", n)
+	}
+}
+
+func TestStkPushRequest_RejectedToken(t *testing.T) {
+	tokens := 0
+	rt := daraja(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/v1/generate" {
+			tokens++
+			fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": "3599"}`, tokens)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorCode": "404.001.03", "errorMessage": "Invalid Access Token"}`))
+			return
+		}
+		w.Write([]byte(`{"CheckoutRequestID": "ws_CO_1", "ResponseCode": "0"}`))
+	})
+	m := New("key", "secret", WithPassKey("passkey"), WithTransport(rt))
+
+	if _, err := m.StkPushRequest(context.Background(), push); err != nil {
+		t.Fatalf("StkPushRequest() error = %v, want a retry with a new token", err)
+	}
+	if tokens != 2 {
+		t.Errorf("daraja got %d token requests, want 2", tokens)
+	}
+}
+
+type memoryTokens map[string]*AccessTokenResponse
+
+func (s memoryTokens) Get(_ context.Context, key string) (*AccessTokenResponse, error) {
+	return s[key], nil
+}
+
+func (s memoryTokens) Set(_ context.Context, key string, token *AccessTokenResponse) error {
+	s[key] = token
+	return nil
+}
+
+func (s memoryTokens) Delete(_ context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func TestGetAccessToken_Shared(t *testing.T) {
+	requests := 0
+	rt := daraja(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"access_token": "token", "expires_in": "3599"}`))
+	})
+	store := memoryTokens{}
+	first := New("key", "secret", WithTransport(rt), WithTokenStore(store))
+	second := New("key", "secret", WithTransport(rt), WithTokenStore(store))
+
+	for _, m := range []*Mpesa{first, second} {
+		if _, err := m.GetAccessToken(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("daraja got %d token requests, want the token shared", requests)
+	}
+}
@@ -0,0 +1,168 @@
+package mpesa
+
+import (
+	"context"
+	"time"
+
+	"paydex/breaker"
+	"paydex/provider"
+)
+
+// ProviderName is the name mpesa is registered under.
+const ProviderName = "mpesa"
+
+var (
+	_ provider.Provider = (*Mpesa)(nil)
+	_ provider.Pinger   = (*Mpesa)(nil)
+)
+
+func (m *Mpesa) Name() string {
+	return ProviderName
+}
+
+// Ping checks that an access token can be obtained and that the circuit
+// breaker lets the requests through. While the circuit is not closed it
+// probes daraja once the cooldown passed, so the instances without a
+// prober e.g the api close it too.
+func (m *Mpesa) Ping(ctx context.Context) error {
+	if m.breaker != nil && m.breaker.State() != breaker.Closed {
+		return m.Probe(ctx)
+	}
+	_, err := m.GetAccessToken(ctx)
+	return err
+}
+
+// Probe requests a new access token, unlike Ping it always reaches daraja
+// e.g to close the circuit breaker once daraja is back.
+func (m *Mpesa) Probe(ctx context.Context) error {
+	_, err := m.requestAccessToken(ctx)
+	return err
+}
+
+// Collect sends an stk push to the customer.
+func (m *Mpesa) Collect(ctx context.Context, req provider.CollectRequest) (*provider.CollectResult, error) {
+	amount, err := formatAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.StkPushRequest(ctx, StKPushRequestBody{
+		BusinessShortCode: m.DefaultC2BShortCode,
+		Amount:            amount,
+		PhoneNumber:       req.PhoneNumber,
+		CallBackURL:       req.CallbackURL,
+		AccountReference:  req.Reference,
+		TransactionDesc:   req.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	status := provider.StatusPending
+	if res.ResponseCode != "0" {
+		status = provider.StatusFailed
+	}
+	return &provider.CollectResult{
+		TransactionID: res.CheckoutRequestID,
+		Status:        status,
+		Message:       res.ResponseDescription,
+	}, nil
+}
+
+// Payout sends a b2c payment to the customer.
+func (m *Mpesa) Payout(ctx context.Context, req provider.PayoutRequest) (*provider.PayoutResult, error) {
+	amount, err := formatAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.B2CRequest(ctx, B2CRequestBody{
+		InitiatorName:      m.DefaultInitiatorName,
+		SecurityCredential: m.DefaultSecurityCredential,
+		CommandID:          BusinessPayment,
+		Amount:             amount,
+		PartyA:             m.DefaultB2CShortCode,
+		PartyB:             req.PhoneNumber,
+		Remarks:            req.Description,
+		QueueTimeOutURL:    req.CallbackURL,
+		ResultURL:          req.CallbackURL,
+		Occasion:           req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &provider.PayoutResult{
+		TransactionID: res.ConversationID,
+		Status:        resultStatus(res),
+		Message:       res.ResponseDescription,
+	}, nil
+}
+
+// Status queries the result of an stk push.
+func (m *Mpesa) Status(ctx context.Context, req provider.StatusRequest) (*provider.StatusResult, error) {
+	t := time.Now().Format("20060102150405")
+	pass, err := GeneratePassword(m.DefaultC2BShortCode, m.DefaultPassKey, t)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.StkPushQuery(ctx, StkPushQueryRequestBody{
+		BusinessShortCode: m.DefaultC2BShortCode,
+		Password:          pass,
+		Timestamp:         t,
+		CheckoutRequestID: req.TransactionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	status := provider.StatusFailed
+	switch res.ResultCode {
+	case "0":
+		status = provider.StatusCompleted
+	case "":
+		status = provider.StatusPending
+	}
+	return &provider.StatusResult{
+		TransactionID: res.CheckoutRequestID,
+		Status:        status,
+		ResultCode:    res.ResultCode,
+		Message:       res.ResultDesc,
+	}, nil
+}
+
+// Balance is not supported, daraja only posts the balance to the result url.
+func (m *Mpesa) Balance(context.Context, provider.BalanceRequest) (*provider.BalanceResult, error) {
+	return nil, provider.ErrUnsupported
+}
+
+// Refund reverses the transaction, the outcome is posted to the callback url.
+func (m *Mpesa) Refund(ctx context.Context, req provider.RefundRequest) (*provider.RefundResult, error) {
+	amount, err := formatAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.ReversalRequest(ctx, ReversalRequestBody{
+		Initiator:              m.DefaultInitiatorName,
+		SecurityCredential:     m.DefaultSecurityCredential,
+		CommandID:              TransactionReversal,
+		TransactionID:          req.TransactionID,
+		Amount:                 amount,
+		ReceiverParty:          m.DefaultC2BShortCode,
+		RecieverIdentifierType: ShortCodeIdentifier,
+		ResultURL:              req.CallbackURL,
+		QueueTimeOutURL:        req.CallbackURL,
+		Remarks:                req.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &provider.RefundResult{
+		TransactionID: res.ConversationID,
+		Status:        resultStatus(res),
+		Message:       res.ResponseDescription,
+	}, nil
+}
+
+// resultStatus maps the acknowledgement of an asynchronous request.
+func resultStatus(res *MpesaResult) provider.Status {
+	if res.ResponseCode != "0" {
+		return provider.StatusFailed
+	}
+	return provider.StatusPending
+}
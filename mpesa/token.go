@@ -0,0 +1,155 @@
+package mpesa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"paydex/metrics"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// defaultTokenLifetime is used when daraja sends no valid expires_in.
+	defaultTokenLifetime = 3599 * time.Second
+	// tokenRefreshMargin is how long before its expiry the token is
+	// refreshed in the background, the requests keep using it meanwhile.
+	tokenRefreshMargin = 5 * time.Minute
+	// tokenExpirySkew is how long before its expiry the token is no
+	// longer used.
+	tokenExpirySkew = 30 * time.Second
+)
+
+// TokenStore shares the access tokens between the instances so they do
+// not each request one, e.g in redis.
+type TokenStore interface {
+	// Get returns the token stored under key, nil when there is none.
+	Get(ctx context.Context, key string) (*AccessTokenResponse, error)
+	// Set stores the token until it expires.
+	Set(ctx context.Context, key string, token *AccessTokenResponse) error
+	Delete(ctx context.Context, key string) error
+}
+
+// WithTokenStore shares the access token through s, it is used when
+// caching is enabled.
+func WithTokenStore(s TokenStore) ClientOption {
+	return func(m *Mpesa) {
+		m.tokens = s
+	}
+}
+
+// tokenFlight is a token request the concurrent callers wait for.
+type tokenFlight struct {
+	done  chan struct{}
+	token *AccessTokenResponse
+	err   error
+}
+
+// GetAccessToken returns the cached token, concurrent callers share a
+// single request for a new one. A token close to its expiry is returned
+// while a new one is requested in the background.
+func (m *Mpesa) GetAccessToken(ctx context.Context) (*AccessTokenResponse, error) {
+	if !m.CacheAccessToken {
+		token, err := m.requestAccessToken(ctx)
+		metrics.TokenRefreshed(ProviderName, err)
+		return token, err
+	}
+	if token, ok := m.cache.Get(); ok {
+		if !token.validFor(tokenRefreshMargin) {
+			m.refreshToken()
+		}
+		return token, nil
+	}
+	f := m.refreshToken()
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidateToken drops a token daraja rejected so the next request gets
+// a new one.
+func (m *Mpesa) invalidateToken(ctx context.Context, accessToken string) {
+	m.cache.Invalidate(accessToken)
+	if m.tokens == nil {
+		return
+	}
+	stored, err := m.tokens.Get(ctx, m.tokenKey())
+	if err != nil || stored == nil || stored.AccessToken != accessToken {
+		return
+	}
+	if err := m.tokens.Delete(ctx, m.tokenKey()); err != nil {
+		slog.Error("failed to delete the shared mpesa token", err)
+	}
+}
+
+// refreshToken starts a token request unless one is in flight.
+func (m *Mpesa) refreshToken() *tokenFlight {
+	m.flightLock.Lock()
+	defer m.flightLock.Unlock()
+	if m.flight != nil {
+		return m.flight
+	}
+	f := &tokenFlight{done: make(chan struct{})}
+	m.flight = f
+	go func() {
+		// not bound to a caller, one giving up does not fail the others.
+		ctx, cancel := context.WithTimeout(context.Background(), m.TimeOut)
+		defer cancel()
+		f.token, f.err = m.loadToken(ctx)
+		m.flightLock.Lock()
+		m.flight = nil
+		m.flightLock.Unlock()
+		close(f.done)
+	}()
+	return f
+}
+
+// loadToken takes the token another instance stored when it is not close
+// to its expiry, otherwise it requests one and stores it.
+func (m *Mpesa) loadToken(ctx context.Context) (*AccessTokenResponse, error) {
+	if m.tokens != nil {
+		token, err := m.tokens.Get(ctx, m.tokenKey())
+		if err != nil {
+			slog.Error("failed to get the shared mpesa token", err)
+		}
+		if token != nil && token.validFor(tokenRefreshMargin) {
+			m.cache.Set(token)
+			return token, nil
+		}
+	}
+	token, err := m.requestAccessToken(ctx)
+	metrics.TokenRefreshed(ProviderName, err)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.Set(token)
+	if m.tokens != nil {
+		if err := m.tokens.Set(ctx, m.tokenKey(), token); err != nil {
+			slog.Error("failed to share the mpesa token", err)
+		}
+	}
+	return token, nil
+}
+
+// tokenKey identifies the credentials the token is for, without them.
+func (m *Mpesa) tokenKey() string {
+	sum := sha256.Sum256([]byte(strconv.FormatBool(m.Live) + ":" + m.ConsumerKey))
+	return hex.EncodeToString(sum[:16])
+}
+
+// expireTime is when a token received now expires, daraja sends
+// expires_in in seconds as a string.
+func expireTime(expiresIn string) time.Time {
+	lifetime := defaultTokenLifetime
+	if seconds, err := strconv.Atoi(strings.TrimSpace(expiresIn)); err == nil && seconds > 0 {
+		lifetime = time.Duration(seconds) * time.Second
+	}
+	return time.Now().Add(lifetime)
+}
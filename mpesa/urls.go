@@ -14,6 +14,7 @@ const (
 	simulateC2BURL    MURL = "mpesa/c2b/v1/simulate"
 	stkPush           MURL = "mpesa/stkpush/v1/processrequest"
 	stkPushQuery      MURL = "mpesa/stkpushquery/v1/query"
+	reversal          MURL = "mpesa/reversal/v1/request"
 )
 
 func (m *Mpesa) getMpesaURL(s string) string {
@@ -34,6 +35,8 @@ func (m *Mpesa) getMpesaURL(s string) string {
 		return m.getBaseURL() + string(stkPush)
 	case stkPushQuery:
 		return m.getBaseURL() + string(stkPushQuery)
+	case reversal:
+		return m.getBaseURL() + string(reversal)
 	case tokenURL:
 		return m.getBaseURL() + string(tokenURL)
 	default:
@@ -0,0 +1,179 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidNumber      = errors.New("invalid phone number")
+	ErrUnsupportedCountry = errors.New("unsupported country")
+)
+
+// Operator is the mobile network operator a number belongs to.
+type Operator string
+
+const (
+	Unknown   Operator = ""
+	Safaricom Operator = "safaricom"
+	Airtel    Operator = "airtel"
+	Telkom    Operator = "telkom"
+	Equitel   Operator = "equitel"
+	Faiba     Operator = "faiba"
+)
+
+// Kenya is the ISO country code of the default numbering plan.
+const Kenya = "KE"
+
+// plan is the numbering plan of a country.
+type plan struct {
+	country  string
+	dialCode string
+	// length of the national number without the trunk prefix.
+	length int
+	// operators maps the leading digits of the national number to the operator,
+	// the longest matching prefix wins.
+	operators map[string]Operator
+}
+
+// plans are looked up by ISO country code, other east african
+// countries are supported by adding their numbering plan here.
+var plans = map[string]*plan{
+	Kenya: {
+		country:  Kenya,
+		dialCode: "254",
+		length:   9,
+		operators: prefixes(map[Operator][]string{
+			Safaricom: {"70", "71", "72", "740", "741", "742", "743", "745", "746", "748", "757", "758", "759", "768", "769", "79", "110", "111", "112", "113", "114", "115"},
+			Airtel:    {"73", "750", "751", "752", "753", "754", "755", "756", "762", "78", "100", "101", "102"},
+			Telkom:    {"77"},
+			Equitel:   {"763", "764", "765", "766"},
+			Faiba:     {"747"},
+		}),
+	},
+}
+
+func prefixes(operators map[Operator][]string) map[string]Operator {
+	m := make(map[string]Operator)
+	for op, list := range operators {
+		for _, p := range list {
+			m[p] = op
+		}
+	}
+	return m
+}
+
+// Number is a parsed mobile number.
+type Number struct {
+	Country  string
+	DialCode string
+	// National is the number without the country or trunk prefix e.g 712345678.
+	National string
+	Operator Operator
+}
+
+// E164 formats the number as +254712345678.
+func (n Number) E164() string {
+	return "+" + n.MSISDN()
+}
+
+// MSISDN formats the number as 254712345678, the form daraja expects.
+func (n Number) MSISDN() string {
+	return n.DialCode + n.National
+}
+
+// Local formats the number with the trunk prefix e.g 0712345678.
+func (n Number) Local() string {
+	return "0" + n.National
+}
+
+func (n Number) String() string {
+	return n.E164()
+}
+
+// Parse normalizes the common input formats of a mobile number
+// i.e +254712345678, 254712345678, 00254712345678, 0712345678 and 712345678,
+// numbers without a country code are read in the numbering plan of country.
+func Parse(input, country string) (Number, error) {
+	digits, international := clean(input)
+	if digits == "" {
+		return Number{}, fmt.Errorf("%w %q", ErrInvalidNumber, input)
+	}
+
+	var p *plan
+	national := ""
+	if international {
+		for _, candidate := range plans {
+			if strings.HasPrefix(digits, candidate.dialCode) {
+				p, national = candidate, strings.TrimPrefix(digits, candidate.dialCode)
+				break
+			}
+		}
+		if p == nil {
+			return Number{}, fmt.Errorf("%w: no supported country code in %q", ErrUnsupportedCountry, input)
+		}
+	} else {
+		var ok bool
+		if p, ok = plans[strings.ToUpper(country)]; !ok {
+			return Number{}, fmt.Errorf("%w %q", ErrUnsupportedCountry, country)
+		}
+		switch {
+		case len(digits) == len(p.dialCode)+p.length && strings.HasPrefix(digits, p.dialCode):
+			national = strings.TrimPrefix(digits, p.dialCode)
+		case len(digits) == p.length+1 && digits[0] == '0':
+			national = digits[1:]
+		default:
+			national = digits
+		}
+	}
+
+	if len(national) != p.length || national[0] == '0' {
+		return Number{}, fmt.Errorf("%w %q", ErrInvalidNumber, input)
+	}
+	op := p.operator(national)
+	if op == Unknown {
+		return Number{}, fmt.Errorf("%w %q: not a mobile number", ErrInvalidNumber, input)
+	}
+	return Number{Country: p.country, DialCode: p.dialCode, National: national, Operator: op}, nil
+}
+
+// Normalize returns the number in E.164.
+func Normalize(input, country string) (string, error) {
+	n, err := Parse(input, country)
+	if err != nil {
+		return "", err
+	}
+	return n.E164(), nil
+}
+
+func (p *plan) operator(national string) Operator {
+	for i := len(national); i > 0; i-- {
+		if op, ok := p.operators[national[:i]]; ok {
+			return op
+		}
+	}
+	return Unknown
+}
+
+// clean strips the separators people type and reports whether
+// the number starts with an international prefix (+ or 00).
+func clean(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	international := strings.HasPrefix(input, "+")
+	var b strings.Builder
+	for i, r := range input {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.' || (r == '+' && i == 0):
+		default:
+			return "", false
+		}
+	}
+	digits := b.String()
+	if !international && strings.HasPrefix(digits, "00") {
+		return digits[2:], true
+	}
+	return digits, international
+}
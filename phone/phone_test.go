@@ -0,0 +1,55 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     string
+		operator Operator
+		wantErr  error
+	}{
+		{input: "254712345678", want: "+254712345678", operator: Safaricom},
+		{input: "+254 712 345 678", want: "+254712345678", operator: Safaricom},
+		{input: "00254712345678", want: "+254712345678", operator: Safaricom},
+		{input: "0712-345-678", want: "+254712345678", operator: Safaricom},
+		{input: "712345678", want: "+254712345678", operator: Safaricom},
+		{input: "0110345678", want: "+254110345678", operator: Safaricom},
+		{input: "0733000000", want: "+254733000000", operator: Airtel},
+		{input: "0101000000", want: "+254101000000", operator: Airtel},
+		{input: "0772000000", want: "+254772000000", operator: Telkom},
+		{input: "0763000000", want: "+254763000000", operator: Equitel},
+		{input: "071234567", wantErr: ErrInvalidNumber},
+		{input: "0202345678", wantErr: ErrInvalidNumber},
+		{input: "07123a5678", wantErr: ErrInvalidNumber},
+		{input: "", wantErr: ErrInvalidNumber},
+		{input: "+256712345678", wantErr: ErrUnsupportedCountry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, Kenya)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.E164() != tt.want || got.Operator != tt.operator {
+				t.Errorf("Parse() = %v %v, want %v %v", got, got.Operator, tt.want, tt.operator)
+			}
+		})
+	}
+}
+
+func TestNumber_Formats(t *testing.T) {
+	n, err := Parse("0712345678", Kenya)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.MSISDN() != "254712345678" || n.Local() != "0712345678" || n.National != "712345678" {
+		t.Errorf("formats = %s %s %s", n.MSISDN(), n.Local(), n.National)
+	}
+}
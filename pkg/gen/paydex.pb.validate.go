@@ -35,6 +35,131 @@ var (
 	_ = sort.Sort
 )
 
+// define the regex for a UUID once up-front
+var _paydex_uuidPattern = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// Validate checks the field values on Money with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Money) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Money with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in MoneyMultiError, or nil if none found.
+func (m *Money) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Money) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetMinorUnits() <= 0 {
+		err := MoneyValidationError{
+			field:  "MinorUnits",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if !_Money_Currency_Pattern.MatchString(m.GetCurrency()) {
+		err := MoneyValidationError{
+			field:  "Currency",
+			reason: "value does not match regex pattern \"^[A-Z]{3}$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return MoneyMultiError(errors)
+	}
+
+	return nil
+}
+
+// MoneyMultiError is an error wrapping multiple validation errors returned by
+// Money.ValidateAll() if the designated constraints aren't met.
+type MoneyMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MoneyMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MoneyMultiError) AllErrors() []error { return m }
+
+// MoneyValidationError is the validation error returned by Money.Validate if
+// the designated constraints aren't met.
+type MoneyValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MoneyValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MoneyValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MoneyValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MoneyValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MoneyValidationError) ErrorName() string { return "MoneyValidationError" }
+
+// Error satisfies the builtin error interface
+func (e MoneyValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMoney.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MoneyValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MoneyValidationError{}
+
+var _Money_Currency_Pattern = regexp.MustCompile("^[A-Z]{3}$")
+
 // Validate checks the field values on StkPushRequest with the rules defined in
 // the proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
@@ -57,11 +182,122 @@ func (m *StkPushRequest) validate(all bool) error {
 
 	var errors []error
 
-	// no validation rules for PhoneNumber
+	if l := utf8.RuneCountInString(m.GetPhoneNumber()); l < 9 || l > 20 {
+		err := StkPushRequestValidationError{
+			field:  "PhoneNumber",
+			reason: "value length must be between 9 and 20 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
 
-	// no validation rules for Amount
+	if !_StkPushRequest_PhoneNumber_Pattern.MatchString(m.GetPhoneNumber()) {
+		err := StkPushRequestValidationError{
+			field:  "PhoneNumber",
+			reason: "value does not match regex pattern \"^\\\\+?[0-9 ()-]+$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetAmount() == nil {
+		err := StkPushRequestValidationError{
+			field:  "Amount",
+			reason: "value is required",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if all {
+		switch v := interface{}(m.GetAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, StkPushRequestValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, StkPushRequestValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return StkPushRequestValidationError{
+				field:  "Amount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if l := utf8.RuneCountInString(m.GetTransactionDesc()); l < 1 || l > 13 {
+		err := StkPushRequestValidationError{
+			field:  "TransactionDesc",
+			reason: "value length must be between 1 and 13 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetProvider()) > 32 {
+		err := StkPushRequestValidationError{
+			field:  "Provider",
+			reason: "value length must be at most 32 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if !_StkPushRequest_Provider_Pattern.MatchString(m.GetProvider()) {
+		err := StkPushRequestValidationError{
+			field:  "Provider",
+			reason: "value does not match regex pattern \"^[a-z0-9_-]*$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetMerchantId()) > 64 {
+		err := StkPushRequestValidationError{
+			field:  "MerchantId",
+			reason: "value length must be at most 64 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
 
-	// no validation rules for TransactionDesc
+	if !_StkPushRequest_MerchantId_Pattern.MatchString(m.GetMerchantId()) {
+		err := StkPushRequestValidationError{
+			field:  "MerchantId",
+			reason: "value does not match regex pattern \"^[A-Za-z0-9_-]*$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
 
 	if len(errors) > 0 {
 		return StkPushRequestMultiError(errors)
@@ -140,3 +376,2588 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = StkPushRequestValidationError{}
+
+var _StkPushRequest_PhoneNumber_Pattern = regexp.MustCompile("^\\+?[0-9 ()-]+$")
+
+var _StkPushRequest_Provider_Pattern = regexp.MustCompile("^[a-z0-9_-]*$")
+
+var _StkPushRequest_MerchantId_Pattern = regexp.MustCompile("^[A-Za-z0-9_-]*$")
+
+// Validate checks the field values on StkPushResponse with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *StkPushResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on StkPushResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// StkPushResponseMultiError, or nil if none found.
+func (m *StkPushResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *StkPushResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for PaymentId
+
+	// no validation rules for Provider
+
+	if len(errors) > 0 {
+		return StkPushResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// StkPushResponseMultiError is an error wrapping multiple validation errors
+// returned by StkPushResponse.ValidateAll() if the designated constraints
+// aren't met.
+type StkPushResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m StkPushResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m StkPushResponseMultiError) AllErrors() []error { return m }
+
+// StkPushResponseValidationError is the validation error returned by
+// StkPushResponse.Validate if the designated constraints aren't met.
+type StkPushResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e StkPushResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e StkPushResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e StkPushResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e StkPushResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e StkPushResponseValidationError) ErrorName() string { return "StkPushResponseValidationError" }
+
+// Error satisfies the builtin error interface
+func (e StkPushResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sStkPushResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = StkPushResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = StkPushResponseValidationError{}
+
+// Validate checks the field values on ConvertAmountRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ConvertAmountRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ConvertAmountRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ConvertAmountRequestMultiError, or nil if none found.
+func (m *ConvertAmountRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ConvertAmountRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetAmount() == nil {
+		err := ConvertAmountRequestValidationError{
+			field:  "Amount",
+			reason: "value is required",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if all {
+		switch v := interface{}(m.GetAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ConvertAmountRequestValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ConvertAmountRequestValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ConvertAmountRequestValidationError{
+				field:  "Amount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if !_ConvertAmountRequest_To_Pattern.MatchString(m.GetTo()) {
+		err := ConvertAmountRequestValidationError{
+			field:  "To",
+			reason: "value does not match regex pattern \"^[A-Za-z]{3}$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ConvertAmountRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ConvertAmountRequestMultiError is an error wrapping multiple validation
+// errors returned by ConvertAmountRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ConvertAmountRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ConvertAmountRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ConvertAmountRequestMultiError) AllErrors() []error { return m }
+
+// ConvertAmountRequestValidationError is the validation error returned by
+// ConvertAmountRequest.Validate if the designated constraints aren't met.
+type ConvertAmountRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ConvertAmountRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ConvertAmountRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ConvertAmountRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ConvertAmountRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ConvertAmountRequestValidationError) ErrorName() string {
+	return "ConvertAmountRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ConvertAmountRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sConvertAmountRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ConvertAmountRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ConvertAmountRequestValidationError{}
+
+var _ConvertAmountRequest_To_Pattern = regexp.MustCompile("^[A-Za-z]{3}$")
+
+// Validate checks the field values on ConvertAmountResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ConvertAmountResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ConvertAmountResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ConvertAmountResponseMultiError, or nil if none found.
+func (m *ConvertAmountResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ConvertAmountResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ConvertAmountResponseValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ConvertAmountResponseValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ConvertAmountResponseValidationError{
+				field:  "Amount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetConvertedAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ConvertAmountResponseValidationError{
+					field:  "ConvertedAmount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ConvertAmountResponseValidationError{
+					field:  "ConvertedAmount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetConvertedAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ConvertAmountResponseValidationError{
+				field:  "ConvertedAmount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Rate
+
+	if all {
+		switch v := interface{}(m.GetRateTimestamp()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ConvertAmountResponseValidationError{
+					field:  "RateTimestamp",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ConvertAmountResponseValidationError{
+					field:  "RateTimestamp",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetRateTimestamp()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ConvertAmountResponseValidationError{
+				field:  "RateTimestamp",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Source
+
+	if len(errors) > 0 {
+		return ConvertAmountResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ConvertAmountResponseMultiError is an error wrapping multiple validation
+// errors returned by ConvertAmountResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ConvertAmountResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ConvertAmountResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ConvertAmountResponseMultiError) AllErrors() []error { return m }
+
+// ConvertAmountResponseValidationError is the validation error returned by
+// ConvertAmountResponse.Validate if the designated constraints aren't met.
+type ConvertAmountResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ConvertAmountResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ConvertAmountResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ConvertAmountResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ConvertAmountResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ConvertAmountResponseValidationError) ErrorName() string {
+	return "ConvertAmountResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ConvertAmountResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sConvertAmountResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ConvertAmountResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ConvertAmountResponseValidationError{}
+
+// Validate checks the field values on ReplayWebhookRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ReplayWebhookRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReplayWebhookRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReplayWebhookRequestMultiError, or nil if none found.
+func (m *ReplayWebhookRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReplayWebhookRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetDeliveryId()); err != nil {
+		err = ReplayWebhookRequestValidationError{
+			field:  "DeliveryId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ReplayWebhookRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *ReplayWebhookRequest) _validateUuid(uuid string) error {
+	if matched := _paydex_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// ReplayWebhookRequestMultiError is an error wrapping multiple validation
+// errors returned by ReplayWebhookRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ReplayWebhookRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReplayWebhookRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReplayWebhookRequestMultiError) AllErrors() []error { return m }
+
+// ReplayWebhookRequestValidationError is the validation error returned by
+// ReplayWebhookRequest.Validate if the designated constraints aren't met.
+type ReplayWebhookRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReplayWebhookRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReplayWebhookRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReplayWebhookRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReplayWebhookRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReplayWebhookRequestValidationError) ErrorName() string {
+	return "ReplayWebhookRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReplayWebhookRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReplayWebhookRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReplayWebhookRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReplayWebhookRequestValidationError{}
+
+// Validate checks the field values on WebhookDelivery with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *WebhookDelivery) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on WebhookDelivery with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// WebhookDeliveryMultiError, or nil if none found.
+func (m *WebhookDelivery) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *WebhookDelivery) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for MerchantId
+
+	// no validation rules for SubscriptionId
+
+	// no validation rules for Url
+
+	// no validation rules for EventId
+
+	// no validation rules for EventType
+
+	// no validation rules for PaymentId
+
+	// no validation rules for Status
+
+	// no validation rules for Attempts
+
+	// no validation rules for ResponseStatus
+
+	// no validation rules for LastError
+
+	if all {
+		switch v := interface{}(m.GetCreatedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WebhookDeliveryValidationError{
+					field:  "CreatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WebhookDeliveryValidationError{
+					field:  "CreatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetCreatedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WebhookDeliveryValidationError{
+				field:  "CreatedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetUpdatedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, WebhookDeliveryValidationError{
+					field:  "UpdatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, WebhookDeliveryValidationError{
+					field:  "UpdatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUpdatedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return WebhookDeliveryValidationError{
+				field:  "UpdatedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return WebhookDeliveryMultiError(errors)
+	}
+
+	return nil
+}
+
+// WebhookDeliveryMultiError is an error wrapping multiple validation errors
+// returned by WebhookDelivery.ValidateAll() if the designated constraints
+// aren't met.
+type WebhookDeliveryMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m WebhookDeliveryMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m WebhookDeliveryMultiError) AllErrors() []error { return m }
+
+// WebhookDeliveryValidationError is the validation error returned by
+// WebhookDelivery.Validate if the designated constraints aren't met.
+type WebhookDeliveryValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WebhookDeliveryValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WebhookDeliveryValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WebhookDeliveryValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WebhookDeliveryValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WebhookDeliveryValidationError) ErrorName() string { return "WebhookDeliveryValidationError" }
+
+// Error satisfies the builtin error interface
+func (e WebhookDeliveryValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWebhookDelivery.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WebhookDeliveryValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WebhookDeliveryValidationError{}
+
+// Validate checks the field values on ReplayCallbackRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ReplayCallbackRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReplayCallbackRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReplayCallbackRequestMultiError, or nil if none found.
+func (m *ReplayCallbackRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReplayCallbackRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetCallbackId()); err != nil {
+		err = ReplayCallbackRequestValidationError{
+			field:  "CallbackId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ReplayCallbackRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *ReplayCallbackRequest) _validateUuid(uuid string) error {
+	if matched := _paydex_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// ReplayCallbackRequestMultiError is an error wrapping multiple validation
+// errors returned by ReplayCallbackRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ReplayCallbackRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReplayCallbackRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReplayCallbackRequestMultiError) AllErrors() []error { return m }
+
+// ReplayCallbackRequestValidationError is the validation error returned by
+// ReplayCallbackRequest.Validate if the designated constraints aren't met.
+type ReplayCallbackRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReplayCallbackRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReplayCallbackRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReplayCallbackRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReplayCallbackRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReplayCallbackRequestValidationError) ErrorName() string {
+	return "ReplayCallbackRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReplayCallbackRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReplayCallbackRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReplayCallbackRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReplayCallbackRequestValidationError{}
+
+// Validate checks the field values on Callback with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Callback) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Callback with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in CallbackMultiError, or nil
+// if none found.
+func (m *Callback) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Callback) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for Provider
+
+	// no validation rules for Kind
+
+	// no validation rules for Status
+
+	// no validation rules for DedupeKey
+
+	// no validation rules for DuplicateOf
+
+	// no validation rules for Attempts
+
+	// no validation rules for Error
+
+	// no validation rules for Body
+
+	if all {
+		switch v := interface{}(m.GetReceivedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CallbackValidationError{
+					field:  "ReceivedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CallbackValidationError{
+					field:  "ReceivedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetReceivedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CallbackValidationError{
+				field:  "ReceivedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProcessedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CallbackValidationError{
+					field:  "ProcessedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CallbackValidationError{
+					field:  "ProcessedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProcessedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CallbackValidationError{
+				field:  "ProcessedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return CallbackMultiError(errors)
+	}
+
+	return nil
+}
+
+// CallbackMultiError is an error wrapping multiple validation errors returned
+// by Callback.ValidateAll() if the designated constraints aren't met.
+type CallbackMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CallbackMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CallbackMultiError) AllErrors() []error { return m }
+
+// CallbackValidationError is the validation error returned by
+// Callback.Validate if the designated constraints aren't met.
+type CallbackValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CallbackValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CallbackValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CallbackValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CallbackValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CallbackValidationError) ErrorName() string { return "CallbackValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CallbackValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCallback.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CallbackValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CallbackValidationError{}
+
+// Validate checks the field values on GetPaymentRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *GetPaymentRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetPaymentRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetPaymentRequestMultiError, or nil if none found.
+func (m *GetPaymentRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetPaymentRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetPaymentId()); err != nil {
+		err = GetPaymentRequestValidationError{
+			field:  "PaymentId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return GetPaymentRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *GetPaymentRequest) _validateUuid(uuid string) error {
+	if matched := _paydex_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// GetPaymentRequestMultiError is an error wrapping multiple validation errors
+// returned by GetPaymentRequest.ValidateAll() if the designated constraints
+// aren't met.
+type GetPaymentRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetPaymentRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetPaymentRequestMultiError) AllErrors() []error { return m }
+
+// GetPaymentRequestValidationError is the validation error returned by
+// GetPaymentRequest.Validate if the designated constraints aren't met.
+type GetPaymentRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetPaymentRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetPaymentRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetPaymentRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetPaymentRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetPaymentRequestValidationError) ErrorName() string {
+	return "GetPaymentRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetPaymentRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetPaymentRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetPaymentRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetPaymentRequestValidationError{}
+
+// Validate checks the field values on Payment with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Payment) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Payment with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in PaymentMultiError, or nil if none found.
+func (m *Payment) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Payment) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for MerchantId
+
+	// no validation rules for Provider
+
+	// no validation rules for Status
+
+	if all {
+		switch v := interface{}(m.GetAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return PaymentValidationError{
+				field:  "Amount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for PhoneNumber
+
+	// no validation rules for Description
+
+	// no validation rules for TransactionId
+
+	// no validation rules for ResultCode
+
+	// no validation rules for ResultDesc
+
+	// no validation rules for ReceiptNumber
+
+	if all {
+		switch v := interface{}(m.GetOriginalAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "OriginalAmount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "OriginalAmount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetOriginalAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return PaymentValidationError{
+				field:  "OriginalAmount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for ExchangeRate
+
+	if all {
+		switch v := interface{}(m.GetCreatedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "CreatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "CreatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetCreatedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return PaymentValidationError{
+				field:  "CreatedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetUpdatedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "UpdatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, PaymentValidationError{
+					field:  "UpdatedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUpdatedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return PaymentValidationError{
+				field:  "UpdatedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return PaymentMultiError(errors)
+	}
+
+	return nil
+}
+
+// PaymentMultiError is an error wrapping multiple validation errors returned
+// by Payment.ValidateAll() if the designated constraints aren't met.
+type PaymentMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PaymentMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PaymentMultiError) AllErrors() []error { return m }
+
+// PaymentValidationError is the validation error returned by Payment.Validate
+// if the designated constraints aren't met.
+type PaymentValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PaymentValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PaymentValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PaymentValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PaymentValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PaymentValidationError) ErrorName() string { return "PaymentValidationError" }
+
+// Error satisfies the builtin error interface
+func (e PaymentValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPayment.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PaymentValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PaymentValidationError{}
+
+// Validate checks the field values on ListPaymentsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListPaymentsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListPaymentsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListPaymentsRequestMultiError, or nil if none found.
+func (m *ListPaymentsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListPaymentsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if _, ok := _ListPaymentsRequest_Status_InLookup[m.GetStatus()]; !ok {
+		err := ListPaymentsRequestValidationError{
+			field:  "Status",
+			reason: "value must be in list [ pending completed failed expired reversing reversed]",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if all {
+		switch v := interface{}(m.GetCreatedAfter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ListPaymentsRequestValidationError{
+					field:  "CreatedAfter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ListPaymentsRequestValidationError{
+					field:  "CreatedAfter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetCreatedAfter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ListPaymentsRequestValidationError{
+				field:  "CreatedAfter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetCreatedBefore()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ListPaymentsRequestValidationError{
+					field:  "CreatedBefore",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ListPaymentsRequestValidationError{
+					field:  "CreatedBefore",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetCreatedBefore()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ListPaymentsRequestValidationError{
+				field:  "CreatedBefore",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if val := m.GetLimit(); val < 0 || val > 1000 {
+		err := ListPaymentsRequestValidationError{
+			field:  "Limit",
+			reason: "value must be inside range [0, 1000]",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ListPaymentsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListPaymentsRequestMultiError is an error wrapping multiple validation
+// errors returned by ListPaymentsRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ListPaymentsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListPaymentsRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListPaymentsRequestMultiError) AllErrors() []error { return m }
+
+// ListPaymentsRequestValidationError is the validation error returned by
+// ListPaymentsRequest.Validate if the designated constraints aren't met.
+type ListPaymentsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListPaymentsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListPaymentsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListPaymentsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListPaymentsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListPaymentsRequestValidationError) ErrorName() string {
+	return "ListPaymentsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListPaymentsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListPaymentsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListPaymentsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListPaymentsRequestValidationError{}
+
+var _ListPaymentsRequest_Status_InLookup = map[string]struct{}{
+	"":          {},
+	"pending":   {},
+	"completed": {},
+	"failed":    {},
+	"expired":   {},
+	"reversing": {},
+	"reversed":  {},
+}
+
+// Validate checks the field values on ListPaymentsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListPaymentsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListPaymentsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListPaymentsResponseMultiError, or nil if none found.
+func (m *ListPaymentsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListPaymentsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetPayments() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ListPaymentsResponseValidationError{
+						field:  fmt.Sprintf("Payments[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ListPaymentsResponseValidationError{
+						field:  fmt.Sprintf("Payments[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ListPaymentsResponseValidationError{
+					field:  fmt.Sprintf("Payments[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return ListPaymentsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListPaymentsResponseMultiError is an error wrapping multiple validation
+// errors returned by ListPaymentsResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ListPaymentsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListPaymentsResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListPaymentsResponseMultiError) AllErrors() []error { return m }
+
+// ListPaymentsResponseValidationError is the validation error returned by
+// ListPaymentsResponse.Validate if the designated constraints aren't met.
+type ListPaymentsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListPaymentsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListPaymentsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListPaymentsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListPaymentsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListPaymentsResponseValidationError) ErrorName() string {
+	return "ListPaymentsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListPaymentsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListPaymentsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListPaymentsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListPaymentsResponseValidationError{}
+
+// Validate checks the field values on PayoutRequest with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *PayoutRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on PayoutRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in PayoutRequestMultiError, or
+// nil if none found.
+func (m *PayoutRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *PayoutRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if l := utf8.RuneCountInString(m.GetPhoneNumber()); l < 9 || l > 20 {
+		err := PayoutRequestValidationError{
+			field:  "PhoneNumber",
+			reason: "value length must be between 9 and 20 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if !_PayoutRequest_PhoneNumber_Pattern.MatchString(m.GetPhoneNumber()) {
+		err := PayoutRequestValidationError{
+			field:  "PhoneNumber",
+			reason: "value does not match regex pattern \"^\\\\+?[0-9 ()-]+$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetAmount() == nil {
+		err := PayoutRequestValidationError{
+			field:  "Amount",
+			reason: "value is required",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if all {
+		switch v := interface{}(m.GetAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, PayoutRequestValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, PayoutRequestValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return PayoutRequestValidationError{
+				field:  "Amount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if l := utf8.RuneCountInString(m.GetDescription()); l < 1 || l > 100 {
+		err := PayoutRequestValidationError{
+			field:  "Description",
+			reason: "value length must be between 1 and 100 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetProvider()) > 32 {
+		err := PayoutRequestValidationError{
+			field:  "Provider",
+			reason: "value length must be at most 32 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if !_PayoutRequest_Provider_Pattern.MatchString(m.GetProvider()) {
+		err := PayoutRequestValidationError{
+			field:  "Provider",
+			reason: "value does not match regex pattern \"^[a-z0-9_-]*$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return PayoutRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// PayoutRequestMultiError is an error wrapping multiple validation errors
+// returned by PayoutRequest.ValidateAll() if the designated constraints
+// aren't met.
+type PayoutRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PayoutRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PayoutRequestMultiError) AllErrors() []error { return m }
+
+// PayoutRequestValidationError is the validation error returned by
+// PayoutRequest.Validate if the designated constraints aren't met.
+type PayoutRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PayoutRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PayoutRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PayoutRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PayoutRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PayoutRequestValidationError) ErrorName() string { return "PayoutRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e PayoutRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPayoutRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PayoutRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PayoutRequestValidationError{}
+
+var _PayoutRequest_PhoneNumber_Pattern = regexp.MustCompile("^\\+?[0-9 ()-]+$")
+
+var _PayoutRequest_Provider_Pattern = regexp.MustCompile("^[a-z0-9_-]*$")
+
+// Validate checks the field values on GetBalanceRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *GetBalanceRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetBalanceRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetBalanceRequestMultiError, or nil if none found.
+func (m *GetBalanceRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetBalanceRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if utf8.RuneCountInString(m.GetProvider()) > 32 {
+		err := GetBalanceRequestValidationError{
+			field:  "Provider",
+			reason: "value length must be at most 32 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if !_GetBalanceRequest_Provider_Pattern.MatchString(m.GetProvider()) {
+		err := GetBalanceRequestValidationError{
+			field:  "Provider",
+			reason: "value does not match regex pattern \"^[a-z0-9_-]*$\"",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetAccountId()) > 64 {
+		err := GetBalanceRequestValidationError{
+			field:  "AccountId",
+			reason: "value length must be at most 64 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return GetBalanceRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetBalanceRequestMultiError is an error wrapping multiple validation errors
+// returned by GetBalanceRequest.ValidateAll() if the designated constraints
+// aren't met.
+type GetBalanceRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetBalanceRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetBalanceRequestMultiError) AllErrors() []error { return m }
+
+// GetBalanceRequestValidationError is the validation error returned by
+// GetBalanceRequest.Validate if the designated constraints aren't met.
+type GetBalanceRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetBalanceRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetBalanceRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetBalanceRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetBalanceRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetBalanceRequestValidationError) ErrorName() string {
+	return "GetBalanceRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetBalanceRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetBalanceRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetBalanceRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetBalanceRequestValidationError{}
+
+var _GetBalanceRequest_Provider_Pattern = regexp.MustCompile("^[a-z0-9_-]*$")
+
+// Validate checks the field values on Balance with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Balance) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Balance with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in BalanceMultiError, or nil if none found.
+func (m *Balance) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Balance) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Type
+
+	if all {
+		switch v := interface{}(m.GetAmount()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, BalanceValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, BalanceValidationError{
+					field:  "Amount",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetAmount()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return BalanceValidationError{
+				field:  "Amount",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return BalanceMultiError(errors)
+	}
+
+	return nil
+}
+
+// BalanceMultiError is an error wrapping multiple validation errors returned
+// by Balance.ValidateAll() if the designated constraints aren't met.
+type BalanceMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m BalanceMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m BalanceMultiError) AllErrors() []error { return m }
+
+// BalanceValidationError is the validation error returned by Balance.Validate
+// if the designated constraints aren't met.
+type BalanceValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e BalanceValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e BalanceValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e BalanceValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e BalanceValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e BalanceValidationError) ErrorName() string { return "BalanceValidationError" }
+
+// Error satisfies the builtin error interface
+func (e BalanceValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sBalance.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = BalanceValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = BalanceValidationError{}
+
+// Validate checks the field values on GetBalanceResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetBalanceResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetBalanceResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetBalanceResponseMultiError, or nil if none found.
+func (m *GetBalanceResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetBalanceResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetBalances() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GetBalanceResponseValidationError{
+						field:  fmt.Sprintf("Balances[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GetBalanceResponseValidationError{
+						field:  fmt.Sprintf("Balances[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GetBalanceResponseValidationError{
+					field:  fmt.Sprintf("Balances[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return GetBalanceResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetBalanceResponseMultiError is an error wrapping multiple validation errors
+// returned by GetBalanceResponse.ValidateAll() if the designated constraints
+// aren't met.
+type GetBalanceResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetBalanceResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetBalanceResponseMultiError) AllErrors() []error { return m }
+
+// GetBalanceResponseValidationError is the validation error returned by
+// GetBalanceResponse.Validate if the designated constraints aren't met.
+type GetBalanceResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetBalanceResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetBalanceResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetBalanceResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetBalanceResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetBalanceResponseValidationError) ErrorName() string {
+	return "GetBalanceResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetBalanceResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetBalanceResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetBalanceResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetBalanceResponseValidationError{}
+
+// Validate checks the field values on ReversePaymentRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ReversePaymentRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReversePaymentRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReversePaymentRequestMultiError, or nil if none found.
+func (m *ReversePaymentRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReversePaymentRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetPaymentId()); err != nil {
+		err = ReversePaymentRequestValidationError{
+			field:  "PaymentId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if l := utf8.RuneCountInString(m.GetReason()); l < 1 || l > 100 {
+		err := ReversePaymentRequestValidationError{
+			field:  "Reason",
+			reason: "value length must be between 1 and 100 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ReversePaymentRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *ReversePaymentRequest) _validateUuid(uuid string) error {
+	if matched := _paydex_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// ReversePaymentRequestMultiError is an error wrapping multiple validation
+// errors returned by ReversePaymentRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ReversePaymentRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReversePaymentRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReversePaymentRequestMultiError) AllErrors() []error { return m }
+
+// ReversePaymentRequestValidationError is the validation error returned by
+// ReversePaymentRequest.Validate if the designated constraints aren't met.
+type ReversePaymentRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReversePaymentRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReversePaymentRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReversePaymentRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReversePaymentRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReversePaymentRequestValidationError) ErrorName() string {
+	return "ReversePaymentRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReversePaymentRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReversePaymentRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReversePaymentRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReversePaymentRequestValidationError{}
+
+// Validate checks the field values on ProviderResult with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *ProviderResult) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ProviderResult with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in ProviderResultMultiError,
+// or nil if none found.
+func (m *ProviderResult) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ProviderResult) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Provider
+
+	// no validation rules for TransactionId
+
+	// no validation rules for Status
+
+	// no validation rules for Message
+
+	if len(errors) > 0 {
+		return ProviderResultMultiError(errors)
+	}
+
+	return nil
+}
+
+// ProviderResultMultiError is an error wrapping multiple validation errors
+// returned by ProviderResult.ValidateAll() if the designated constraints
+// aren't met.
+type ProviderResultMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ProviderResultMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ProviderResultMultiError) AllErrors() []error { return m }
+
+// ProviderResultValidationError is the validation error returned by
+// ProviderResult.Validate if the designated constraints aren't met.
+type ProviderResultValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ProviderResultValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ProviderResultValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ProviderResultValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ProviderResultValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ProviderResultValidationError) ErrorName() string { return "ProviderResultValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ProviderResultValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sProviderResult.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ProviderResultValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ProviderResultValidationError{}
@@ -1,4 +1,8 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: paydex.proto
 
 package pkg
 
@@ -7,7 +11,6 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
-	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,7 +22,20 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PaydexServiceClient interface {
-	InitStkPush(ctx context.Context, in *StkPushRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	InitStkPush(ctx context.Context, in *StkPushRequest, opts ...grpc.CallOption) (*StkPushResponse, error)
+	ConvertAmount(ctx context.Context, in *ConvertAmountRequest, opts ...grpc.CallOption) (*ConvertAmountResponse, error)
+	// ReplayWebhook sends a logged webhook delivery again.
+	ReplayWebhook(ctx context.Context, in *ReplayWebhookRequest, opts ...grpc.CallOption) (*WebhookDelivery, error)
+	// ReplayCallback processes a stored provider callback again.
+	ReplayCallback(ctx context.Context, in *ReplayCallbackRequest, opts ...grpc.CallOption) (*Callback, error)
+	GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	// ListPayments returns the payments oldest first.
+	ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error)
+	// Payout sends money to a customer, the outcome is posted to the result callback.
+	Payout(ctx context.Context, in *PayoutRequest, opts ...grpc.CallOption) (*ProviderResult, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	// ReversePayment reverses a completed payment, the outcome is posted to the result callback.
+	ReversePayment(ctx context.Context, in *ReversePaymentRequest, opts ...grpc.CallOption) (*ProviderResult, error)
 }
 
 type paydexServiceClient struct {
@@ -30,8 +46,8 @@ func NewPaydexServiceClient(cc grpc.ClientConnInterface) PaydexServiceClient {
 	return &paydexServiceClient{cc}
 }
 
-func (c *paydexServiceClient) InitStkPush(ctx context.Context, in *StkPushRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *paydexServiceClient) InitStkPush(ctx context.Context, in *StkPushRequest, opts ...grpc.CallOption) (*StkPushResponse, error) {
+	out := new(StkPushResponse)
 	err := c.cc.Invoke(ctx, "/PaydexService/InitStkPush", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -39,11 +55,96 @@ func (c *paydexServiceClient) InitStkPush(ctx context.Context, in *StkPushReques
 	return out, nil
 }
 
+func (c *paydexServiceClient) ConvertAmount(ctx context.Context, in *ConvertAmountRequest, opts ...grpc.CallOption) (*ConvertAmountResponse, error) {
+	out := new(ConvertAmountResponse)
+	err := c.cc.Invoke(ctx, "/PaydexService/ConvertAmount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paydexServiceClient) ReplayWebhook(ctx context.Context, in *ReplayWebhookRequest, opts ...grpc.CallOption) (*WebhookDelivery, error) {
+	out := new(WebhookDelivery)
+	err := c.cc.Invoke(ctx, "/PaydexService/ReplayWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paydexServiceClient) ReplayCallback(ctx context.Context, in *ReplayCallbackRequest, opts ...grpc.CallOption) (*Callback, error) {
+	out := new(Callback)
+	err := c.cc.Invoke(ctx, "/PaydexService/ReplayCallback", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paydexServiceClient) GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, "/PaydexService/GetPayment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paydexServiceClient) ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error) {
+	out := new(ListPaymentsResponse)
+	err := c.cc.Invoke(ctx, "/PaydexService/ListPayments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paydexServiceClient) Payout(ctx context.Context, in *PayoutRequest, opts ...grpc.CallOption) (*ProviderResult, error) {
+	out := new(ProviderResult)
+	err := c.cc.Invoke(ctx, "/PaydexService/Payout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paydexServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
+	out := new(GetBalanceResponse)
+	err := c.cc.Invoke(ctx, "/PaydexService/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paydexServiceClient) ReversePayment(ctx context.Context, in *ReversePaymentRequest, opts ...grpc.CallOption) (*ProviderResult, error) {
+	out := new(ProviderResult)
+	err := c.cc.Invoke(ctx, "/PaydexService/ReversePayment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaydexServiceServer is the server API for PaydexService service.
 // All implementations must embed UnimplementedPaydexServiceServer
 // for forward compatibility
 type PaydexServiceServer interface {
-	InitStkPush(context.Context, *StkPushRequest) (*emptypb.Empty, error)
+	InitStkPush(context.Context, *StkPushRequest) (*StkPushResponse, error)
+	ConvertAmount(context.Context, *ConvertAmountRequest) (*ConvertAmountResponse, error)
+	// ReplayWebhook sends a logged webhook delivery again.
+	ReplayWebhook(context.Context, *ReplayWebhookRequest) (*WebhookDelivery, error)
+	// ReplayCallback processes a stored provider callback again.
+	ReplayCallback(context.Context, *ReplayCallbackRequest) (*Callback, error)
+	GetPayment(context.Context, *GetPaymentRequest) (*Payment, error)
+	// ListPayments returns the payments oldest first.
+	ListPayments(context.Context, *ListPaymentsRequest) (*ListPaymentsResponse, error)
+	// Payout sends money to a customer, the outcome is posted to the result callback.
+	Payout(context.Context, *PayoutRequest) (*ProviderResult, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
+	// ReversePayment reverses a completed payment, the outcome is posted to the result callback.
+	ReversePayment(context.Context, *ReversePaymentRequest) (*ProviderResult, error)
 	mustEmbedUnimplementedPaydexServiceServer()
 }
 
@@ -51,9 +152,33 @@ type PaydexServiceServer interface {
 type UnimplementedPaydexServiceServer struct {
 }
 
-func (UnimplementedPaydexServiceServer) InitStkPush(context.Context, *StkPushRequest) (*emptypb.Empty, error) {
+func (UnimplementedPaydexServiceServer) InitStkPush(context.Context, *StkPushRequest) (*StkPushResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InitStkPush not implemented")
 }
+func (UnimplementedPaydexServiceServer) ConvertAmount(context.Context, *ConvertAmountRequest) (*ConvertAmountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConvertAmount not implemented")
+}
+func (UnimplementedPaydexServiceServer) ReplayWebhook(context.Context, *ReplayWebhookRequest) (*WebhookDelivery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayWebhook not implemented")
+}
+func (UnimplementedPaydexServiceServer) ReplayCallback(context.Context, *ReplayCallbackRequest) (*Callback, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayCallback not implemented")
+}
+func (UnimplementedPaydexServiceServer) GetPayment(context.Context, *GetPaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayment not implemented")
+}
+func (UnimplementedPaydexServiceServer) ListPayments(context.Context, *ListPaymentsRequest) (*ListPaymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPayments not implemented")
+}
+func (UnimplementedPaydexServiceServer) Payout(context.Context, *PayoutRequest) (*ProviderResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Payout not implemented")
+}
+func (UnimplementedPaydexServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedPaydexServiceServer) ReversePayment(context.Context, *ReversePaymentRequest) (*ProviderResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReversePayment not implemented")
+}
 func (UnimplementedPaydexServiceServer) mustEmbedUnimplementedPaydexServiceServer() {}
 
 // UnsafePaydexServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -85,6 +210,150 @@ func _PaydexService_InitStkPush_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaydexService_ConvertAmount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertAmountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).ConvertAmount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/ConvertAmount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).ConvertAmount(ctx, req.(*ConvertAmountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaydexService_ReplayWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).ReplayWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/ReplayWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).ReplayWebhook(ctx, req.(*ReplayWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaydexService_ReplayCallback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayCallbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).ReplayCallback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/ReplayCallback",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).ReplayCallback(ctx, req.(*ReplayCallbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaydexService_GetPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).GetPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/GetPayment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).GetPayment(ctx, req.(*GetPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaydexService_ListPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPaymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).ListPayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/ListPayments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).ListPayments(ctx, req.(*ListPaymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaydexService_Payout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).Payout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/Payout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).Payout(ctx, req.(*PayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaydexService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaydexService_ReversePayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReversePaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaydexServiceServer).ReversePayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/PaydexService/ReversePayment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaydexServiceServer).ReversePayment(ctx, req.(*ReversePaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaydexService_ServiceDesc is the grpc.ServiceDesc for PaydexService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -96,6 +365,38 @@ var PaydexService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "InitStkPush",
 			Handler:    _PaydexService_InitStkPush_Handler,
 		},
+		{
+			MethodName: "ConvertAmount",
+			Handler:    _PaydexService_ConvertAmount_Handler,
+		},
+		{
+			MethodName: "ReplayWebhook",
+			Handler:    _PaydexService_ReplayWebhook_Handler,
+		},
+		{
+			MethodName: "ReplayCallback",
+			Handler:    _PaydexService_ReplayCallback_Handler,
+		},
+		{
+			MethodName: "GetPayment",
+			Handler:    _PaydexService_GetPayment_Handler,
+		},
+		{
+			MethodName: "ListPayments",
+			Handler:    _PaydexService_ListPayments_Handler,
+		},
+		{
+			MethodName: "Payout",
+			Handler:    _PaydexService_Payout_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _PaydexService_GetBalance_Handler,
+		},
+		{
+			MethodName: "ReversePayment",
+			Handler:    _PaydexService_ReversePayment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "paydex.proto",
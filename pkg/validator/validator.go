@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validatorAll is implemented by messages generated by protoc-gen-validate.
+type validatorAll interface {
+	ValidateAll() error
+}
+
+type validator interface {
+	Validate() error
+}
+
+// fieldError is implemented by the generated <Message>ValidationError types.
+type fieldError interface {
+	Field() string
+	Reason() string
+}
+
+// multiError is implemented by the generated <Message>MultiError types.
+type multiError interface {
+	AllErrors() []error
+}
+
+// UnaryServerInterceptor rejects requests that break the rules in the proto definition.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := Validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor validates every message received on the stream.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: stream})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return Validate(m)
+}
+
+// Validate returns an InvalidArgument status with a BadRequest detail
+// listing every violated field.
+func Validate(m any) error {
+	var err error
+	switch v := m.(type) {
+	case validatorAll:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	default:
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+
+	errs := []error{err}
+	if multi, ok := err.(multiError); ok {
+		errs = multi.AllErrors()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, e := range errs {
+		violation := &errdetails.BadRequest_FieldViolation{Description: e.Error()}
+		if f, ok := e.(fieldError); ok {
+			violation.Field = jsonName(f.Field())
+			violation.Description = f.Reason()
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, violation)
+	}
+
+	st := status.New(codes.InvalidArgument, err.Error())
+	if detailed, errx := st.WithDetails(badRequest); errx == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// jsonName converts the generated field name to the json name used by the gateway.
+func jsonName(field string) string {
+	if field == "" {
+		return field
+	}
+	return strings.ToLower(field[:1]) + field[1:]
+}
@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"testing"
+
+	pb "paydex/pkg/gen"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidate(t *testing.T) {
+	valid := &pb.StkPushRequest{PhoneNumber: "254712345678", Amount: &pb.Money{MinorUnits: 1000, Currency: "KES"}, TransactionDesc: "order"}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	err := Validate(&pb.StkPushRequest{PhoneNumber: "07123a5678", TransactionDesc: "order"})
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Fatalf("Validate() error = %v, want InvalidArgument", err)
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+		}
+	}
+	if len(fields) != 2 || fields[0] != "phoneNumber" || fields[1] != "amount" {
+		t.Errorf("violations = %v, want [phoneNumber amount]", fields)
+	}
+}
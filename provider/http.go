@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Transport is the connection pool shared by the provider clients, the
+// connections are kept alive so the requests skip the tls handshake.
+var Transport http.RoundTripper = NewTransport()
+
+// NewTransport is tuned for many concurrent requests to a few hosts,
+// http.DefaultTransport keeps only 2 idle connections per host.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          200,
+		MaxIdleConnsPerHost:   100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// Hooks observe the http requests of a provider client e.g to log them.
+type Hooks struct {
+	// Request is called before the request is sent.
+	Request func(r *http.Request)
+	// Response is called with the response, or the error, of the request.
+	Response func(r *http.Request, res *http.Response, err error, elapsed time.Duration)
+}
+
+// Transport calls the hooks around the requests sent through base.
+func (h Hooks) Transport(base http.RoundTripper) http.RoundTripper {
+	if h.Request == nil && h.Response == nil {
+		return base
+	}
+	return &hooked{hooks: h, base: base}
+}
+
+type hooked struct {
+	hooks Hooks
+	base  http.RoundTripper
+}
+
+func (t *hooked) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.hooks.Request != nil {
+		t.hooks.Request(r)
+	}
+	start := time.Now()
+	res, err := t.base.RoundTrip(r)
+	if t.hooks.Response != nil {
+		t.hooks.Response(r, res, err, time.Since(start))
+	}
+	return res, err
+}
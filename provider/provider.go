@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"paydex/money"
+)
+
+// ErrUnsupported is returned when a provider does not offer a capability
+// e.g. balance enquiries on rails that only report balances asynchronously.
+var ErrUnsupported = errors.New("capability not supported by provider")
+
+// Status is the provider agnostic state of a transaction.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Provider is implemented by every payment rail (mpesa, jenga ...).
+// the worker and the gRPC layer only talk to this interface.
+type Provider interface {
+	// Name is the identifier clients use to route requests to the provider.
+	Name() string
+	// Collect requests money from a customer i.e stk/ussd push.
+	Collect(ctx context.Context, req CollectRequest) (*CollectResult, error)
+	// Payout sends money from the business to a customer.
+	Payout(ctx context.Context, req PayoutRequest) (*PayoutResult, error)
+	// Status queries the state of a transaction started by the provider.
+	Status(ctx context.Context, req StatusRequest) (*StatusResult, error)
+	// Balance returns the balances of the business account.
+	Balance(ctx context.Context, req BalanceRequest) (*BalanceResult, error)
+	// Refund reverses a completed transaction.
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+}
+
+// Pinger is implemented by providers that can check they are reachable
+// with the configured credentials, it is used by the readiness checks.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type CollectRequest struct {
+	Amount      money.Money
+	PhoneNumber string
+	// Reference is shown to the customer e.g account number.
+	Reference   string
+	Description string
+	CallbackURL string
+}
+
+type CollectResult struct {
+	// TransactionID is the id the provider uses for the request
+	// e.g the mpesa CheckoutRequestID.
+	TransactionID string
+	Status        Status
+	Message       string
+}
+
+type PayoutRequest struct {
+	Amount      money.Money
+	PhoneNumber string
+	Name        string
+	Reference   string
+	Description string
+	CallbackURL string
+}
+
+type PayoutResult struct {
+	TransactionID string
+	Status        Status
+	Message       string
+}
+
+type StatusRequest struct {
+	TransactionID string
+}
+
+type StatusResult struct {
+	TransactionID string
+	Status        Status
+	// ResultCode is the raw provider result code.
+	ResultCode string
+	Message    string
+}
+
+type BalanceRequest struct {
+	AccountID   string
+	CountryCode string
+}
+
+type Balance struct {
+	Type   string
+	Amount money.Money
+}
+
+type BalanceResult struct {
+	Balances []Balance
+}
+
+type RefundRequest struct {
+	TransactionID string
+	Amount        money.Money
+	Reason        string
+	CallbackURL   string
+}
+
+type RefundResult struct {
+	TransactionID string
+	Status        Status
+	Message       string
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry routes requests to providers by name.
+type Registry struct {
+	providers map[string]Provider
+	// fallback is used when a request does not name a provider.
+	fallback string
+	lock     *sync.RWMutex
+}
+
+func NewRegistry(fallback string, providers ...Provider) *Registry {
+	r := &Registry{
+		providers: make(map[string]Provider),
+		fallback:  fallback,
+		lock:      &sync.RWMutex{},
+	}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds the provider replacing any provider with the same name.
+func (r *Registry) Register(p Provider) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.providers[p.Name()] = p
+}
+
+// Get returns the named provider, an empty name returns the default provider.
+func (r *Registry) Get(name string) (Provider, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if name == "" {
+		name = r.fallback
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	return p, nil
+}
+
+// Names lists the registered providers.
+func (r *Registry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeProvider struct {
+	name string
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+func (f *fakeProvider) Collect(context.Context, CollectRequest) (*CollectResult, error) {
+	return nil, ErrUnsupported
+}
+func (f *fakeProvider) Payout(context.Context, PayoutRequest) (*PayoutResult, error) {
+	return nil, ErrUnsupported
+}
+func (f *fakeProvider) Status(context.Context, StatusRequest) (*StatusResult, error) {
+	return nil, ErrUnsupported
+}
+func (f *fakeProvider) Balance(context.Context, BalanceRequest) (*BalanceResult, error) {
+	return nil, ErrUnsupported
+}
+func (f *fakeProvider) Refund(context.Context, RefundRequest) (*RefundResult, error) {
+	return nil, ErrUnsupported
+}
+
+func TestRegistry_Get(t *testing.T) {
+	r := NewRegistry("mpesa", &fakeProvider{name: "mpesa"}, &fakeProvider{name: "jenga"})
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: "mpesa"},
+		{name: "jenga", want: "jenga"},
+		{name: "airtel", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Get(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Registry.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Name() != tt.want {
+				t.Errorf("Registry.Get() = %v, want %v", got.Name(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"paydex/metrics"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/exp/slog"
+)
+
+// ErrThrottled is returned when no token is available before the deadline
+// of the request.
+var ErrThrottled = errors.New("rate limited until past the deadline")
+
+// take refills the bucket for the time passed since the last token was
+// taken and takes one, it returns 0 or the milliseconds until one is available.
+// the redis clock is used so the instances agree on the time.
+var take = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local b = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(b[1]) or burst
+local ts = tonumber(b[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return wait`)
+
+// Bucket is a token bucket shared by the instances, e.g to keep the
+// requests to a provider under the rate it allows.
+type Bucket struct {
+	client redis.UniversalClient
+	name   string
+	rate   float64
+	burst  int
+}
+
+// NewBucket allows rate requests per second and bursts of burst requests,
+// at least one.
+func NewBucket(client redis.UniversalClient, name string, rate float64, burst int) *Bucket {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &Bucket{client: client, name: name, rate: rate, burst: burst}
+}
+
+// Wait blocks until a token is taken, it returns ErrThrottled without
+// waiting when the token comes after the deadline of ctx. redis errors are
+// logged and the request let through, the provider enforces its own limit.
+func (b *Bucket) Wait(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		metrics.ThrottleWait.WithLabelValues(b.name).Observe(time.Since(start).Seconds())
+	}()
+	for {
+		wait, err := take.Run(ctx, b.client, []string{keyPrefix + "bucket:" + b.name}, b.rate, b.burst).Int64()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Error("rate limit bucket failed", err, "bucket", b.name)
+			return nil
+		}
+		if wait <= 0 {
+			return nil
+		}
+		d := time.Duration(wait) * time.Millisecond
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return ErrThrottled
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Networks are addresses and cidr ranges e.g the trusted proxies whose
+// x-forwarded-for is read to find the caller.
+type Networks []*net.IPNet
+
+// ParseNetworks reads ip addresses and cidr ranges.
+func ParseNetworks(addresses []string) (Networks, error) {
+	networks := make(Networks, 0, len(addresses))
+	for _, a := range addresses {
+		if !strings.Contains(a, "/") {
+			ip := net.ParseIP(a)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address %s", a)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// Contains reports whether the ip is in one of the networks.
+func (n Networks) Contains(ip net.IP) bool {
+	for _, network := range n {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
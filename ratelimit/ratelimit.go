@@ -0,0 +1,120 @@
+// Package ratelimit counts requests in redis so the limits hold across
+// the instances.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"paydex/events"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/exp/slog"
+)
+
+const keyPrefix = "paydex:ratelimit:"
+
+// count increments the counter of the window and returns the count and
+// the milliseconds left in the window.
+var count = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {n, redis.call("PTTL", KEYS[1])}`)
+
+// release deletes the hold when it is still held for the id.
+var release = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Limiter counts requests in fixed windows and holds per key slots.
+type Limiter struct {
+	client redis.UniversalClient
+}
+
+func New(client redis.UniversalClient) *Limiter {
+	return &Limiter{client: client}
+}
+
+// Allow counts a request against the key, when more than limit requests
+// were made in the window it returns false and how long until it resets.
+func (l *Limiter) Allow(ctx context.Context, scope, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	res, err := count.Run(ctx, l.client, []string{redisKey(scope, key)}, window.Milliseconds()).Result()
+	if err != nil {
+		return false, 0, err
+	}
+	reply, ok := res.([]interface{})
+	if !ok || len(reply) != 2 {
+		return false, 0, fmt.Errorf("unexpected reply %v", res)
+	}
+	n, _ := reply[0].(int64)
+	left, _ := reply[1].(int64)
+	if n <= int64(limit) {
+		return true, 0, nil
+	}
+	return false, ttl(left, window), nil
+}
+
+// Hold takes the slot of the key for id until it is released or ttl
+// passed, when another id holds it it returns false and how long it is held.
+func (l *Limiter) Hold(ctx context.Context, scope, key, id string, hold time.Duration) (bool, time.Duration, error) {
+	k := redisKey(scope, key)
+	held, err := l.client.SetNX(ctx, k, id, hold).Result()
+	if err != nil || held {
+		return held, 0, err
+	}
+	left, err := l.client.PTTL(ctx, k).Result()
+	if err != nil {
+		return false, 0, err
+	}
+	return false, ttl(left.Milliseconds(), hold), nil
+}
+
+// Release frees the slot of the key when id still holds it.
+func (l *Limiter) Release(ctx context.Context, scope, key, id string) error {
+	return release.Run(ctx, l.client, []string{redisKey(scope, key)}, id).Err()
+}
+
+// redisKey hashes the key, it may be personal data e.g a phone number.
+func redisKey(scope, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return keyPrefix + scope + ":" + hex.EncodeToString(sum[:16])
+}
+
+// ttl falls back to the whole window for keys without an expiry.
+func ttl(ms int64, window time.Duration) time.Duration {
+	if ms <= 0 {
+		return window
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// ScopePendingPhone is the slot of a push awaiting its outcome.
+const ScopePendingPhone = "pending_phone"
+
+// ReleasingPublisher frees the pending slot of the phone once the payment
+// reached a final state, then publishes the event.
+type ReleasingPublisher struct {
+	events.Publisher
+	limiter *Limiter
+}
+
+func NewReleasingPublisher(limiter *Limiter, next events.Publisher) *ReleasingPublisher {
+	return &ReleasingPublisher{Publisher: next, limiter: limiter}
+}
+
+func (p *ReleasingPublisher) Publish(ctx context.Context, e events.Event) error {
+	if e.Payment.PhoneNumber != "" {
+		if err := p.limiter.Release(ctx, ScopePendingPhone, e.Payment.PhoneNumber, e.Payment.ID); err != nil {
+			// the slot expires on its own.
+			slog.Error("failed to release pending push", err, "payment_id", e.Payment.ID)
+		}
+	}
+	return p.Publisher.Publish(ctx, e)
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"paydex/events"
+	"paydex/store"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	l := New(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ok, _, err := l.Allow(ctx, "phone", "254712345678", 3, time.Minute); err != nil || !ok {
+			t.Fatalf("request %d: Allow() = %v, %v, want allowed", i, ok, err)
+		}
+	}
+	ok, retryAfter, err := l.Allow(ctx, "phone", "254712345678", 3, time.Minute)
+	if err != nil || ok {
+		t.Fatalf("Allow() = %v, %v, want the fourth request rejected", ok, err)
+	}
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Errorf("retry after %s, want within the window", retryAfter)
+	}
+	if ok, _, _ := l.Allow(ctx, "phone", "254700000000", 3, time.Minute); !ok {
+		t.Error("another phone shares the limit")
+	}
+
+	mr.FastForward(time.Minute)
+	if ok, _, _ := l.Allow(ctx, "phone", "254712345678", 3, time.Minute); !ok {
+		t.Error("the limit did not reset after the window")
+	}
+	for _, key := range mr.Keys() {
+		if key == keyPrefix+"phone:254712345678" {
+			t.Error("the phone number is stored in clear")
+		}
+	}
+}
+
+type recorder struct{ events []events.Event }
+
+func (r *recorder) Publish(_ context.Context, e events.Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestLimiter_Hold(t *testing.T) {
+	mr := miniredis.RunT(t)
+	l := New(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+
+	if held, _, err := l.Hold(ctx, ScopePendingPhone, "254712345678", "pay-1", 2*time.Minute); err != nil || !held {
+		t.Fatalf("Hold() = %v, %v, want the slot", held, err)
+	}
+	held, retryAfter, err := l.Hold(ctx, ScopePendingPhone, "254712345678", "pay-2", 2*time.Minute)
+	if err != nil || held || retryAfter <= 0 {
+		t.Fatalf("Hold() = %v, %s, %v, want the slot taken by pay-1", held, retryAfter, err)
+	}
+
+	// a late event of another payment does not free the slot.
+	next := &recorder{}
+	publisher := NewReleasingPublisher(l, next)
+	late := store.Payment{ID: "pay-0", PhoneNumber: "254712345678", Status: store.PaymentFailed}
+	if err := publisher.Publish(ctx, events.NewPaymentEvent(&late)); err != nil {
+		t.Fatal(err)
+	}
+	if held, _, _ := l.Hold(ctx, ScopePendingPhone, "254712345678", "pay-2", 2*time.Minute); held {
+		t.Error("the event of another payment released the slot")
+	}
+
+	done := store.Payment{ID: "pay-1", PhoneNumber: "254712345678", Status: store.PaymentCompleted}
+	if err := publisher.Publish(ctx, events.NewPaymentEvent(&done)); err != nil {
+		t.Fatal(err)
+	}
+	if held, _, _ := l.Hold(ctx, ScopePendingPhone, "254712345678", "pay-2", 2*time.Minute); !held {
+		t.Error("the slot was not released when the payment completed")
+	}
+	if len(next.events) != 2 {
+		t.Errorf("published %d events, want 2", len(next.events))
+	}
+}
+
+func TestBucket_Wait(t *testing.T) {
+	mr := miniredis.RunT(t)
+	b := NewBucket(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "mpesa", 20, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the burst of 2 is free, the next 2 tokens take 50ms each.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("4 requests took %s, want the rate enforced", elapsed)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(short); err != ErrThrottled {
+		t.Errorf("Wait() = %v, want ErrThrottled before the deadline", err)
+	}
+}
@@ -0,0 +1,156 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paydex/config"
+	"paydex/events"
+	"paydex/metrics"
+	"paydex/provider"
+	"paydex/store"
+
+	"golang.org/x/exp/slog"
+)
+
+type Policy struct {
+	// PendingAfter is how long a payment waits for its callback
+	// before the provider is queried.
+	PendingAfter time.Duration
+	// ExpireAfter is how long a payment may stay unresolved.
+	ExpireAfter time.Duration
+	// BatchSize limits the payments reconciled per run.
+	BatchSize int
+}
+
+// PolicyFromConfig reads the reconciliation thresholds in seconds.
+func PolicyFromConfig(c *config.Config) Policy {
+	policy := Policy{
+		PendingAfter: 2 * time.Minute,
+		ExpireAfter:  time.Hour,
+		BatchSize:    100,
+	}
+	if c.Reconciliation.PendingAfter > 0 {
+		policy.PendingAfter = time.Duration(c.Reconciliation.PendingAfter) * time.Second
+	}
+	if c.Reconciliation.ExpireAfter > 0 {
+		policy.ExpireAfter = time.Duration(c.Reconciliation.ExpireAfter) * time.Second
+	}
+	if c.Reconciliation.BatchSize > 0 {
+		policy.BatchSize = c.Reconciliation.BatchSize
+	}
+	return policy
+}
+
+// Report counts the payments resolved by a run.
+type Report struct {
+	Checked   int
+	Completed int
+	Failed    int
+	Expired   int
+}
+
+type Reconciler struct {
+	store     store.Store
+	providers *provider.Registry
+	publisher events.Publisher
+	policy    Policy
+}
+
+func New(s store.Store, providers *provider.Registry, publisher events.Publisher, policy Policy) *Reconciler {
+	return &Reconciler{
+		store:     s,
+		providers: providers,
+		publisher: publisher,
+		policy:    policy,
+	}
+}
+
+// Run resolves the payments that have been pending longer than PendingAfter.
+func (r *Reconciler) Run(ctx context.Context, now time.Time) (*Report, error) {
+	payments, err := r.store.ListPayments(ctx, store.PaymentFilter{
+		Status:        store.PaymentPending,
+		CreatedBefore: now.Add(-r.policy.PendingAfter),
+		Limit:         r.policy.BatchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	for _, payment := range payments {
+		report.Checked++
+		resolved, err := r.resolve(ctx, payment, now)
+		if err != nil {
+			// leave the payment pending, the next run will query it again.
+			slog.Error("failed to reconcile payment", err, "payment_id", payment.ID, "provider", payment.Provider)
+			continue
+		}
+		if !resolved {
+			continue
+		}
+		payment.UpdatedAt = now
+		// a callback received during the status query already resolved it.
+		if err := r.store.SwapPayment(ctx, payment, store.PaymentPending); errors.Is(err, store.ErrConflict) {
+			continue
+		} else if err != nil {
+			return report, err
+		}
+		metrics.StkPushResolved(payment.Provider, string(payment.Status), payment.ResultCode)
+		switch payment.Status {
+		case store.PaymentCompleted:
+			report.Completed++
+		case store.PaymentFailed:
+			report.Failed++
+		case store.PaymentExpired:
+			report.Expired++
+		}
+		if err := r.publisher.Publish(ctx, events.NewPaymentEvent(payment)); err != nil {
+			slog.Error("failed to publish payment event", err, "payment_id", payment.ID)
+		}
+	}
+	return report, nil
+}
+
+// resolve updates the payment status and reports whether it reached a final state.
+func (r *Reconciler) resolve(ctx context.Context, payment *store.Payment, now time.Time) (bool, error) {
+	expired := now.Sub(payment.CreatedAt) >= r.policy.ExpireAfter
+	// the push was never accepted by the provider so there is nothing to query.
+	if payment.TransactionID == "" {
+		if expired {
+			payment.Status = store.PaymentExpired
+			payment.ResultDesc = "payment was never sent to the provider"
+		}
+		return expired, nil
+	}
+
+	p, err := r.providers.Get(payment.Provider)
+	if err != nil {
+		return false, err
+	}
+	res, err := p.Status(ctx, provider.StatusRequest{TransactionID: payment.TransactionID})
+	if errors.Is(err, provider.ErrUnsupported) {
+		if expired {
+			payment.Status = store.PaymentExpired
+			payment.ResultDesc = "no callback received and the provider does not support status queries"
+		}
+		return expired, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	switch res.Status {
+	case provider.StatusCompleted:
+		payment.Status = store.PaymentCompleted
+	case provider.StatusFailed:
+		payment.Status = store.PaymentFailed
+	default:
+		if !expired {
+			return false, nil
+		}
+		payment.Status = store.PaymentExpired
+	}
+	payment.ResultCode = res.ResultCode
+	payment.ResultDesc = res.Message
+	return true, nil
+}
@@ -0,0 +1,138 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"paydex/events"
+	"paydex/metrics"
+	"paydex/provider"
+	"paydex/store"
+)
+
+type statusProvider struct {
+	provider.Provider
+	statuses map[string]provider.Status
+}
+
+func (p *statusProvider) Name() string { return "fake" }
+
+func (p *statusProvider) Status(_ context.Context, req provider.StatusRequest) (*provider.StatusResult, error) {
+	return &provider.StatusResult{TransactionID: req.TransactionID, Status: p.statuses[req.TransactionID]}, nil
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e events.Event) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+func TestReconciler_Run(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := store.NewMemoryStore()
+	payments := []*store.Payment{
+		{ID: "completed", TransactionID: "tx-1", CreatedAt: now.Add(-5 * time.Minute)},
+		{ID: "failed", TransactionID: "tx-2", CreatedAt: now.Add(-5 * time.Minute)},
+		{ID: "still-pending", TransactionID: "tx-3", CreatedAt: now.Add(-5 * time.Minute)},
+		{ID: "expired", TransactionID: "tx-3", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "never-sent", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "recent", TransactionID: "tx-1", CreatedAt: now},
+	}
+	for _, p := range payments {
+		p.Provider = "fake"
+		p.Status = store.PaymentPending
+		if err := s.CreatePayment(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry := provider.NewRegistry("fake", &statusProvider{statuses: map[string]provider.Status{
+		"tx-1": provider.StatusCompleted,
+		"tx-2": provider.StatusFailed,
+		"tx-3": provider.StatusPending,
+	}})
+	publisher := &recordingPublisher{}
+	r := New(s, registry, publisher, Policy{PendingAfter: 2 * time.Minute, ExpireAfter: time.Hour, BatchSize: 10})
+
+	report, err := r.Run(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 5 || report.Completed != 1 || report.Failed != 1 || report.Expired != 2 {
+		t.Errorf("Run() report = %+v", report)
+	}
+	if len(publisher.events) != 4 {
+		t.Errorf("published %d events, want 4", len(publisher.events))
+	}
+	if got := testutil.ToFloat64(metrics.StkPushResults.WithLabelValues("fake", "expired", "")); got != 2 {
+		t.Errorf("expired results = %v, want 2", got)
+	}
+
+	want := map[string]store.PaymentStatus{
+		"completed":     store.PaymentCompleted,
+		"failed":        store.PaymentFailed,
+		"still-pending": store.PaymentPending,
+		"expired":       store.PaymentExpired,
+		"never-sent":    store.PaymentExpired,
+		"recent":        store.PaymentPending,
+	}
+	for id, status := range want {
+		p, err := s.GetPayment(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Status != status {
+			t.Errorf("payment %s status = %v, want %v", id, p.Status, status)
+		}
+	}
+}
+
+// callbackProvider completes the payment while its status is queried, as a
+// callback received meanwhile would.
+type callbackProvider struct {
+	provider.Provider
+	store store.Store
+}
+
+func (p *callbackProvider) Name() string { return "fake" }
+
+func (p *callbackProvider) Status(ctx context.Context, req provider.StatusRequest) (*provider.StatusResult, error) {
+	payment, err := p.store.GetPayment(ctx, "p1")
+	if err != nil {
+		return nil, err
+	}
+	payment.Status = store.PaymentCompleted
+	if err := p.store.UpdatePayment(ctx, payment); err != nil {
+		return nil, err
+	}
+	return &provider.StatusResult{TransactionID: req.TransactionID, Status: provider.StatusPending}, nil
+}
+
+func TestReconciler_Run_ResolvedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := store.NewMemoryStore()
+	if err := s.CreatePayment(ctx, &store.Payment{ID: "p1", Provider: "fake", Status: store.PaymentPending, TransactionID: "tx-1", CreatedAt: now.Add(-2 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &recordingPublisher{}
+	r := New(s, provider.NewRegistry("fake", &callbackProvider{store: s}), publisher, Policy{PendingAfter: 2 * time.Minute, ExpireAfter: time.Hour, BatchSize: 10})
+
+	report, err := r.Run(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := s.GetPayment(ctx, "p1")
+	if p.Status != store.PaymentCompleted {
+		t.Errorf("status = %s, want the completed payment kept", p.Status)
+	}
+	if report.Expired != 0 || len(publisher.events) != 0 {
+		t.Errorf("report = %+v, events = %d, want the payment skipped", report, len(publisher.events))
+	}
+}
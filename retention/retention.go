@@ -0,0 +1,191 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"paydex/config"
+	"paydex/store"
+
+	"golang.org/x/exp/slog"
+)
+
+// Policy is how long each record type is kept, zero keeps records forever.
+type Policy struct {
+	// Callbacks is the retention of raw provider callbacks.
+	Callbacks time.Duration
+	// PII is how long phone numbers and names are kept on payments
+	// before they are anonymized, and how long the webhook deliveries,
+	// whose payloads carry them, are kept.
+	PII time.Duration
+	// Payments is how long completed, failed and expired payments are kept.
+	Payments time.Duration
+}
+
+// PolicyFromConfig reads the retention periods in days.
+func PolicyFromConfig(c *config.Config) Policy {
+	day := 24 * time.Hour
+	return Policy{
+		Callbacks: time.Duration(c.Retention.CallbackDays) * day,
+		PII:       time.Duration(c.Retention.PIIDays) * day,
+		Payments:  time.Duration(c.Retention.PaymentDays) * day,
+	}
+}
+
+// Report lists the records that were, or in a dry run would be, purged.
+type Report struct {
+	DryRun bool
+	RanAt  time.Time
+	// ids of the deleted callbacks.
+	Callbacks []string
+	// ids of the anonymized payments.
+	Anonymized []string
+	// ids of the deleted webhook deliveries.
+	Deliveries []string
+	// ids of the deleted payments.
+	Deleted []string
+}
+
+type Purger struct {
+	store  store.Store
+	policy Policy
+}
+
+func NewPurger(s store.Store, policy Policy) *Purger {
+	return &Purger{store: s, policy: policy}
+}
+
+// Run purges the records that expired at now,
+// in a dry run nothing is changed and the report lists what would be purged.
+func (p *Purger) Run(ctx context.Context, now time.Time, dryRun bool) (*Report, error) {
+	report := &Report{DryRun: dryRun, RanAt: now}
+
+	if p.policy.Callbacks > 0 {
+		callbacks, err := p.store.ListCallbacks(ctx, store.CallbackFilter{ReceivedBefore: now.Add(-p.policy.Callbacks)})
+		if err != nil {
+			return report, err
+		}
+		for _, c := range callbacks {
+			if !dryRun {
+				if err := p.store.DeleteCallback(ctx, c.ID); err != nil {
+					return report, err
+				}
+			}
+			report.Callbacks = append(report.Callbacks, c.ID)
+		}
+	}
+
+	// delete before anonymizing so deleted payments are not reported twice.
+	if p.policy.Payments > 0 {
+		for _, status := range []store.PaymentStatus{store.PaymentCompleted, store.PaymentFailed, store.PaymentExpired, store.PaymentReversing, store.PaymentReversed} {
+			payments, err := p.store.ListPayments(ctx, store.PaymentFilter{Status: status, CreatedBefore: now.Add(-p.policy.Payments)})
+			if err != nil {
+				return report, err
+			}
+			for _, payment := range payments {
+				if !dryRun {
+					if err := p.store.DeletePayment(ctx, payment.ID); err != nil {
+						return report, err
+					}
+				}
+				report.Deleted = append(report.Deleted, payment.ID)
+			}
+		}
+	}
+
+	if p.policy.PII > 0 {
+		payments, err := p.store.ListPayments(ctx, store.PaymentFilter{CreatedBefore: now.Add(-p.policy.PII)})
+		if err != nil {
+			return report, err
+		}
+		deleted := make(map[string]bool, len(report.Deleted))
+		for _, id := range report.Deleted {
+			deleted[id] = true
+		}
+		for _, payment := range payments {
+			if payment.Anonymized || deleted[payment.ID] {
+				continue
+			}
+			if !dryRun {
+				anonymized, err := p.anonymize(ctx, payment, now)
+				if err != nil {
+					return report, err
+				}
+				if !anonymized {
+					continue
+				}
+			}
+			report.Anonymized = append(report.Anonymized, payment.ID)
+		}
+
+		deliveries, err := p.store.ListDeliveries(ctx, store.DeliveryFilter{CreatedBefore: now.Add(-p.policy.PII)})
+		if err != nil {
+			return report, err
+		}
+		for _, d := range deliveries {
+			if !dryRun {
+				if err := p.store.DeleteDelivery(ctx, d.ID); err != nil {
+					return report, err
+				}
+			}
+			report.Deliveries = append(report.Deliveries, d.ID)
+		}
+	}
+	return report, nil
+}
+
+// anonymizeAttempts bounds the retries of an anonymization that raced
+// another write of the payment.
+const anonymizeAttempts = 3
+
+// anonymize writes the anonymized payment unless its status changed since
+// it was read, a callback or the reconciler resolving it meanwhile is not
+// overwritten, the payment is read again and retried.
+func (p *Purger) anonymize(ctx context.Context, payment *store.Payment, now time.Time) (bool, error) {
+	for i := 0; i < anonymizeAttempts; i++ {
+		if i > 0 {
+			var err error
+			payment, err = p.store.GetPayment(ctx, payment.ID)
+			if errors.Is(err, store.ErrNotFound) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			if payment.Anonymized {
+				return false, nil
+			}
+		}
+		from := payment.Status
+		Anonymize(payment)
+		payment.UpdatedAt = now
+		err := p.store.SwapPayment(ctx, payment, from)
+		if errors.Is(err, store.ErrConflict) {
+			continue
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	slog.Warn("payment kept changing, it was not anonymized", "payment_id", payment.ID)
+	return false, nil
+}
+
+// Anonymize removes the personal data from the payment,
+// the last digits of the phone number are kept for support queries.
+func Anonymize(p *store.Payment) {
+	p.PhoneNumber = MaskPhoneNumber(p.PhoneNumber)
+	p.Name = ""
+	p.Anonymized = true
+}
+
+func MaskPhoneNumber(phone string) string {
+	const visible = 3
+	if len(phone) <= visible {
+		return strings.Repeat("*", len(phone))
+	}
+	return strings.Repeat("*", len(phone)-visible) + phone[len(phone)-visible:]
+}
@@ -0,0 +1,116 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"paydex/store"
+)
+
+func TestPurger_Run(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	old := now.Add(-40 * 24 * time.Hour)
+	s := store.NewMemoryStore()
+	for _, p := range []*store.Payment{
+		{ID: "old-completed", Status: store.PaymentCompleted, PhoneNumber: "254712345678", CreatedAt: old},
+		{ID: "old-pending", Status: store.PaymentPending, PhoneNumber: "254712345678", Name: "Jane", CreatedAt: old},
+		{ID: "new-completed", Status: store.PaymentCompleted, PhoneNumber: "254712345678", CreatedAt: now},
+	} {
+		if err := s.CreatePayment(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SaveCallback(ctx, &store.Callback{ID: "old-callback", ReceivedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*store.WebhookDelivery{
+		{ID: "old-delivery", PaymentID: "old-pending", Payload: []byte(`{"phoneNumber":"254712345678"}`), CreatedAt: old},
+		{ID: "new-delivery", PaymentID: "new-completed", Payload: []byte(`{"phoneNumber":"254712345678"}`), CreatedAt: now},
+	} {
+		if err := s.CreateDelivery(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	day := 24 * time.Hour
+	purger := NewPurger(s, Policy{Callbacks: 7 * day, PII: 30 * day, Payments: 30 * day})
+
+	report, err := purger.Run(ctx, now, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Callbacks) != 1 || len(report.Deleted) != 1 || len(report.Anonymized) != 1 || len(report.Deliveries) != 1 {
+		t.Fatalf("dry run report = %+v", report)
+	}
+	if _, err := s.GetPayment(ctx, "old-completed"); err != nil {
+		t.Errorf("dry run deleted a payment: %v", err)
+	}
+
+	if _, err := purger.Run(ctx, now, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetPayment(ctx, "old-completed"); err != store.ErrNotFound {
+		t.Errorf("expired payment was not deleted: %v", err)
+	}
+	p, err := s.GetPayment(ctx, "old-pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Anonymized || p.PhoneNumber != "*********678" || p.Name != "" {
+		t.Errorf("payment was not anonymized: %+v", p)
+	}
+	if p, _ := s.GetPayment(ctx, "new-completed"); p.PhoneNumber != "254712345678" {
+		t.Errorf("recent payment was changed: %+v", p)
+	}
+	callbacks, _ := s.ListCallbacks(ctx, store.CallbackFilter{})
+	if len(callbacks) != 0 {
+		t.Errorf("expired callbacks were not deleted: %d", len(callbacks))
+	}
+	deliveries, _ := s.ListDeliveries(ctx, store.DeliveryFilter{})
+	if len(deliveries) != 1 || deliveries[0].ID != "new-delivery" {
+		t.Errorf("deliveries = %v, want only the recent one kept", deliveries)
+	}
+}
+
+// resolvingStore completes the payment between the purger reading and
+// writing it, as a callback would.
+type resolvingStore struct {
+	*store.MemoryStore
+	resolved bool
+}
+
+func (s *resolvingStore) SwapPayment(ctx context.Context, p *store.Payment, from store.PaymentStatus) error {
+	if !s.resolved {
+		s.resolved = true
+		stored, _ := s.GetPayment(ctx, p.ID)
+		stored.Status = store.PaymentCompleted
+		stored.ReceiptNumber = "NLJ7RT61SV"
+		if err := s.MemoryStore.UpdatePayment(ctx, stored); err != nil {
+			return err
+		}
+	}
+	return s.MemoryStore.SwapPayment(ctx, p, from)
+}
+
+func TestPurger_Run_ResolvedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := &resolvingStore{MemoryStore: store.NewMemoryStore()}
+	if err := s.CreatePayment(ctx, &store.Payment{ID: "p1", Status: store.PaymentPending, PhoneNumber: "254712345678", CreatedAt: now.Add(-40 * 24 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := NewPurger(s, Policy{PII: 30 * 24 * time.Hour}).Run(ctx, now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := s.GetPayment(ctx, "p1")
+	if p.Status != store.PaymentCompleted || p.ReceiptNumber != "NLJ7RT61SV" {
+		t.Errorf("payment = %s %q, want the callback outcome kept", p.Status, p.ReceiptNumber)
+	}
+	if !p.Anonymized || len(report.Anonymized) != 1 {
+		t.Errorf("payment anonymized = %v, report = %+v, want it anonymized on the retry", p.Anonymized, report)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"paydex/callback"
+	"paydex/mpesa"
+	pb "paydex/pkg/gen"
+	"paydex/store"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// callbackInbox archives the provider callbacks, the worker authenticates
+// them before they are applied to the payments.
+func (s *Server) callbackInbox() (*callback.Inbox, error) {
+	source, err := s.mpesaCallbackSource()
+	if err != nil {
+		return nil, err
+	}
+	return callback.NewInbox(s.store, s.worker, source), nil
+}
+
+func (s *Server) mpesaCallbackSource() (mpesa.SourceFilter, error) {
+	source := mpesa.SourceFilter{ForwardedFor: s.cfg.Mpesa.CallbackForwardedFor}
+	if len(s.cfg.Mpesa.CallbackIPs) == 0 {
+		return source, nil
+	}
+	var addresses []string
+	for _, a := range s.cfg.Mpesa.CallbackIPs {
+		if a == "safaricom" {
+			addresses = append(addresses, mpesa.SafaricomCallbackIPs...)
+			continue
+		}
+		addresses = append(addresses, a)
+	}
+	networks, err := mpesa.ParseNetworks(addresses)
+	if err != nil {
+		return source, err
+	}
+	source.Networks = networks
+	return source, nil
+}
+
+// ReplayCallback processes a stored callback again e.g after a parser fix,
+// the outcome is recorded on the returned callback.
+func (s *Server) ReplayCallback(ctx context.Context, in *pb.ReplayCallbackRequest) (*pb.Callback, error) {
+	processor := callback.NewProcessor(s.store, callback.NewMpesa(s.store, s.providers, s.publisher), callback.NewAirtel(s.store, s.providers, s.publisher))
+	c, err := processor.Process(ctx, in.CallbackId)
+	if c == nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "callback %s not found", in.CallbackId)
+		}
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to get callback")
+	}
+	if err != nil {
+		// rejected and failed callbacks are reported in the callback status.
+		log.Print(err)
+	}
+	return toProtoCallback(c), nil
+}
+
+func toProtoCallback(c *store.Callback) *pb.Callback {
+	return &pb.Callback{
+		Id:          c.ID,
+		Provider:    c.Provider,
+		Kind:        c.Kind,
+		Status:      string(c.Status),
+		DedupeKey:   c.DedupeKey,
+		DuplicateOf: c.DuplicateOf,
+		Attempts:    int32(c.Attempts),
+		Error:       c.Error,
+		Body:        c.Body,
+		ReceivedAt:  timestamppb.New(c.ReceivedAt),
+		ProcessedAt: timestamppb.New(c.ProcessedAt),
+	}
+}
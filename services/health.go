@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"paydex/health"
+	"paydex/lifecycle"
+	pb "paydex/pkg/gen"
+	"paydex/provider"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// healthInterval is how often the grpc serving status is refreshed.
+const healthInterval = 10 * time.Second
+
+// readinessChecks are the dependencies an instance needs to serve payments.
+// The providers are only reported, an outage is handled by their circuit
+// breakers and must not take the callback endpoints out of rotation.
+func (s *Server) readinessChecks() *health.Checker {
+	checks := health.NewChecker(5 * time.Second)
+	inspector := asynq.NewInspector(s.redisOpt)
+	checks.Add("redis", func(ctx context.Context) error {
+		_, err := inspector.Queues()
+		return err
+	})
+	checks.Add("store", s.store.Ping)
+	for _, name := range s.providers.Names() {
+		p, err := s.providers.Get(name)
+		if err != nil {
+			continue
+		}
+		if _, ok := p.(provider.Pinger); !ok {
+			continue
+		}
+		name := name
+		// e.g mpesa checks that a daraja access token can be obtained.
+		// the provider is looked up each time as a config reload replaces it.
+		checks.AddOptional(name, func(ctx context.Context) error {
+			p, err := s.providers.Get(name)
+			if err != nil {
+				return err
+			}
+			pinger, ok := p.(provider.Pinger)
+			if !ok {
+				return nil
+			}
+			return pinger.Ping(ctx)
+		})
+	}
+	return checks
+}
+
+// HealthWatcher keeps the grpc health status in line with the readiness
+// checks, stopping it reports the services as not serving.
+func (s *Server) HealthWatcher() lifecycle.Component {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	return lifecycle.Component{
+		Name: "health watcher",
+		Start: func() error {
+			defer close(stopped)
+			health.Watch(ctx, s.checks, s.health, healthInterval, "", pb.PaydexService_ServiceDesc.ServiceName)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			cancel()
+			<-stopped
+			// tell the load balancers before the grpc server drains.
+			s.health.Shutdown()
+			return nil
+		},
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"paydex/auth"
+	"paydex/config"
+	"paydex/ratelimit"
+	"paydex/worker"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// rateLimitWindow is the window of the per minute limits.
+	rateLimitWindow = time.Minute
+	// defaultPendingTimeout is how long a push blocks the next one to the
+	// phone when its outcome never arrives.
+	defaultPendingTimeout = 120 * time.Second
+)
+
+// newLimiter counts the pushes in the redis of the queues.
+func newLimiter(redisOpt asynq.RedisClientOpt) *ratelimit.Limiter {
+	return ratelimit.New(worker.NewRedisClient(redisOpt))
+}
+
+// setTrustedProxies parses the trusted proxies of the config, the limits
+// read them on every push. config.Validate rejects the invalid ones, an
+// error here keeps the proxies trusted before.
+func (s *Server) setTrustedProxies(c *config.Config) {
+	networks, err := ratelimit.ParseNetworks(c.RateLimits.TrustedProxies)
+	if err != nil {
+		slog.Error("invalid trusted proxies", err)
+		if s.trustedProxies.Load() != nil {
+			return
+		}
+	}
+	s.trustedProxies.Store(&networks)
+}
+
+// checkLimits rejects the push when the caller, the merchant or the phone
+// made too many pushes or the phone has a push awaiting its outcome.
+// redis errors are logged and the push allowed, the limits protect the
+// customers but must not stop the payments.
+func (s *Server) checkLimits(ctx context.Context, merchantID, phoneNumber, paymentID string) error {
+	if s.limiter == nil {
+		return nil
+	}
+	limits := s.conf().RateLimits
+	checks := []struct {
+		scope string
+		key   string
+		limit int
+	}{
+		{"api_key", callerKey(ctx, *s.trustedProxies.Load()), limits.PerAPIKey},
+		{"merchant", merchantID, limits.PerMerchant},
+		{"phone", phoneNumber, limits.PerPhone},
+	}
+	for _, c := range checks {
+		if c.limit <= 0 || c.key == "" {
+			continue
+		}
+		ok, retryAfter, err := s.limiter.Allow(ctx, c.scope, c.key, c.limit, rateLimitWindow)
+		if err != nil {
+			slog.Error("rate limit check failed", err, "scope", c.scope)
+			continue
+		}
+		if !ok {
+			return resourceExhausted(c.scope, fmt.Sprintf("more than %d stk pushes per minute", c.limit), retryAfter)
+		}
+	}
+
+	if !limits.OnePendingPerPhone {
+		return nil
+	}
+	timeout := defaultPendingTimeout
+	if limits.PendingTimeout > 0 {
+		timeout = time.Duration(limits.PendingTimeout) * time.Second
+	}
+	held, retryAfter, err := s.limiter.Hold(ctx, ratelimit.ScopePendingPhone, phoneNumber, paymentID, timeout)
+	if err != nil {
+		slog.Error("pending push check failed", err)
+		return nil
+	}
+	if !held {
+		return resourceExhausted(ratelimit.ScopePendingPhone, "the phone has an stk push awaiting its outcome", retryAfter)
+	}
+	return nil
+}
+
+// releasePending frees the phone when the push was not sent.
+func (s *Server) releasePending(ctx context.Context, phoneNumber, paymentID string) {
+	if s.limiter == nil || !s.conf().RateLimits.OnePendingPerPhone {
+		return
+	}
+	if err := s.limiter.Release(ctx, ratelimit.ScopePendingPhone, phoneNumber, paymentID); err != nil {
+		slog.Error("failed to release pending push", err, "payment_id", paymentID)
+	}
+}
+
+// callerKey identifies the caller by the name of its authenticated api
+// key, or by its address when it sent none.
+func callerKey(ctx context.Context, trustedProxies ratelimit.Networks) string {
+	if name, ok := auth.Caller(ctx); ok {
+		return "key:" + name
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	addr := hostIP(p.Addr.String())
+	trusted := func(ip net.IP) bool {
+		return ip.IsLoopback() || trustedProxies.Contains(ip)
+	}
+	if addr == nil {
+		return "ip:" + p.Addr.String()
+	}
+	if !trusted(addr) {
+		return "ip:" + addr.String()
+	}
+	// the gateway appends the address of its client to x-forwarded-for,
+	// the hops are read from the last one and the clients can only forge
+	// the ones before the trusted proxies.
+	var hops []string
+	for _, v := range metadata.ValueFromIncomingContext(ctx, "x-forwarded-for") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		addr = hop
+		if !trusted(hop) {
+			break
+		}
+	}
+	return "ip:" + addr.String()
+}
+
+// hostIP is the ip of a host:port address.
+func hostIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// resourceExhausted tells the client which limit was hit and when to retry.
+func resourceExhausted(subject, description string, retryAfter time.Duration) error {
+	// whole seconds as in the Retry-After header, rounded up.
+	retryAfter = time.Duration(math.Max(1, math.Ceil(retryAfter.Seconds()))) * time.Second
+	st := status.New(codes.ResourceExhausted, description)
+	detailed, err := st.WithDetails(
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: subject, Description: description}}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// gatewayErrorHandler sets the Retry-After header of the errors with retry info.
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if st, ok := status.FromError(err); ok {
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.RetryInfo); ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(info.RetryDelay.AsDuration().Seconds()))))
+			}
+		}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"net"
+	"paydex/auth"
+	"paydex/config"
+	"paydex/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func limitedServer(t *testing.T, c *config.Config) *Server {
+	mr := miniredis.RunT(t)
+	s := &Server{limiter: ratelimit.New(redis.NewClient(&redis.Options{Addr: mr.Addr()}))}
+	s.current.Store(c)
+	s.setTrustedProxies(c)
+	return s
+}
+
+func TestCheckLimits_PerPhone(t *testing.T) {
+	c := &config.Config{}
+	c.RateLimits.PerPhone = 1
+	s := limitedServer(t, c)
+	ctx := context.Background()
+
+	if err := s.checkLimits(ctx, "merchant-1", "254712345678", "pay-1"); err != nil {
+		t.Fatalf("checkLimits() error = %v, want the first push allowed", err)
+	}
+	err := s.checkLimits(ctx, "merchant-1", "254712345678", "pay-2")
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("checkLimits() error = %v, want ResourceExhausted", err)
+	}
+	var retry *errdetails.RetryInfo
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			retry = info
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() < time.Second || retry.RetryDelay.AsDuration() > time.Minute {
+		t.Errorf("retry info = %v, want a delay within the minute", retry)
+	}
+	if err := s.checkLimits(ctx, "merchant-1", "254700000000", "pay-3"); err != nil {
+		t.Errorf("checkLimits() error = %v, want another phone allowed", err)
+	}
+}
+
+func TestCheckLimits_OnePendingPerPhone(t *testing.T) {
+	c := &config.Config{}
+	c.RateLimits.OnePendingPerPhone = true
+	s := limitedServer(t, c)
+	ctx := context.Background()
+
+	if err := s.checkLimits(ctx, "merchant-1", "254712345678", "pay-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.checkLimits(ctx, "merchant-1", "254712345678", "pay-2"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("checkLimits() error = %v, want the second push rejected", err)
+	}
+	s.releasePending(ctx, "254712345678", "pay-1")
+	if err := s.checkLimits(ctx, "merchant-1", "254712345678", "pay-2"); err != nil {
+		t.Errorf("checkLimits() error = %v, want the push allowed once released", err)
+	}
+}
+
+func TestCallerKey(t *testing.T) {
+	gateway := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 50000}
+	client := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 50000}
+	tests := []struct {
+		name    string
+		caller  string
+		addr    net.Addr
+		md      metadata.MD
+		proxies []string
+		want    string
+	}{
+		{name: "api key", caller: "backoffice", addr: client, md: metadata.Pairs("authorization", "Bearer secret"), want: "key:backoffice"},
+		{name: "unauthenticated key", addr: client, md: metadata.Pairs("authorization", "Bearer random"), want: "ip:203.0.113.7"},
+		{name: "gateway", addr: gateway, md: metadata.Pairs("x-forwarded-for", "10.0.0.1, 10.0.0.2"), want: "ip:10.0.0.2"},
+		{name: "trusted proxy", addr: gateway, md: metadata.Pairs("x-forwarded-for", "10.0.0.1, 10.0.0.2"), proxies: []string{"10.0.0.0/24"}, want: "ip:10.0.0.1"},
+		{name: "forged", addr: client, md: metadata.Pairs("x-forwarded-for", "10.0.0.1"), want: "ip:203.0.113.7"},
+		{name: "none", md: metadata.MD{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			if tt.addr != nil {
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: tt.addr})
+			}
+			if tt.caller != "" {
+				ctx = auth.WithCaller(ctx, tt.caller)
+			}
+			proxies, err := ratelimit.ParseNetworks(tt.proxies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := callerKey(ctx, proxies); got != tt.want {
+				t.Errorf("callerKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,22 +3,130 @@ package services
 import (
 	"context"
 	"log"
+	"paydex/airtel"
+	"paydex/currency"
+	"paydex/money"
+	"paydex/mpesa"
+	"paydex/phone"
 	pb "paydex/pkg/gen"
+	"paydex/store"
+	"paydex/tracing"
 	"paydex/worker"
+	"time"
 
-	"google.golang.org/protobuf/types/known/emptypb"
+	"github.com/google/uuid"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func (s *Server) InitStkPush(ctx context.Context, in *pb.StkPushRequest) (*emptypb.Empty, error) {
-	s.l.Info("InitSktPush", in)
-	if err := s.worker.DistributeTaskSendSTKPush(ctx, &worker.STKRequest{
-		Amount:      in.Amount,
+// providerOperators are the networks whose subscribers a provider collects
+// from, the other providers accept any number.
+var providerOperators = map[string]phone.Operator{
+	mpesa.ProviderName:  phone.Safaricom,
+	airtel.ProviderName: phone.Airtel,
+}
+
+func (s *Server) InitStkPush(ctx context.Context, in *pb.StkPushRequest) (*pb.StkPushResponse, error) {
+	p, err := s.providers.Get(in.Provider)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	amount, err := fromProtoMoney(in.Amount)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	number, err := phone.Parse(in.PhoneNumber, phone.Kenya)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if op, ok := providerOperators[p.Name()]; ok && number.Operator != op {
+		return nil, status.Errorf(codes.InvalidArgument, "%s cannot collect from a %s number", p.Name(), number.Operator)
+	}
+	phoneNumber := number.E164()
+	if _, ok := s.conf().Merchants[in.MerchantId]; in.MerchantId != "" && !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown merchant %q", in.MerchantId)
+	}
+	payload := &worker.STKRequest{
+		PaymentID:   uuid.NewString(),
+		Provider:    p.Name(),
+		Amount:      amount,
 		Description: in.TransactionDesc,
-		PhoneNumber: in.PhoneNumber,
+		PhoneNumber: phoneNumber,
+	}
+	if amount.Currency == currency.KES {
+		// mpesa only accepts whole shillings, the customer is not charged
+		// another amount than requested.
+		if _, err := amount.Whole(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	} else {
+		conversion, err := s.converter.Convert(ctx, amount, currency.KES)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		// mpesa only accepts whole shillings.
+		payload.Amount = conversion.Converted.RoundWhole()
+		payload.OriginalAmount = conversion.Amount
+		payload.ExchangeRate = conversion.Rate
+		payload.RateTimestamp = conversion.Timestamp
+	}
+
+	now := time.Now()
+	if err := s.checkLimits(ctx, in.MerchantId, payload.PhoneNumber, payload.PaymentID); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreatePayment(ctx, &store.Payment{
+		ID:             payload.PaymentID,
+		MerchantID:     in.MerchantId,
+		Provider:       payload.Provider,
+		Status:         store.PaymentPending,
+		Amount:         payload.Amount,
+		PhoneNumber:    payload.PhoneNumber,
+		Description:    payload.Description,
+		OriginalAmount: payload.OriginalAmount,
+		ExchangeRate:   payload.ExchangeRate,
+		RateTimestamp:  payload.RateTimestamp,
+		Trace:          tracing.Inject(ctx),
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}); err != nil {
 		log.Print(err)
-		return &emptypb.Empty{}, err
+		s.releasePending(ctx, payload.PhoneNumber, payload.PaymentID)
+		return nil, status.Error(codes.Internal, "unable to save payment")
+	}
+	if err := s.worker.DistributeTaskSendSTKPush(ctx, payload); err != nil {
+		log.Print(err)
+		s.releasePending(ctx, payload.PhoneNumber, payload.PaymentID)
+		return nil, err
+	}
+	s.l.Info("InitStkPush", "payment_id", payload.PaymentID, "provider", payload.Provider)
+	return &pb.StkPushResponse{PaymentId: payload.PaymentID, Provider: payload.Provider}, nil
+}
+
+func (s *Server) ConvertAmount(ctx context.Context, in *pb.ConvertAmountRequest) (*pb.ConvertAmountResponse, error) {
+	amount, err := fromProtoMoney(in.Amount)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	conversion, err := s.converter.Convert(ctx, amount, in.To)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	return &pb.ConvertAmountResponse{
+		Amount:          toProtoMoney(conversion.Amount),
+		ConvertedAmount: toProtoMoney(conversion.Converted),
+		Rate:            conversion.Rate,
+		RateTimestamp:   timestamppb.New(conversion.Timestamp),
+		Source:          conversion.Source,
+	}, nil
+}
+
+func fromProtoMoney(m *pb.Money) (money.Money, error) {
+	return money.New(m.GetMinorUnits(), m.GetCurrency())
+}
 
-	return &emptypb.Empty{}, nil
+func toProtoMoney(m money.Money) *pb.Money {
+	return &pb.Money{MinorUnits: m.Minor, Currency: m.Currency}
 }
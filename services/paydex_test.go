@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"paydex/config"
+	pb "paydex/pkg/gen"
+	"paydex/provider"
+	"paydex/store"
+	"testing"
+
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInitStkPush_FractionalShillings(t *testing.T) {
+	c := &config.Config{}
+	s := &Server{cfg: c, store: store.NewMemoryStore(), providers: provider.NewRegistry("mpesa", &refundProvider{}), l: slog.Default()}
+	s.current.Store(c)
+	_, err := s.InitStkPush(context.Background(), &pb.StkPushRequest{
+		PhoneNumber: "0712345678",
+		Amount:      &pb.Money{MinorUnits: 1050, Currency: "KES"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("InitStkPush() error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestInitStkPush_OperatorMismatch(t *testing.T) {
+	c := &config.Config{}
+	s := &Server{cfg: c, store: store.NewMemoryStore(), providers: provider.NewRegistry("mpesa", &refundProvider{}), l: slog.Default()}
+	s.current.Store(c)
+	// 0733 is an airtel number.
+	_, err := s.InitStkPush(context.Background(), &pb.StkPushRequest{
+		PhoneNumber: "0733123456",
+		Amount:      &pb.Money{MinorUnits: 1000, Currency: "KES"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("InitStkPush() error = %v, want InvalidArgument", err)
+	}
+	payments, _ := s.store.ListPayments(context.Background(), store.PaymentFilter{})
+	if len(payments) != 0 {
+		t.Errorf("stored %d payments, want none", len(payments))
+	}
+}
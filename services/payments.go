@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"paydex/callback"
+	"paydex/phone"
+	pb "paydex/pkg/gen"
+	"paydex/provider"
+	"paydex/store"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) GetPayment(ctx context.Context, in *pb.GetPaymentRequest) (*pb.Payment, error) {
+	p, err := s.store.GetPayment(ctx, in.PaymentId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "payment %s not found", in.PaymentId)
+	}
+	if err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to get payment")
+	}
+	return toProtoPayment(p), nil
+}
+
+func (s *Server) ListPayments(ctx context.Context, in *pb.ListPaymentsRequest) (*pb.ListPaymentsResponse, error) {
+	filter := store.PaymentFilter{Status: store.PaymentStatus(in.Status), Limit: int(in.Limit)}
+	if in.CreatedAfter != nil {
+		filter.CreatedAfter = in.CreatedAfter.AsTime()
+	}
+	if in.CreatedBefore != nil {
+		filter.CreatedBefore = in.CreatedBefore.AsTime()
+	}
+	payments, err := s.store.ListPayments(ctx, filter)
+	if err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to list payments")
+	}
+	res := &pb.ListPaymentsResponse{Payments: make([]*pb.Payment, 0, len(payments))}
+	for _, p := range payments {
+		res.Payments = append(res.Payments, toProtoPayment(p))
+	}
+	return res, nil
+}
+
+func (s *Server) Payout(ctx context.Context, in *pb.PayoutRequest) (*pb.ProviderResult, error) {
+	p, err := s.providers.Get(in.Provider)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	amount, err := fromProtoMoney(in.Amount)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	phoneNumber, err := phone.Normalize(in.PhoneNumber, phone.Kenya)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resultURL, err := callback.ResultURL(s.conf().Mpesa.CallbackURL, "")
+	if err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "invalid result callback url")
+	}
+	res, err := p.Payout(ctx, provider.PayoutRequest{
+		Amount:      amount,
+		PhoneNumber: phoneNumber,
+		Reference:   s.conf().Mpesa.BusinessName,
+		Description: in.Description,
+		CallbackURL: resultURL,
+	})
+	if err != nil {
+		return nil, providerError(p.Name(), "payout", err)
+	}
+	s.l.Info("payout requested", "provider", p.Name(), "transaction_id", res.TransactionID, "status", res.Status)
+	return &pb.ProviderResult{
+		Provider:      p.Name(),
+		TransactionId: res.TransactionID,
+		Status:        string(res.Status),
+		Message:       res.Message,
+	}, nil
+}
+
+func (s *Server) GetBalance(ctx context.Context, in *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	p, err := s.providers.Get(in.Provider)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res, err := p.Balance(ctx, provider.BalanceRequest{AccountID: in.AccountId})
+	if err != nil {
+		return nil, providerError(p.Name(), "balance enquiry", err)
+	}
+	balances := make([]*pb.Balance, 0, len(res.Balances))
+	for _, b := range res.Balances {
+		balances = append(balances, &pb.Balance{Type: b.Type, Amount: toProtoMoney(b.Amount)})
+	}
+	return &pb.GetBalanceResponse{Balances: balances}, nil
+}
+
+// ReversePayment returns the money of a completed payment to the customer.
+func (s *Server) ReversePayment(ctx context.Context, in *pb.ReversePaymentRequest) (*pb.ProviderResult, error) {
+	payment, err := s.store.GetPayment(ctx, in.PaymentId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "payment %s not found", in.PaymentId)
+	}
+	if err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to get payment")
+	}
+	if payment.Status != store.PaymentCompleted {
+		return nil, status.Errorf(codes.FailedPrecondition, "payment %s is %s, only completed payments can be reversed", payment.ID, payment.Status)
+	}
+	p, err := s.providers.Get(payment.Provider)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	// the payment is marked before the refund so a concurrent request
+	// cannot reverse it twice.
+	reversing := *payment
+	reversing.Status = store.PaymentReversing
+	reversing.UpdatedAt = time.Now()
+	if err := s.store.SwapPayment(ctx, &reversing, store.PaymentCompleted); errors.Is(err, store.ErrConflict) {
+		return nil, status.Errorf(codes.FailedPrecondition, "payment %s is already being reversed", payment.ID)
+	} else if err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to update payment")
+	}
+	// mpesa reverses by the receipt, the other providers by their transaction id.
+	transactionID := payment.ReceiptNumber
+	if transactionID == "" {
+		transactionID = payment.TransactionID
+	}
+	resultURL, err := callback.ResultURL(s.conf().Mpesa.CallbackURL, payment.ID)
+	if err != nil {
+		log.Print(err)
+		s.cancelReversal(ctx, &reversing)
+		return nil, status.Error(codes.Internal, "invalid result callback url")
+	}
+	res, err := p.Refund(ctx, provider.RefundRequest{
+		TransactionID: transactionID,
+		Amount:        payment.Amount,
+		Reason:        in.Reason,
+		CallbackURL:   resultURL,
+	})
+	if err != nil {
+		s.cancelReversal(ctx, &reversing)
+		return nil, providerError(p.Name(), "reversal", err)
+	}
+	switch res.Status {
+	case provider.StatusFailed:
+		s.cancelReversal(ctx, &reversing)
+	case provider.StatusCompleted:
+		reversed := reversing
+		reversed.Status = store.PaymentReversed
+		reversed.ReversalID = res.TransactionID
+		reversed.UpdatedAt = time.Now()
+		if err := s.store.SwapPayment(ctx, &reversed, store.PaymentReversing); err != nil {
+			s.l.Error("failed to record the reversal", err, "payment_id", payment.ID)
+		}
+	default:
+		// the result callback finds the payment by the reversal id.
+		reversing.ReversalID = res.TransactionID
+		reversing.UpdatedAt = time.Now()
+		if err := s.store.SwapPayment(ctx, &reversing, store.PaymentReversing); err != nil {
+			s.l.Error("failed to record the reversal", err, "payment_id", payment.ID)
+		}
+	}
+	s.l.Info("reversal requested", "payment_id", payment.ID, "provider", p.Name(), "transaction_id", res.TransactionID, "status", res.Status)
+	return &pb.ProviderResult{
+		Provider:      p.Name(),
+		TransactionId: res.TransactionID,
+		Status:        string(res.Status),
+		Message:       res.Message,
+	}, nil
+}
+
+// cancelReversal returns the payment to completed when the provider did not
+// accept the reversal, so it can be requested again.
+func (s *Server) cancelReversal(ctx context.Context, payment *store.Payment) {
+	completed := *payment
+	completed.Status = store.PaymentCompleted
+	completed.UpdatedAt = time.Now()
+	if err := s.store.SwapPayment(ctx, &completed, store.PaymentReversing); err != nil {
+		s.l.Error("failed to cancel the reversal", err, "payment_id", payment.ID)
+	}
+}
+
+// providerError hides the provider error from the client, except when the
+// provider does not offer the capability.
+func providerError(name, request string, err error) error {
+	if errors.Is(err, provider.ErrUnsupported) {
+		return status.Errorf(codes.Unimplemented, "%s does not support %s", name, request)
+	}
+	log.Print(err)
+	return status.Errorf(codes.Unavailable, "%s %s failed", name, request)
+}
+
+func toProtoPayment(p *store.Payment) *pb.Payment {
+	payment := &pb.Payment{
+		Id:            p.ID,
+		MerchantId:    p.MerchantID,
+		Provider:      p.Provider,
+		Status:        string(p.Status),
+		Amount:        toProtoMoney(p.Amount),
+		PhoneNumber:   p.PhoneNumber,
+		Description:   p.Description,
+		TransactionId: p.TransactionID,
+		ResultCode:    p.ResultCode,
+		ResultDesc:    p.ResultDesc,
+		ReceiptNumber: p.ReceiptNumber,
+		ExchangeRate:  p.ExchangeRate,
+		CreatedAt:     timestamppb.New(p.CreatedAt),
+		UpdatedAt:     timestamppb.New(p.UpdatedAt),
+	}
+	if p.OriginalAmount.Currency != "" {
+		payment.OriginalAmount = toProtoMoney(p.OriginalAmount)
+	}
+	return payment
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"paydex/config"
+	"paydex/money"
+	pb "paydex/pkg/gen"
+	"paydex/provider"
+	"paydex/store"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReversePayment_NotCompleted(t *testing.T) {
+	s := &Server{store: store.NewMemoryStore(), providers: provider.NewRegistry("mpesa")}
+	ctx := context.Background()
+	if err := s.store.CreatePayment(ctx, &store.Payment{
+		ID:        "6f1c7a0e-8a7b-4c1d-9a3e-0b8d2f4c5e6a",
+		Provider:  "mpesa",
+		Status:    store.PaymentPending,
+		Amount:    money.Money{Minor: 1000, Currency: "KES"},
+		CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.ReversePayment(ctx, &pb.ReversePaymentRequest{PaymentId: "6f1c7a0e-8a7b-4c1d-9a3e-0b8d2f4c5e6a", Reason: "duplicate"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ReversePayment() error = %v, want FailedPrecondition", err)
+	}
+	_, err = s.ReversePayment(ctx, &pb.ReversePaymentRequest{PaymentId: "0b8d2f4c-5e6a-4c1d-9a3e-6f1c7a0e8a7b", Reason: "duplicate"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("ReversePayment() error = %v, want NotFound", err)
+	}
+}
+
+type refundProvider struct {
+	provider.Provider
+	refunds int
+	status  provider.Status
+	err     error
+}
+
+func (p *refundProvider) Name() string { return "mpesa" }
+
+func (p *refundProvider) Refund(context.Context, provider.RefundRequest) (*provider.RefundResult, error) {
+	p.refunds++
+	if p.err != nil {
+		return nil, p.err
+	}
+	status := p.status
+	if status == "" {
+		status = provider.StatusPending
+	}
+	return &provider.RefundResult{TransactionID: "AG_1", Status: status}, nil
+}
+
+func TestReversePayment_Once(t *testing.T) {
+	p := &refundProvider{err: errors.New("daraja unavailable")}
+	s := &Server{store: store.NewMemoryStore(), providers: provider.NewRegistry("mpesa", p), l: slog.Default()}
+	s.current.Store(&config.Config{})
+	ctx := context.Background()
+	id := "6f1c7a0e-8a7b-4c1d-9a3e-0b8d2f4c5e6a"
+	if err := s.store.CreatePayment(ctx, &store.Payment{
+		ID:            id,
+		Provider:      "mpesa",
+		Status:        store.PaymentCompleted,
+		Amount:        money.Money{Minor: 1000, Currency: "KES"},
+		ReceiptNumber: "RKTQDM7W6S",
+		CreatedAt:     time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	req := &pb.ReversePaymentRequest{PaymentId: id, Reason: "duplicate"}
+
+	// a failed reversal can be requested again.
+	if _, err := s.ReversePayment(ctx, req); err == nil {
+		t.Fatal("ReversePayment() succeeded while the provider failed")
+	}
+	p.err = nil
+	if _, err := s.ReversePayment(ctx, req); err != nil {
+		t.Fatalf("ReversePayment() error = %v", err)
+	}
+	payment, _ := s.store.GetPayment(ctx, id)
+	if payment.Status != store.PaymentReversing {
+		t.Errorf("payment status = %s, want %s", payment.Status, store.PaymentReversing)
+	}
+	if _, err := s.ReversePayment(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ReversePayment() error = %v, want FailedPrecondition", err)
+	}
+	if p.refunds != 2 {
+		t.Errorf("provider got %d refunds, want 2", p.refunds)
+	}
+}
+
+func TestReversePayment_Rejected(t *testing.T) {
+	p := &refundProvider{status: provider.StatusFailed}
+	s := &Server{store: store.NewMemoryStore(), providers: provider.NewRegistry("mpesa", p), l: slog.Default()}
+	s.current.Store(&config.Config{})
+	ctx := context.Background()
+	id := "6f1c7a0e-8a7b-4c1d-9a3e-0b8d2f4c5e6a"
+	if err := s.store.CreatePayment(ctx, &store.Payment{
+		ID:            id,
+		Provider:      "mpesa",
+		Status:        store.PaymentCompleted,
+		Amount:        money.Money{Minor: 1000, Currency: "KES"},
+		ReceiptNumber: "RKTQDM7W6S",
+		CreatedAt:     time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// daraja acknowledged the reversal with a failure.
+	if _, err := s.ReversePayment(ctx, &pb.ReversePaymentRequest{PaymentId: id, Reason: "duplicate"}); err != nil {
+		t.Fatalf("ReversePayment() error = %v", err)
+	}
+	payment, _ := s.store.GetPayment(ctx, id)
+	if payment.Status != store.PaymentCompleted {
+		t.Errorf("payment status = %s, want %s", payment.Status, store.PaymentCompleted)
+	}
+
+	p.status = provider.StatusPending
+	if _, err := s.ReversePayment(ctx, &pb.ReversePaymentRequest{PaymentId: id, Reason: "duplicate"}); err != nil {
+		t.Fatalf("ReversePayment() error = %v, want the reversal requested again", err)
+	}
+	payment, _ = s.store.GetPayment(ctx, id)
+	if payment.Status != store.PaymentReversing || payment.ReversalID != "AG_1" {
+		t.Errorf("payment status = %s, reversal id = %q, want reversing AG_1", payment.Status, payment.ReversalID)
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"paydex/config"
+	"paydex/lifecycle"
+	"paydex/worker"
+	"time"
+)
+
+// configCheckInterval is how often the config file is checked for changes.
+const configCheckInterval = 5 * time.Second
+
+// Reload applies a reloaded config to the rate limits, the providers and
+// the task processor.
+// The settings read at startup e.g the listen addresses keep their value,
+// see config.RestartRequired.
+func (s *Server) Reload(old, c *config.Config) {
+	s.current.Store(c)
+	s.setTrustedProxies(c)
+	worker.ReloadProviders(s.providers, s.guard, old, c)
+	if s.processor != nil {
+		s.processor.Reload(c)
+	}
+}
+
+// ConfigWatcher reloads the config on SIGHUP and when its file changes.
+func (s *Server) ConfigWatcher(r *config.Reloader) lifecycle.Component {
+	r.OnReload(s.Reload)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	return lifecycle.Component{
+		Name: "config watcher",
+		Start: func() error {
+			defer close(stopped)
+			r.Watch(ctx, configCheckInterval)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			cancel()
+			<-stopped
+			return nil
+		},
+	}
+}
+
+// conf is the current config, e.g the callback urls of provider requests.
+func (s *Server) conf() *config.Config {
+	return s.current.Load()
+}
@@ -1,96 +1,283 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"io/fs"
 	"log"
 	"net"
 	"net/http"
+	"net/http/pprof"
 	"paydex/assets"
+	"paydex/auth"
+	"paydex/callback"
 	"paydex/config"
+	"paydex/currency"
+	"paydex/events"
+	"paydex/health"
+	"paydex/lifecycle"
+	"paydex/metrics"
 	pb "paydex/pkg/gen"
+	"paydex/pkg/validator"
+	"paydex/provider"
+	"paydex/ratelimit"
+	"paydex/store"
 	"paydex/worker"
+	"sync/atomic"
 	"time"
 
-	_ "expvar"         // Register the expvar handlers
-	_ "net/http/pprof" // Register the pprof handlers
-
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 type Server struct {
 	pb.UnimplementedPaydexServiceServer
-	worker   worker.TaskDistributor
-	cfg      *config.Config
-	redisOpt asynq.RedisClientOpt
-	l        *slog.Logger
+	worker    worker.TaskDistributor
+	providers *provider.Registry
+	converter *currency.Converter
+	store     store.Store
+	publisher events.Publisher
+	cfg       *config.Config
+	redisOpt  asynq.RedisClientOpt
+	l         *slog.Logger
+	health    *grpchealth.Server
+	checks    *health.Checker
+	// processor is set by TaskProcessor, it receives the reloaded config.
+	processor worker.TaskProcessor
+	// current is the reloaded config, cfg keeps the one the servers started with.
+	current atomic.Pointer[config.Config]
+	limiter *ratelimit.Limiter
+	// trustedProxies are parsed from the current config.
+	trustedProxies atomic.Pointer[ratelimit.Networks]
+	// guard throttles the mpesa requests and pauses the mpesa queue while daraja is down.
+	guard *worker.MpesaGuard
 }
 
 func NewServer(
 	worker worker.TaskDistributor,
+	providers *provider.Registry,
+	guard *worker.MpesaGuard,
+	converter *currency.Converter,
+	s store.Store,
+	publisher events.Publisher,
 	cfg *config.Config,
 	l *slog.Logger,
 	redisOpt asynq.RedisClientOpt) *Server {
-	return &Server{
-		worker:   worker,
-		cfg:      cfg,
-		l:        l,
-		redisOpt: redisOpt,
+	limiter := newLimiter(redisOpt)
+	server := &Server{
+		worker:    worker,
+		providers: providers,
+		converter: converter,
+		store:     s,
+		// a payment outcome frees the phone for the next push.
+		publisher: ratelimit.NewReleasingPublisher(limiter, publisher),
+		cfg:       cfg,
+		l:         l,
+		redisOpt:  redisOpt,
+		health:    grpchealth.NewServer(),
+		limiter:   limiter,
+		guard:     guard,
+	}
+	server.checks = server.readinessChecks()
+	server.current.Store(cfg)
+	server.setTrustedProxies(cfg)
+	return server
+}
+
+// Role is a part of paydex that is scaled on its own.
+type Role string
+
+const (
+	// RoleAPI serves the grpc api.
+	RoleAPI Role = "api"
+	// RoleGateway serves the rest gateway, swagger ui and provider callbacks.
+	RoleGateway Role = "gateway"
+	// RoleWorker processes the queued tasks.
+	RoleWorker Role = "worker"
+	// RoleScheduler enqueues the periodic tasks on the leader instance.
+	RoleScheduler Role = "scheduler"
+)
+
+// AllRoles run every part of paydex in one process.
+var AllRoles = []Role{RoleAPI, RoleGateway, RoleWorker, RoleScheduler}
+
+// RequiredServers are the server sections of c the roles need, the
+// gateway dials the grpc server unless an upstream is configured.
+func RequiredServers(c *config.Config, roles ...Role) []string {
+	var api, gateway bool
+	for _, role := range roles {
+		switch role {
+		case RoleAPI:
+			api = true
+		case RoleGateway:
+			gateway = true
+		}
+	}
+	var servers []string
+	if api || gateway && c.Servers["http"].Upstream == "" {
+		servers = append(servers, "grpc")
+	}
+	if gateway {
+		servers = append(servers, "http")
+	}
+	return servers
+}
+
+// Components are the servers of the roles in start order, the metrics
+// server runs when configured and the debug server outside production.
+func (s *Server) Components(roles ...Role) ([]lifecycle.Component, error) {
+	var components []lifecycle.Component
+	serveAPI := false
+	for _, role := range roles {
+		switch role {
+		case RoleAPI:
+			grpcServer, err := s.GrpcServer()
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, grpcServer)
+			serveAPI = true
+		case RoleGateway:
+			httpServer, err := s.HTTPServer()
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, httpServer)
+		case RoleWorker:
+			components = append(components, s.TaskProcessor())
+			if s.guard != nil {
+				components = append(components, s.MpesaGuard())
+			}
+		case RoleScheduler:
+			components = append(components, s.Scheduler())
+		default:
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+	}
+	if serveAPI {
+		// the health watcher is stopped first so instances leave the load balancer before draining.
+		components = append(components, s.HealthWatcher())
+	}
+
+	metricsHandler := metrics.Handler(s.metricsRegistry())
+	if conf, ok := s.cfg.Servers["metrics"]; ok {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricsHandler)
+		components = append(components, httpComponent("metrics server", &http.Server{
+			Addr:              fmt.Sprintf("%s:%s", conf.Address, conf.Port),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}))
+	}
+	//Register debug handlers
+	if !s.cfg.Prod {
+		log.Printf("system is in debug mode: running debug servers @http://localhost:8091")
+		components = append(components, httpComponent("debug server", NewDebugServer("localhost:8091", metricsHandler).Server))
+	}
+	return components, nil
+}
+
+// protectedMethods move money, expose the payments or replay the
+// callbacks and webhooks, they require an api key.
+func protectedMethods() []string {
+	methods := []string{"GetPayment", "ListPayments", "Payout", "GetBalance", "ReversePayment", "ReplayWebhook", "ReplayCallback"}
+	for i, m := range methods {
+		methods[i] = "/" + pb.PaydexService_ServiceDesc.ServiceName + "/" + m
 	}
+	return methods
 }
 
-func (s *Server) RunGrpcServer() error {
+func (s *Server) GrpcServer() (lifecycle.Component, error) {
 	dsn := fmt.Sprintf("%s:%s", s.cfg.Servers["grpc"].Address, s.cfg.Servers["grpc"].Port)
 
 	lis, err := net.Listen("tcp", dsn)
 	if err != nil {
-		return err
+		return lifecycle.Component{}, err
 	}
 
+	authenticator := auth.New(s.conf, protectedMethods()...)
 	grpcServer := grpc.NewServer(
 		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(
 			otelgrpc.StreamServerInterceptor(),
+			authenticator.StreamServerInterceptor(),
+			validator.StreamServerInterceptor(),
 			// grpc_recovery.StreamServerInterceptor(),
 		)),
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
 			otelgrpc.UnaryServerInterceptor(),
+			metrics.UnaryServerInterceptor(),
+			authenticator.UnaryServerInterceptor(),
+			validator.UnaryServerInterceptor(),
 		)))
 
 	pb.RegisterPaydexServiceServer(grpcServer, s)
+	healthpb.RegisterHealthServer(grpcServer, s.health)
 	reflection.Register(grpcServer)
 
-	log.Print("grpc sever started")
-
-	return grpcServer.Serve(lis)
+	stopped := make(chan struct{})
+	return lifecycle.Component{
+		Name: "grpc server",
+		Start: func() error {
+			log.Print("grpc sever started")
+			if err := grpcServer.Serve(lis); err != nil {
+				return err
+			}
+			// Serve returns as soon as the listener closes, wait for the rpcs to drain.
+			<-stopped
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			defer close(stopped)
+			drained := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(drained)
+			}()
+			select {
+			case <-drained:
+				return nil
+			case <-ctx.Done():
+				grpcServer.Stop()
+				return ctx.Err()
+			}
+		},
+	}, nil
 }
 
-func (s *Server) RunHTTPServer() error {
+func (s *Server) HTTPServer() (lifecycle.Component, error) {
 	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
 
-	dsn := fmt.Sprintf("%s:%s", s.cfg.Servers["http"].Address, s.cfg.Servers["http"].Port)
-	// dial the gRPC server above to make a client connection
-	conn, err := grpc.Dial(dsn, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	httpConf := s.cfg.Servers["http"]
+	// dial the gRPC api to make a client connection
+	conn, err := grpc.Dial(upstreamAddress(s.cfg),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
 	if err != nil {
-		return fmt.Errorf("fail to dial: %w", err)
+		return lifecycle.Component{}, fmt.Errorf("fail to dial: %w", err)
 	}
-	defer conn.Close()
 
 	// create an HTTP router using the client connection above
 	// and register it with the service client
-	rmux := runtime.NewServeMux()
+	rmux := runtime.NewServeMux(runtime.WithErrorHandler(gatewayErrorHandler))
 	client := pb.NewPaydexServiceClient(conn)
 	err = pb.RegisterPaydexServiceHandlerClient(ctx, rmux, client)
 	if err != nil {
-		return err
+		conn.Close()
+		return lifecycle.Component{}, err
 	}
 
 	// create a standard HTTP router
@@ -98,22 +285,45 @@ func (s *Server) RunHTTPServer() error {
 	// mount the gRPC HTTP gateway to the root
 	mux.Handle("/", rmux)
 
-	// mount a path to expose the generated OpenAPI specification on disk
+	// liveness and readiness probes of the orchestrator.
+	mux.Handle("/healthz", health.LivenessHandler())
+	mux.Handle("/readyz", health.ReadinessHandler(s.checks))
+
+	inbox, err := s.callbackInbox()
+	if err != nil {
+		conn.Close()
+		return lifecycle.Component{}, fmt.Errorf("invalid mpesa callback config: %w", err)
+	}
+	// airtel posts the outcome of ussd pushes here.
+	mux.Handle("/callbacks/airtel", inbox.AirtelHandler())
+	// Mpesa.CallbackURL should point here, the b2c and reversal ResultURL to /callbacks/mpesa/result.
+	mux.Handle("/callbacks/mpesa", inbox.Handler(callback.KindStk))
+	mux.Handle("/callbacks/mpesa/result", inbox.Handler(callback.KindResult))
+
+	// mount the Swagger UI and the OpenAPI specification generated with the protos.
+	swaggerUI, err := fs.Sub(assets.EmbeddedFiles, "swagger-ui")
+	if err != nil {
+		conn.Close()
+		return lifecycle.Component{}, err
+	}
+	spec, err := assets.EmbeddedFiles.ReadFile("paydex.swagger.json")
+	if err != nil {
+		conn.Close()
+		return lifecycle.Component{}, err
+	}
 	mux.HandleFunc("/swagger-ui/paydex.swagger.json", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./gen/protos/service.swagger.json")
+		http.ServeContent(w, r, "paydex.swagger.json", time.Time{}, bytes.NewReader(spec))
 	})
-
-	// mount the Swagger UI that uses the OpenAPI specification path above
-	mux.Handle("/swagger-ui/", http.StripPrefix("/swagger-ui/", http.FileServer(http.FS(assets.EmbeddedFiles))))
+	mux.Handle("/swagger-ui/", http.StripPrefix("/swagger-ui/", http.FileServer(http.FS(swaggerUI))))
 
 	srv := &http.Server{
-		Addr:              "localhost:8080",
-		Handler:           mux,
+		Addr:              fmt.Sprintf("%s:%s", httpConf.Address, httpConf.Port),
+		Handler:           otelhttp.NewHandler(mux, "gateway"),
 		TLSConfig:         nil,
-		ReadTimeout:       time.Duration(s.cfg.Servers["http"].Timeout) * time.Second,
-		ReadHeaderTimeout: time.Duration(s.cfg.Servers["http"].Timeout) * time.Second,
-		WriteTimeout:      time.Duration(s.cfg.Servers["http"].Timeout) * time.Second,
-		IdleTimeout:       time.Duration(s.cfg.Servers["http"].Timeout) * time.Second,
+		ReadTimeout:       time.Duration(httpConf.Timeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(httpConf.Timeout) * time.Second,
+		WriteTimeout:      time.Duration(httpConf.Timeout) * time.Second,
+		IdleTimeout:       time.Duration(httpConf.Timeout) * time.Second,
 		MaxHeaderBytes:    0,
 		TLSNextProto:      nil,
 		ConnState:         nil,
@@ -121,45 +331,156 @@ func (s *Server) RunHTTPServer() error {
 		BaseContext:       nil,
 		ConnContext:       nil,
 	}
-	log.Print("http sever started")
+	c := httpComponent("http server", srv)
+	stop := c.Stop
+	c.Stop = func(ctx context.Context) error {
+		// the in flight requests still use the connection.
+		defer conn.Close()
+		return stop(ctx)
+	}
+	return c, nil
+}
 
-	//Register debug handlers
-	if !s.cfg.Prod {
-		log.Printf("system is in debug mode: running debug servers @http://localhost:8091")
-		debugServer := NewDebugServer("localhost:8091")
-		go func() {
-			log.Fatal(debugServer.ListenAndServe())
-		}()
+// httpComponent serves srv until it is shut down.
+func httpComponent(name string, srv *http.Server) lifecycle.Component {
+	return lifecycle.Component{
+		Name: name,
+		Start: func() error {
+			slog.Info("http server started", "name", name, "address", srv.Addr)
+			if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: srv.Shutdown,
+	}
+}
+
+// upstreamAddress is the address of the grpc api the gateway dials, the
+// configured upstream or else servers.grpc.
+func upstreamAddress(c *config.Config) string {
+	if upstream := c.Servers["http"].Upstream; upstream != "" {
+		return upstream
+	}
+	grpcConf := c.Servers["grpc"]
+	return dialAddress(grpcConf.Address, grpcConf.Port)
+}
+
+// dialAddress is the address the gateway dials to reach a server listening
+// on address, a server listening on all interfaces is dialed on localhost.
+func dialAddress(address, port string) string {
+	if ip := net.ParseIP(address); address == "" || (ip != nil && ip.IsUnspecified()) {
+		address = "localhost"
 	}
+	return net.JoinHostPort(address, port)
+}
 
-	// start a standard HTTP server with the router
-	return srv.ListenAndServe()
+// TaskProcessor runs the asynq server, stopping it waits for the active
+// tasks up to the shutdown timeout.
+func (s *Server) TaskProcessor() lifecycle.Component {
+	taskProcessor := worker.NewRedisTaskProcessor(s.redisOpt, s.cfg, s.providers, s.store, s.publisher)
+	s.processor = taskProcessor
+	stopped := make(chan struct{})
+	return lifecycle.Component{
+		Name: "task processor",
+		Start: func() error {
+			slog.Info("start task processor")
+			if err := taskProcessor.Start(); err != nil {
+				slog.Error("failed to start task processor", err)
+				return err
+			}
+			<-stopped
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				taskProcessor.Shutdown()
+				close(done)
+				close(stopped)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
 }
 
-func (s *Server) RunTaskProcessor() error {
-	taskProcessor := worker.NewRedisTaskProcessor(s.redisOpt, s.cfg)
-	slog.Info("start task processor")
-	if err := taskProcessor.Start(); err != nil {
-		slog.Error("failed to start task processor", err)
-		return err
+// Scheduler enqueues the periodic tasks while this instance holds the
+// scheduler leader lock, stopping it releases the lock.
+func (s *Server) Scheduler() lifecycle.Component {
+	scheduler := worker.NewScheduler(s.redisOpt, s.cfg, worker.NewSchedulerLock(s.redisOpt))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	return lifecycle.Component{
+		Name: "scheduler",
+		Start: func() error {
+			defer close(stopped)
+			return scheduler.Run(ctx)
+		},
+		Stop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-stopped:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
 	}
-	if err := taskProcessor.StartScheduler(); err != nil {
-		slog.Error("failed to start task scheduler", err)
-		return err
+}
+
+// MpesaGuard pauses the mpesa queue while the daraja circuit is open and
+// resumes it once a probe succeeded.
+func (s *Server) MpesaGuard() lifecycle.Component {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	return lifecycle.Component{
+		Name: "mpesa guard",
+		Start: func() error {
+			defer close(stopped)
+			s.guard.Watch(ctx, s.redisOpt, s.providers)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			cancel()
+			<-stopped
+			return nil
+		},
 	}
-	return nil
+}
+
+// metricsRegistry collects the queue metrics, it is created per call so
+// building the components twice does not register them twice.
+func (s *Server) metricsRegistry() *prometheus.Registry {
+	inspector := asynq.NewInspector(s.redisOpt)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.NewQueueCollector(inspector, worker.QueueCritical, worker.QueueDefault, worker.QueueMpesa, worker.QueueWebhooks))
+	return registry
 }
 
 type DebugServer struct {
 	*http.Server
 }
 
-// NewDebugServer provides new debug http server
-func NewDebugServer(address string) *DebugServer {
+// NewDebugServer provides new debug http server serving pprof, expvar and
+// the metrics.
+func NewDebugServer(address string, metricsHandler http.Handler) *DebugServer {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/metrics", metricsHandler)
 	return &DebugServer{
 		&http.Server{
 			Addr:        address,
-			Handler:     http.DefaultServeMux,
+			Handler:     mux,
 			ReadTimeout: 1 * time.Second,
 		},
 	}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"paydex/config"
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestDialAddress(t *testing.T) {
+	tests := []struct{ address, want string }{
+		{"", "localhost:9090"},
+		{"0.0.0.0", "localhost:9090"},
+		{"::", "localhost:9090"},
+		{"10.0.0.5", "10.0.0.5:9090"},
+		{"::1", "[::1]:9090"},
+		{"grpc.internal", "grpc.internal:9090"},
+	}
+	for _, tt := range tests {
+		if got := dialAddress(tt.address, "9090"); got != tt.want {
+			t.Errorf("dialAddress(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestUpstreamAddress(t *testing.T) {
+	tests := []struct{ config, want string }{
+		{"[servers.grpc]\nport = \"9090\"\n[servers.http]\nport = \"8080\"", "localhost:9090"},
+		{"[servers.http]\nport = \"8080\"\nupstream = \"paydex-api:9090\"", "paydex-api:9090"},
+	}
+	for _, tt := range tests {
+		var c config.Config
+		if _, err := toml.Decode(tt.config, &c); err != nil {
+			t.Fatal(err)
+		}
+		if got := upstreamAddress(&c); got != tt.want {
+			t.Errorf("upstreamAddress() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRequiredServers(t *testing.T) {
+	upstream := &config.Config{}
+	if _, err := toml.Decode("[servers.http]\nupstream = \"paydex-api:9090\"", upstream); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		c     *config.Config
+		roles []Role
+		want  []string
+	}{
+		{name: "all", c: &config.Config{}, roles: AllRoles, want: []string{"grpc", "http"}},
+		{name: "api", c: &config.Config{}, roles: []Role{RoleAPI}, want: []string{"grpc"}},
+		{name: "gateway", c: &config.Config{}, roles: []Role{RoleGateway}, want: []string{"grpc", "http"}},
+		{name: "gateway with upstream", c: upstream, roles: []Role{RoleGateway}, want: []string{"http"}},
+		{name: "worker", c: &config.Config{}, roles: []Role{RoleWorker, RoleScheduler}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequiredServers(tt.c, tt.roles...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RequiredServers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	pb "paydex/pkg/gen"
+	"paydex/store"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ReplayWebhook queues the delivery again with a fresh set of retries,
+// the attempts keep counting in the delivery log.
+func (s *Server) ReplayWebhook(ctx context.Context, in *pb.ReplayWebhookRequest) (*pb.WebhookDelivery, error) {
+	d, err := s.store.GetDelivery(ctx, in.DeliveryId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "webhook delivery %s not found", in.DeliveryId)
+	}
+	if err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to get webhook delivery")
+	}
+	d.Status = store.DeliveryPending
+	d.UpdatedAt = time.Now()
+	if err := s.store.UpdateDelivery(ctx, d); err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to update webhook delivery")
+	}
+	if err := s.worker.DistributeTaskDeliverWebhook(ctx, d.ID); err != nil {
+		log.Print(err)
+		return nil, status.Error(codes.Internal, "unable to queue webhook delivery")
+	}
+	return toProtoDelivery(d), nil
+}
+
+func toProtoDelivery(d *store.WebhookDelivery) *pb.WebhookDelivery {
+	return &pb.WebhookDelivery{
+		Id:             d.ID,
+		MerchantId:     d.MerchantID,
+		SubscriptionId: d.SubscriptionID,
+		Url:            d.URL,
+		EventId:        d.EventID,
+		EventType:      d.EventType,
+		PaymentId:      d.PaymentID,
+		Status:         string(d.Status),
+		Attempts:       int32(d.Attempts),
+		ResponseStatus: int32(d.ResponseStatus),
+		LastError:      d.LastError,
+		CreatedAt:      timestamppb.New(d.CreatedAt),
+		UpdatedAt:      timestamppb.New(d.UpdatedAt),
+	}
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps records in memory, it is meant for tests and local development.
+type MemoryStore struct {
+	payments      map[string]Payment
+	callbacks     map[string]Callback
+	callbackIndex map[string]string
+	deliveries    map[string]WebhookDelivery
+	lock          *sync.RWMutex
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		payments:      make(map[string]Payment),
+		callbacks:     make(map[string]Callback),
+		callbackIndex: make(map[string]string),
+		deliveries:    make(map[string]WebhookDelivery),
+		lock:          &sync.RWMutex{},
+	}
+}
+
+func (s *MemoryStore) CreatePayment(_ context.Context, p *Payment) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.payments[p.ID] = *p
+	return nil
+}
+
+func (s *MemoryStore) GetPayment(_ context.Context, id string) (*Payment, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	p, ok := s.payments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (s *MemoryStore) GetPaymentByTransactionID(_ context.Context, provider, transactionID string) (*Payment, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, p := range s.payments {
+		if p.Provider == provider && transactionID != "" && (p.TransactionID == transactionID || p.ReversalID == transactionID) {
+			p := p
+			return &p, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) UpdatePayment(_ context.Context, p *Payment) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.payments[p.ID]; !ok {
+		return ErrNotFound
+	}
+	s.payments[p.ID] = *p
+	return nil
+}
+
+func (s *MemoryStore) SwapPayment(_ context.Context, p *Payment, from PaymentStatus) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	old, ok := s.payments[p.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if old.Status != from {
+		return ErrConflict
+	}
+	s.payments[p.ID] = *p
+	return nil
+}
+
+func (s *MemoryStore) ListPayments(_ context.Context, filter PaymentFilter) ([]*Payment, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var payments []*Payment
+	for _, p := range s.payments {
+		if filter.matches(&p) {
+			p := p
+			payments = append(payments, &p)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].CreatedAt.Before(payments[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(payments) > filter.Limit {
+		payments = payments[:filter.Limit]
+	}
+	return payments, nil
+}
+
+func (s *MemoryStore) DeletePayment(_ context.Context, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.payments, id)
+	return nil
+}
+
+func (s *MemoryStore) SaveCallback(_ context.Context, c *Callback) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.callbacks[c.ID] = *c
+	return nil
+}
+
+func (s *MemoryStore) GetCallback(_ context.Context, id string) (*Callback, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	c, ok := s.callbacks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+func (s *MemoryStore) UpdateCallback(_ context.Context, c *Callback) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.callbacks[c.ID]; !ok {
+		return ErrNotFound
+	}
+	s.callbacks[c.ID] = *c
+	return nil
+}
+
+func (s *MemoryStore) IndexCallback(_ context.Context, key, id string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if first, ok := s.callbackIndex[key]; ok {
+		return first, nil
+	}
+	s.callbackIndex[key] = id
+	return id, nil
+}
+
+func (s *MemoryStore) ListCallbacks(_ context.Context, filter CallbackFilter) ([]*Callback, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var callbacks []*Callback
+	for _, c := range s.callbacks {
+		if !filter.ReceivedBefore.IsZero() && !c.ReceivedAt.Before(filter.ReceivedBefore) {
+			continue
+		}
+		c := c
+		callbacks = append(callbacks, &c)
+	}
+	sort.Slice(callbacks, func(i, j int) bool {
+		return callbacks[i].ReceivedAt.Before(callbacks[j].ReceivedAt)
+	})
+	if filter.Limit > 0 && len(callbacks) > filter.Limit {
+		callbacks = callbacks[:filter.Limit]
+	}
+	return callbacks, nil
+}
+
+func (s *MemoryStore) DeleteCallback(_ context.Context, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if c, ok := s.callbacks[id]; ok && s.callbackIndex[c.DedupeKey] == id {
+		delete(s.callbackIndex, c.DedupeKey)
+	}
+	delete(s.callbacks, id)
+	return nil
+}
+
+func (s *MemoryStore) CreateDelivery(_ context.Context, d *WebhookDelivery) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.deliveries[d.ID] = *d
+	return nil
+}
+
+func (s *MemoryStore) GetDelivery(_ context.Context, id string) (*WebhookDelivery, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &d, nil
+}
+
+func (s *MemoryStore) UpdateDelivery(_ context.Context, d *WebhookDelivery) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.deliveries[d.ID]; !ok {
+		return ErrNotFound
+	}
+	s.deliveries[d.ID] = *d
+	return nil
+}
+
+func (s *MemoryStore) ListDeliveries(_ context.Context, filter DeliveryFilter) ([]*WebhookDelivery, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var deliveries []*WebhookDelivery
+	for _, d := range s.deliveries {
+		if filter.matches(&d) {
+			d := d
+			deliveries = append(deliveries, &d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(deliveries) > filter.Limit {
+		deliveries = deliveries[:filter.Limit]
+	}
+	return deliveries, nil
+}
+
+func (s *MemoryStore) DeleteDelivery(_ context.Context, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.deliveries, id)
+	return nil
+}
+
+func (s *MemoryStore) Ping(context.Context) error {
+	return nil
+}
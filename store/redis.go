@@ -0,0 +1,401 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	paymentKey       = "paydex:payment:"
+	paymentsKey      = "paydex:payments"
+	paymentStatusKey = "paydex:payments:status:"
+	transactionKey   = "paydex:payment:transaction:"
+	callbackKey      = "paydex:callback:"
+	callbacksKey     = "paydex:callbacks"
+	callbackIndexKey = "paydex:callback:key:"
+	deliveryKey      = "paydex:webhook:delivery:"
+	deliveriesKey    = "paydex:webhook:deliveries"
+	// deliveries of a payment are indexed under deliveriesKey + ":payment:" + id.
+	paymentDeliveriesKey = deliveriesKey + ":payment:"
+)
+
+// RedisStore keeps records as json documents indexed by sorted sets
+// scored with the creation time in milliseconds.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) CreatePayment(ctx context.Context, p *Payment) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	score := float64(p.CreatedAt.UnixMilli())
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, paymentKey+p.ID, b, 0)
+		pipe.ZAdd(ctx, paymentsKey, &redis.Z{Score: score, Member: p.ID})
+		pipe.ZAdd(ctx, paymentStatusKey+string(p.Status), &redis.Z{Score: score, Member: p.ID})
+		if p.TransactionID != "" {
+			pipe.Set(ctx, transactionKey+p.Provider+":"+p.TransactionID, p.ID, 0)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) GetPayment(ctx context.Context, id string) (*Payment, error) {
+	return getPayment(ctx, s.client, id)
+}
+
+func getPayment(ctx context.Context, c redis.Cmdable, id string) (*Payment, error) {
+	b, err := c.Get(ctx, paymentKey+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p Payment
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("unable to decode payment %s: %w", id, err)
+	}
+	return &p, nil
+}
+
+func (s *RedisStore) GetPaymentByTransactionID(ctx context.Context, provider, transactionID string) (*Payment, error) {
+	id, err := s.client.Get(ctx, transactionKey+provider+":"+transactionID).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPayment(ctx, id)
+}
+
+func (s *RedisStore) UpdatePayment(ctx context.Context, p *Payment) error {
+	old, err := s.GetPayment(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		return setPayment(ctx, pipe, old, p)
+	})
+	return err
+}
+
+// SwapPayment watches the payment, the transaction fails when it is
+// written in the meantime.
+func (s *RedisStore) SwapPayment(ctx context.Context, p *Payment, from PaymentStatus) error {
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		old, err := getPayment(ctx, tx, p.ID)
+		if err != nil {
+			return err
+		}
+		if old.Status != from {
+			return ErrConflict
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return setPayment(ctx, pipe, old, p)
+		})
+		return err
+	}, paymentKey+p.ID)
+	if errors.Is(err, redis.TxFailedErr) {
+		return ErrConflict
+	}
+	return err
+}
+
+// setPayment writes p and moves it between the status indexes.
+func setPayment(ctx context.Context, pipe redis.Pipeliner, old, p *Payment) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	score := float64(p.CreatedAt.UnixMilli())
+	pipe.Set(ctx, paymentKey+p.ID, b, 0)
+	if old.Status != p.Status {
+		pipe.ZRem(ctx, paymentStatusKey+string(old.Status), p.ID)
+		pipe.ZAdd(ctx, paymentStatusKey+string(p.Status), &redis.Z{Score: score, Member: p.ID})
+	}
+	if p.TransactionID != "" {
+		pipe.Set(ctx, transactionKey+p.Provider+":"+p.TransactionID, p.ID, 0)
+	}
+	if p.ReversalID != "" {
+		pipe.Set(ctx, transactionKey+p.Provider+":"+p.ReversalID, p.ID, 0)
+	}
+	return nil
+}
+
+func (s *RedisStore) ListPayments(ctx context.Context, filter PaymentFilter) ([]*Payment, error) {
+	key := paymentsKey
+	if filter.Status != "" {
+		key = paymentStatusKey + string(filter.Status)
+	}
+	ids, err := s.client.ZRangeByScore(ctx, key, scoreRange(filter.CreatedAfter, filter.CreatedBefore, filter.Limit)).Result()
+	if err != nil {
+		return nil, err
+	}
+	payments := make([]*Payment, 0, len(ids))
+	for _, id := range ids {
+		p, err := s.GetPayment(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, nil
+}
+
+func (s *RedisStore) DeletePayment(ctx context.Context, id string) error {
+	p, err := s.GetPayment(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, paymentKey+id)
+		pipe.ZRem(ctx, paymentsKey, id)
+		pipe.ZRem(ctx, paymentStatusKey+string(p.Status), id)
+		if p.TransactionID != "" {
+			pipe.Del(ctx, transactionKey+p.Provider+":"+p.TransactionID)
+		}
+		if p.ReversalID != "" {
+			pipe.Del(ctx, transactionKey+p.Provider+":"+p.ReversalID)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) SaveCallback(ctx context.Context, c *Callback) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, callbackKey+c.ID, b, 0)
+		pipe.ZAdd(ctx, callbacksKey, &redis.Z{Score: float64(c.ReceivedAt.UnixMilli()), Member: c.ID})
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) GetCallback(ctx context.Context, id string) (*Callback, error) {
+	b, err := s.client.Get(ctx, callbackKey+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Callback
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("unable to decode callback %s: %w", id, err)
+	}
+	return &c, nil
+}
+
+func (s *RedisStore) UpdateCallback(ctx context.Context, c *Callback) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	ok, err := s.client.SetXX(ctx, callbackKey+c.ID, b, redis.KeepTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *RedisStore) IndexCallback(ctx context.Context, key, id string) (string, error) {
+	ok, err := s.client.SetNX(ctx, callbackIndexKey+key, id, 0).Result()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return id, nil
+	}
+	return s.client.Get(ctx, callbackIndexKey+key).Result()
+}
+
+func (s *RedisStore) ListCallbacks(ctx context.Context, filter CallbackFilter) ([]*Callback, error) {
+	ids, err := s.client.ZRangeByScore(ctx, callbacksKey, scoreRange(time.Time{}, filter.ReceivedBefore, filter.Limit)).Result()
+	if err != nil {
+		return nil, err
+	}
+	callbacks := make([]*Callback, 0, len(ids))
+	for _, id := range ids {
+		c, err := s.GetCallback(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		callbacks = append(callbacks, c)
+	}
+	return callbacks, nil
+}
+
+func (s *RedisStore) DeleteCallback(ctx context.Context, id string) error {
+	c, err := s.GetCallback(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	first := ""
+	if c.DedupeKey != "" {
+		first, err = s.client.Get(ctx, callbackIndexKey+c.DedupeKey).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, callbackKey+id)
+		pipe.ZRem(ctx, callbacksKey, id)
+		if first == id {
+			pipe.Del(ctx, callbackIndexKey+c.DedupeKey)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) CreateDelivery(ctx context.Context, d *WebhookDelivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	score := float64(d.CreatedAt.UnixMilli())
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, deliveryKey+d.ID, b, 0)
+		pipe.ZAdd(ctx, deliveriesKey, &redis.Z{Score: score, Member: d.ID})
+		if d.PaymentID != "" {
+			pipe.ZAdd(ctx, paymentDeliveriesKey+d.PaymentID, &redis.Z{Score: score, Member: d.ID})
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) GetDelivery(ctx context.Context, id string) (*WebhookDelivery, error) {
+	b, err := s.client.Get(ctx, deliveryKey+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var d WebhookDelivery
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, fmt.Errorf("unable to decode webhook delivery %s: %w", id, err)
+	}
+	return &d, nil
+}
+
+func (s *RedisStore) UpdateDelivery(ctx context.Context, d *WebhookDelivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	// XX only updates existing deliveries.
+	ok, err := s.client.SetXX(ctx, deliveryKey+d.ID, b, redis.KeepTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *RedisStore) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*WebhookDelivery, error) {
+	key := deliveriesKey
+	if filter.PaymentID != "" {
+		key = paymentDeliveriesKey + filter.PaymentID
+	}
+	// the status is not indexed so the limit is applied after filtering.
+	ids, err := s.client.ZRangeByScore(ctx, key, scoreRange(time.Time{}, filter.CreatedBefore, 0)).Result()
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []*WebhookDelivery
+	for _, id := range ids {
+		d, err := s.GetDelivery(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !filter.matches(d) {
+			continue
+		}
+		deliveries = append(deliveries, d)
+		if filter.Limit > 0 && len(deliveries) == filter.Limit {
+			break
+		}
+	}
+	return deliveries, nil
+}
+
+func (s *RedisStore) DeleteDelivery(ctx context.Context, id string) error {
+	d, err := s.GetDelivery(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, deliveryKey+id)
+		pipe.ZRem(ctx, deliveriesKey, id)
+		if d.PaymentID != "" {
+			pipe.ZRem(ctx, paymentDeliveriesKey+d.PaymentID, id)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// scoreRange builds an exclusive range over the millisecond scores.
+func scoreRange(after, before time.Time, limit int) *redis.ZRangeBy {
+	r := &redis.ZRangeBy{Min: "-inf", Max: "+inf", Count: int64(limit)}
+	if !after.IsZero() {
+		r.Min = "(" + strconv.FormatInt(after.UnixMilli(), 10)
+	}
+	if !before.IsZero() {
+		r.Max = "(" + strconv.FormatInt(before.UnixMilli(), 10)
+	}
+	if limit <= 0 {
+		r.Count = 0
+	}
+	return r
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"paydex/money"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestRedisStore_SwapPayment(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+	p := &Payment{ID: "6f1c7a0e", Provider: "mpesa", Status: PaymentPending, CreatedAt: time.Now()}
+	if err := s.CreatePayment(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	completed := *p
+	completed.Status = PaymentCompleted
+	if err := s.SwapPayment(ctx, &completed, PaymentPending); err != nil {
+		t.Fatalf("SwapPayment() error = %v", err)
+	}
+	expired := *p
+	expired.Status = PaymentExpired
+	if err := s.SwapPayment(ctx, &expired, PaymentPending); !errors.Is(err, ErrConflict) {
+		t.Fatalf("SwapPayment() error = %v, want ErrConflict", err)
+	}
+	got, err := s.GetPayment(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != PaymentCompleted {
+		t.Errorf("status = %s, want %s", got.Status, PaymentCompleted)
+	}
+	pending, err := s.ListPayments(ctx, PaymentFilter{Status: PaymentPending})
+	if err != nil || len(pending) != 0 {
+		t.Errorf("ListPayments(pending) = %d, %v, want the payment moved out of the index", len(pending), err)
+	}
+}
+
+func TestRedisStore_GetPayment_Legacy(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	mr.Set(paymentKey+"6f1c7a0e", `{"ID": "6f1c7a0e", "Status": "completed", "Amount": "10.50", "Currency": "KES", "OriginalAmount": "", "OriginalCurrency": ""}`)
+
+	p, err := s.GetPayment(context.Background(), "6f1c7a0e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (money.Money{Minor: 1050, Currency: "KES"}); !p.Amount.Equal(want) || !p.OriginalAmount.IsZero() {
+		t.Errorf("payment amounts = %v, %v, want %v and none", p.Amount, p.OriginalAmount, want)
+	}
+	if p.Status != PaymentCompleted {
+		t.Errorf("Status = %s, want %s", p.Status, PaymentCompleted)
+	}
+}
+
+func TestRedisStore_DeleteDelivery(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+	now := time.Now()
+	for _, d := range []*WebhookDelivery{
+		{ID: "old", PaymentID: "p1", CreatedAt: now.Add(-time.Hour)},
+		{ID: "new", PaymentID: "p1", CreatedAt: now},
+	} {
+		if err := s.CreateDelivery(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old, err := s.ListDeliveries(ctx, DeliveryFilter{CreatedBefore: now.Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(old) != 1 || old[0].ID != "old" {
+		t.Fatalf("ListDeliveries() = %v, want the old delivery", old)
+	}
+	if err := s.DeleteDelivery(ctx, "old"); err != nil {
+		t.Fatal(err)
+	}
+	left, err := s.ListDeliveries(ctx, DeliveryFilter{PaymentID: "p1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 || left[0].ID != "new" {
+		t.Errorf("deliveries of the payment = %v, want the new one", left)
+	}
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"paydex/money"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when the record changed since it was read.
+var ErrConflict = errors.New("record was modified")
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentCompleted PaymentStatus = "completed"
+	PaymentFailed    PaymentStatus = "failed"
+	// PaymentExpired the outcome was never received.
+	PaymentExpired PaymentStatus = "expired"
+	// PaymentReversing a reversal of the completed payment was requested.
+	PaymentReversing PaymentStatus = "reversing"
+	PaymentReversed  PaymentStatus = "reversed"
+)
+
+// Payment is a collection requested through paydex.
+type Payment struct {
+	ID string
+	// MerchantID is the merchant whose webhooks receive the payment events.
+	MerchantID string
+	Provider   string
+	Status     PaymentStatus
+	Amount     money.Money
+	// PII, anonymized once the retention period is over.
+	PhoneNumber string
+	Name        string
+	Description string
+	// TransactionID is the id the provider uses for the payment
+	// e.g the mpesa CheckoutRequestID.
+	TransactionID string
+	ResultCode    string
+	ResultDesc    string
+	// ReceiptNumber is the provider receipt e.g the mpesa receipt.
+	ReceiptNumber string
+	// ReversalID is the id the provider uses for the reversal of the
+	// payment e.g the mpesa ConversationID.
+	ReversalID string `json:",omitempty"`
+	// CallbackTokenHash is the hash of the token embedded in the callback url.
+	CallbackTokenHash string
+	// Trace is the trace context of the request that created the payment,
+	// the callback is traced as part of it.
+	Trace map[string]string `json:",omitempty"`
+	// set when the amount was converted from another currency.
+	OriginalAmount money.Money
+	ExchangeRate   float64
+	RateTimestamp  time.Time
+	Anonymized     bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// UnmarshalJSON also reads the payments stored before the amounts were
+// money, with decimal string amounts and the currencies apart.
+func (p *Payment) UnmarshalJSON(b []byte) error {
+	type payment Payment
+	v := struct {
+		*payment
+		Amount           json.RawMessage
+		Currency         string
+		OriginalAmount   json.RawMessage
+		OriginalCurrency string
+	}{payment: (*payment)(p)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	var err error
+	if p.Amount, err = money.UnmarshalLegacy(v.Amount, v.Currency); err != nil {
+		return err
+	}
+	p.OriginalAmount, err = money.UnmarshalLegacy(v.OriginalAmount, v.OriginalCurrency)
+	return err
+}
+
+type CallbackStatus string
+
+const (
+	CallbackReceived  CallbackStatus = "received"
+	CallbackProcessed CallbackStatus = "processed"
+	// CallbackDuplicate was already received, see DuplicateOf.
+	CallbackDuplicate CallbackStatus = "duplicate"
+	// CallbackRejected failed authentication.
+	CallbackRejected CallbackStatus = "rejected"
+	CallbackFailed   CallbackStatus = "failed"
+)
+
+// Callback is a raw callback received from a provider,
+// it is stored before it is parsed so it can be processed again.
+type Callback struct {
+	ID       string
+	Provider string
+	// Kind is the callback type e.g stk or result.
+	Kind       string
+	Body       []byte
+	Headers    http.Header
+	Query      string
+	RemoteAddr string
+	// DedupeKey identifies the transaction e.g the mpesa CheckoutRequestID.
+	DedupeKey   string
+	DuplicateOf string
+	Status      CallbackStatus
+	Attempts    int
+	Error       string
+	ReceivedAt  time.Time
+	ProcessedAt time.Time
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event sent to a merchant webhook, it is kept
+// as the delivery log and to replay the event.
+type WebhookDelivery struct {
+	ID             string
+	MerchantID     string
+	SubscriptionID string
+	URL            string
+	EventID        string
+	EventType      string
+	PaymentID      string
+	// Payload is the json body sent to the webhook.
+	Payload  []byte
+	Status   DeliveryStatus
+	Attempts int
+	// ResponseStatus is the http status of the last attempt.
+	ResponseStatus int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeliveredAt    time.Time
+}
+
+type PaymentFilter struct {
+	// Status limits the payments to the status, all payments when empty.
+	Status PaymentStatus
+	// CreatedBefore and CreatedAfter limit the payments by creation time.
+	CreatedBefore time.Time
+	CreatedAfter  time.Time
+	// Limit is the maximum number of payments returned, 0 returns all.
+	Limit int
+}
+
+type CallbackFilter struct {
+	ReceivedBefore time.Time
+	Limit          int
+}
+
+type DeliveryFilter struct {
+	// PaymentID limits the deliveries to the events of the payment.
+	PaymentID string
+	Status    DeliveryStatus
+	// CreatedBefore limits the deliveries by creation time.
+	CreatedBefore time.Time
+	Limit         int
+}
+
+// Store persists payments and callbacks.
+type Store interface {
+	CreatePayment(ctx context.Context, p *Payment) error
+	GetPayment(ctx context.Context, id string) (*Payment, error)
+	// GetPaymentByTransactionID finds a payment by the provider transaction
+	// id or the id of its reversal.
+	GetPaymentByTransactionID(ctx context.Context, provider, transactionID string) (*Payment, error)
+	UpdatePayment(ctx context.Context, p *Payment) error
+	// SwapPayment updates the payment when its stored status is still from,
+	// otherwise it returns ErrConflict.
+	SwapPayment(ctx context.Context, p *Payment, from PaymentStatus) error
+	// ListPayments returns payments oldest first.
+	ListPayments(ctx context.Context, filter PaymentFilter) ([]*Payment, error)
+	DeletePayment(ctx context.Context, id string) error
+
+	SaveCallback(ctx context.Context, c *Callback) error
+	GetCallback(ctx context.Context, id string) (*Callback, error)
+	UpdateCallback(ctx context.Context, c *Callback) error
+	// IndexCallback records id as the first callback with the dedupe key
+	// and returns the id of the first callback, which is id unless it is a duplicate.
+	IndexCallback(ctx context.Context, key, id string) (string, error)
+	// ListCallbacks returns callbacks oldest first.
+	ListCallbacks(ctx context.Context, filter CallbackFilter) ([]*Callback, error)
+	DeleteCallback(ctx context.Context, id string) error
+
+	CreateDelivery(ctx context.Context, d *WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (*WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, d *WebhookDelivery) error
+	// ListDeliveries returns deliveries oldest first.
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*WebhookDelivery, error)
+	DeleteDelivery(ctx context.Context, id string) error
+
+	Ping(ctx context.Context) error
+}
+
+func (f PaymentFilter) matches(p *Payment) bool {
+	if f.Status != "" && p.Status != f.Status {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !p.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !p.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	return true
+}
+
+func (f DeliveryFilter) matches(d *WebhookDelivery) bool {
+	if f.PaymentID != "" && d.PaymentID != f.PaymentID {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !d.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return f.Status == "" || d.Status == f.Status
+}
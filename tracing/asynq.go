@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TaskMiddleware starts a span for every task, the parent is the trace
+// context the distributor stored in the Trace field of the json payload.
+func TaskMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		var payload struct {
+			Trace map[string]string
+		}
+		if len(task.Payload()) > 0 {
+			// payloads without a trace context start a new trace.
+			_ = json.Unmarshal(task.Payload(), &payload)
+		}
+		ctx = Extract(ctx, payload.Trace)
+		attrs := []attribute.KeyValue{attribute.String("task.type", task.Type())}
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			attrs = append(attrs, attribute.String("task.id", id))
+		}
+		if retried, ok := asynq.GetRetryCount(ctx); ok {
+			attrs = append(attrs, attribute.Int("task.retry_count", retried))
+		}
+		ctx, span := Tracer().Start(ctx, task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(attrs...))
+		defer span.End()
+
+		err := next.ProcessTask(ctx, task)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	})
+}
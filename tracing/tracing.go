@@ -0,0 +1,95 @@
+// Package tracing configures opentelemetry and carries the trace
+// context through the task queue.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"paydex/config"
+	"paydex/pkg/version"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	serviceName = "paydex"
+)
+
+// Setup installs the global tracer provider and propagator, the returned
+// func flushes the pending spans. Tracing is disabled when no exporter is
+// configured but the trace context is still propagated.
+func Setup(ctx context.Context, c *config.Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	noop := func(context.Context) error { return nil }
+
+	var exporter sdktrace.SpanExporter
+	switch c.Tracing.Exporter {
+	case "":
+		return noop, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.Tracing.Endpoint)}
+		if c.Tracing.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return noop, fmt.Errorf("unknown tracing exporter %q", c.Tracing.Exporter)
+	}
+	if err != nil {
+		return noop, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String(serviceName),
+		semconv.ServiceVersionKey.String(version.Get()),
+	))
+	if err != nil {
+		return noop, err
+	}
+	ratio := c.Tracing.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer is the tracer of the paydex spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(serviceName)
+}
+
+// Inject returns the trace context of ctx to be stored with a task or payment.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns ctx with the trace context stored by Inject.
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTaskMiddleware_PropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	ctx, parent := Tracer().Start(context.Background(), "InitStkPush")
+	payload, err := json.Marshal(struct {
+		PaymentID string
+		Trace     map[string]string
+	}{PaymentID: "p1", Trace: Inject(ctx)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	var got trace.SpanContext
+	handler := TaskMiddleware(asynq.HandlerFunc(func(ctx context.Context, _ *asynq.Task) error {
+		got = trace.SpanContextFromContext(ctx)
+		return nil
+	}))
+	if err := handler.ProcessTask(context.Background(), asynq.NewTask("task:send_stk", payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.TraceID() != parent.SpanContext().TraceID() {
+		t.Fatalf("task trace = %s, want %s", got.TraceID(), parent.SpanContext().TraceID())
+	}
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[1].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("task span is not a child of the request span: %v", spans)
+	}
+}
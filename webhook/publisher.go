@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"paydex/config"
+	"paydex/events"
+	"paydex/store"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+// Distributor queues the delivery of a stored webhook delivery.
+type Distributor interface {
+	DistributeTaskDeliverWebhook(ctx context.Context, deliveryID string, opts ...asynq.Option) error
+}
+
+// Publisher records a delivery for every subscription of the payment merchant
+// and queues it, the worker sends it with retries.
+type Publisher struct {
+	store         store.Store
+	subscriptions atomic.Pointer[Subscriptions]
+	distributor   Distributor
+}
+
+var _ events.Publisher = (*Publisher)(nil)
+
+func NewPublisher(s store.Store, subscriptions Subscriptions, distributor Distributor) *Publisher {
+	p := &Publisher{store: s, distributor: distributor}
+	p.subscriptions.Store(&subscriptions)
+	return p
+}
+
+// Reload subscribes the webhooks of the reloaded merchants, the events
+// published after it are delivered to them.
+func (p *Publisher) Reload(c *config.Config) {
+	subscriptions := SubscriptionsFromConfig(c)
+	p.subscriptions.Store(&subscriptions)
+}
+
+func (p *Publisher) Publish(ctx context.Context, e events.Event) error {
+	slog.Info("payment event", "id", e.ID, "type", e.Type, "payment_id", e.Payment.ID, "status", e.Payment.Status)
+	subs := p.subscriptions.Load().Match(e.Payment.MerchantID, e.Type)
+	if len(subs) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(NewPayload(e))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, sub := range subs {
+		d := newDelivery(uuid.NewString(), sub, e, body, now)
+		if err := p.store.CreateDelivery(ctx, d); err != nil {
+			return fmt.Errorf("unable to save webhook delivery: %w", err)
+		}
+		// the delivery stays pending in the log and can be replayed.
+		if err := p.distributor.DistributeTaskDeliverWebhook(ctx, d.ID); err != nil {
+			return fmt.Errorf("unable to queue webhook delivery %s: %w", d.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"paydex/store"
+)
+
+var ErrUnknownSubscription = errors.New("webhook subscription not found")
+
+// Sender posts signed deliveries to the merchant webhooks.
+type Sender struct {
+	subscriptions Subscriptions
+	client        *http.Client
+}
+
+func NewSender(subscriptions Subscriptions, timeout time.Duration) *Sender {
+	return &Sender{subscriptions: subscriptions, client: &http.Client{Timeout: timeout}}
+}
+
+// Send makes one delivery attempt and records its outcome on d,
+// the caller persists d.
+func (s *Sender) Send(ctx context.Context, d *store.WebhookDelivery) error {
+	sub, ok := s.subscriptions.Get(d.MerchantID, d.SubscriptionID)
+	if !ok {
+		d.LastError = ErrUnknownSubscription.Error()
+		return fmt.Errorf("%w: %s/%s", ErrUnknownSubscription, d.MerchantID, d.SubscriptionID)
+	}
+	now := time.Now()
+	d.Attempts++
+	d.UpdatedAt = now
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		d.LastError = err.Error()
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "paydex-webhooks")
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, d.ID)
+	// signed per attempt so the timestamp stays fresh on retries.
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, now, d.Payload))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		d.ResponseStatus = 0
+		d.LastError = err.Error()
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	d.ResponseStatus = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		d.LastError = "unexpected status " + res.Status
+		return fmt.Errorf("webhook %s responded with %s", sub.URL, res.Status)
+	}
+	d.LastError = ""
+	d.Status = store.DeliverySucceeded
+	d.DeliveredAt = now
+	return nil
+}
+
+// RetryDelay is the exponential backoff between delivery attempts,
+// starting at 10 seconds and capped at an hour.
+func RetryDelay(retried int) time.Duration {
+	const maxDelay = time.Hour
+	if retried > 10 {
+		return maxDelay
+	}
+	delay := 10 * time.Second << retried
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
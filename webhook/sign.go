@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the timestamp and signature e.g t=1674200000,v1=5257a8...
+	SignatureHeader = "Paydex-Signature"
+	EventHeader     = "Paydex-Event"
+	DeliveryHeader  = "Paydex-Delivery"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the signature header of the body, the timestamp is signed
+// with the body so receivers can reject replayed requests.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + signature(secret, t, body)
+}
+
+// Verify checks the signature header of a received webhook,
+// requests signed more than tolerance before now are rejected.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			v1 = v
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(unix, 0)) > tolerance {
+		return errors.New("webhook signature has expired")
+	}
+	if !hmac.Equal([]byte(v1), []byte(signature(secret, t, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"strconv"
+	"time"
+
+	"paydex/config"
+	"paydex/events"
+	"paydex/money"
+	"paydex/store"
+)
+
+// Subscription is a merchant endpoint that receives payment events.
+type Subscription struct {
+	ID         string
+	MerchantID string
+	URL        string
+	// Events are the delivered event types, all events when empty.
+	Events []events.Type
+	Secret string
+}
+
+func (s Subscription) wants(t events.Type) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscriptions are the webhooks of every merchant.
+type Subscriptions []Subscription
+
+// SubscriptionsFromConfig reads the merchant webhooks, subscriptions
+// without an id are numbered in the order they are configured.
+func SubscriptionsFromConfig(c *config.Config) Subscriptions {
+	var subs Subscriptions
+	for merchantID, m := range c.Merchants {
+		for i, w := range m.Webhooks {
+			sub := Subscription{ID: w.ID, MerchantID: merchantID, URL: w.URL, Secret: w.Secret}
+			if sub.ID == "" {
+				sub.ID = strconv.Itoa(i)
+			}
+			for _, e := range w.Events {
+				sub.Events = append(sub.Events, events.Type(e))
+			}
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// Match returns the subscriptions of the merchant that want the event type.
+func (s Subscriptions) Match(merchantID string, t events.Type) []Subscription {
+	var matched []Subscription
+	for _, sub := range s {
+		if sub.MerchantID == merchantID && sub.wants(t) {
+			matched = append(matched, sub)
+		}
+	}
+	return matched
+}
+
+// Get finds the subscription of a delivery.
+func (s Subscriptions) Get(merchantID, id string) (Subscription, bool) {
+	for _, sub := range s {
+		if sub.MerchantID == merchantID && sub.ID == id {
+			return sub, true
+		}
+	}
+	return Subscription{}, false
+}
+
+// Payload is the json body posted to the webhooks.
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      events.Type `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      struct {
+		Payment Payment `json:"payment"`
+	} `json:"data"`
+}
+
+// Payment is the part of the payment shared with merchants.
+type Payment struct {
+	ID             string       `json:"id"`
+	Provider       string       `json:"provider"`
+	Status         string       `json:"status"`
+	Amount         money.Money  `json:"amount"`
+	OriginalAmount *money.Money `json:"original_amount,omitempty"`
+	ExchangeRate   float64      `json:"exchange_rate,omitempty"`
+	PhoneNumber    string       `json:"phone_number"`
+	Description    string       `json:"description"`
+	TransactionID  string       `json:"transaction_id"`
+	ReceiptNumber  string       `json:"receipt_number,omitempty"`
+	ResultCode     string       `json:"result_code,omitempty"`
+	ResultDesc     string       `json:"result_desc,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+func NewPayload(e events.Event) Payload {
+	p := e.Payment
+	payload := Payload{ID: e.ID, Type: e.Type, CreatedAt: e.OccurredAt}
+	payload.Data.Payment = Payment{
+		ID:            p.ID,
+		Provider:      p.Provider,
+		Status:        string(p.Status),
+		Amount:        p.Amount,
+		ExchangeRate:  p.ExchangeRate,
+		PhoneNumber:   p.PhoneNumber,
+		Description:   p.Description,
+		TransactionID: p.TransactionID,
+		ReceiptNumber: p.ReceiptNumber,
+		ResultCode:    p.ResultCode,
+		ResultDesc:    p.ResultDesc,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+	if p.OriginalAmount != (money.Money{}) {
+		original := p.OriginalAmount
+		payload.Data.Payment.OriginalAmount = &original
+	}
+	return payload
+}
+
+// newDelivery starts the delivery log entry of the event.
+func newDelivery(id string, sub Subscription, e events.Event, body []byte, now time.Time) *store.WebhookDelivery {
+	return &store.WebhookDelivery{
+		ID:             id,
+		MerchantID:     sub.MerchantID,
+		SubscriptionID: sub.ID,
+		URL:            sub.URL,
+		EventID:        e.ID,
+		EventType:      string(e.Type),
+		PaymentID:      e.Payment.ID,
+		Payload:        body,
+		Status:         store.DeliveryPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"paydex/config"
+	"paydex/events"
+	"paydex/money"
+	"paydex/store"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hibiken/asynq"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":"1"}`)
+	header := Sign("secret", now, body)
+	if err := Verify("secret", header, body, time.Minute, now); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := Verify("other", header, body, time.Minute, now); err == nil {
+		t.Error("expected an error for the wrong secret")
+	}
+	if err := Verify("secret", header, []byte(`{"id":"2"}`), time.Minute, now); err == nil {
+		t.Error("expected an error for a modified body")
+	}
+	if err := Verify("secret", header, body, time.Minute, now.Add(2*time.Minute)); err == nil {
+		t.Error("expected an error for an expired signature")
+	}
+}
+
+type queue struct {
+	ids []string
+}
+
+func (q *queue) DistributeTaskDeliverWebhook(_ context.Context, id string, _ ...asynq.Option) error {
+	q.ids = append(q.ids, id)
+	return nil
+}
+
+func TestPublisher_Deliver(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	merchant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify("secret", r.Header.Get(SignatureHeader), body, time.Minute, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload Payload
+		if err := json.Unmarshal(body, &payload); err != nil || payload.Data.Payment.Amount.Minor != 1000 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- r
+	}))
+	defer merchant.Close()
+
+	subs := Subscriptions{
+		{ID: "orders", MerchantID: "shop", URL: merchant.URL, Events: []events.Type{events.PaymentCompleted}, Secret: "secret"},
+		{ID: "failures", MerchantID: "shop", URL: merchant.URL, Events: []events.Type{events.PaymentFailed}, Secret: "secret"},
+	}
+	s := store.NewMemoryStore()
+	q := &queue{}
+	p := NewPublisher(s, subs, q)
+	payment := &store.Payment{ID: "p1", MerchantID: "shop", Status: store.PaymentCompleted, Amount: money.Money{Minor: 1000, Currency: "KES"}}
+	if err := p.Publish(context.Background(), events.NewPaymentEvent(payment)); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.ids) != 1 {
+		t.Fatalf("queued %d deliveries, want 1", len(q.ids))
+	}
+
+	d, err := s.GetDelivery(context.Background(), q.ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSender(subs, time.Second).Send(context.Background(), d); err != nil {
+		t.Fatal(err)
+	}
+	r := <-received
+	if r.Header.Get(EventHeader) != string(events.PaymentCompleted) || r.Header.Get(DeliveryHeader) != d.ID {
+		t.Errorf("headers = %v", r.Header)
+	}
+	if d.Status != store.DeliverySucceeded || d.Attempts != 1 || d.ResponseStatus != http.StatusOK {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+func TestPublisher_Reload(t *testing.T) {
+	q := &queue{}
+	p := NewPublisher(store.NewMemoryStore(), nil, q)
+	payment := &store.Payment{ID: "p1", MerchantID: "shop", Status: store.PaymentCompleted, Amount: money.Money{Minor: 1000, Currency: "KES"}}
+
+	var c config.Config
+	if _, err := toml.Decode(`
+[merchants.shop]
+[[merchants.shop.webhooks]]
+url = "https://shop.example.com/hooks"
+secret = "secret"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+	p.Reload(&c)
+	if err := p.Publish(context.Background(), events.NewPaymentEvent(payment)); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.ids) != 1 {
+		t.Errorf("queued %d deliveries, want one for the reloaded merchant", len(q.ids))
+	}
+}
+
+func TestSender_Send_Failure(t *testing.T) {
+	merchant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer merchant.Close()
+	subs := Subscriptions{{ID: "orders", MerchantID: "shop", URL: merchant.URL, Secret: "secret"}}
+	d := &store.WebhookDelivery{ID: "d1", MerchantID: "shop", SubscriptionID: "orders", Status: store.DeliveryPending}
+	if err := NewSender(subs, time.Second).Send(context.Background(), d); err == nil {
+		t.Fatal("expected an error for a failed delivery")
+	}
+	if d.Status != store.DeliveryPending || d.ResponseStatus != http.StatusInternalServerError || d.LastError == "" {
+		t.Errorf("delivery = %+v", d)
+	}
+}
@@ -2,21 +2,44 @@ package worker
 
 import (
 	"context"
+	"paydex/config"
+	"paydex/mpesa"
 
 	"github.com/hibiken/asynq"
 )
 
 type TaskDistributor interface {
 	DistributeTaskSendSTKPush(ctx context.Context, payload *STKRequest, opts ...asynq.Option) error
+	DistributeTaskDeliverWebhook(ctx context.Context, deliveryID string, opts ...asynq.Option) error
+	DistributeTaskProcessCallback(ctx context.Context, callbackID string, opts ...asynq.Option) error
+	Close() error
 }
 
 type RedisTaskDistributor struct {
 	client *asynq.Client
+	// webhookAttempts is the maximum delivery attempts of a webhook.
+	webhookAttempts int
+	// defaultProvider collects the pushes that name no provider.
+	defaultProvider string
 }
 
-func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
+func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt, c *config.Config) TaskDistributor {
 	client := asynq.NewClient(redisOpt)
+	attempts := c.Webhooks.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultWebhookAttempts
+	}
+	defaultProvider := c.DefaultProvider
+	if defaultProvider == "" {
+		defaultProvider = mpesa.ProviderName
+	}
 	return &RedisTaskDistributor{
-		client: client,
+		client:          client,
+		webhookAttempts: attempts,
+		defaultProvider: defaultProvider,
 	}
 }
+
+func (distributor *RedisTaskDistributor) Close() error {
+	return distributor.client.Close()
+}
@@ -0,0 +1,159 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"paydex/breaker"
+	"paydex/config"
+	"paydex/metrics"
+	"paydex/mpesa"
+	"paydex/provider"
+	"paydex/ratelimit"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+	// guardInterval is how often the circuit is checked to pause the queue and probe daraja.
+	guardInterval = 5 * time.Second
+	// breakerPausedKey holds the instances keeping the mpesa queue paused
+	// scored by the expiry of their hold, so the queue is resumed when
+	// the instance holding it dies.
+	breakerPausedKey = "paydex:breaker:mpesa:paused"
+)
+
+// MpesaGuard throttles the daraja requests of all the instances and fails
+// them fast while daraja is down. It outlives the clients replaced on
+// reload so they share the rate and the circuit state.
+type MpesaGuard struct {
+	client  redis.UniversalClient
+	bucket  *ratelimit.Bucket
+	breaker *breaker.Breaker
+}
+
+func NewMpesaGuard(redisOpt asynq.RedisClientOpt, c *config.Config) *MpesaGuard {
+	client := NewRedisClient(redisOpt)
+	g := &MpesaGuard{client: client}
+	if c.Mpesa.RequestsPerSecond > 0 {
+		g.bucket = ratelimit.NewBucket(client, mpesa.ProviderName, c.Mpesa.RequestsPerSecond, c.Mpesa.Burst)
+	}
+	failures := defaultBreakerFailures
+	if c.Mpesa.BreakerFailures > 0 {
+		failures = c.Mpesa.BreakerFailures
+	}
+	cooldown := defaultBreakerCooldown
+	if c.Mpesa.BreakerCooldown > 0 {
+		cooldown = time.Duration(c.Mpesa.BreakerCooldown) * time.Second
+	}
+	g.breaker = breaker.New(failures, cooldown)
+	g.breaker.OnStateChange(func(from, to breaker.State) {
+		slog.Warn("mpesa circuit breaker "+to.String(), "from", from.String())
+		metrics.CircuitState.WithLabelValues(mpesa.ProviderName).Set(float64(to))
+	})
+	metrics.CircuitState.WithLabelValues(mpesa.ProviderName).Set(float64(breaker.Closed))
+	return g
+}
+
+// options are the mpesa client options of the guard, none when g is nil.
+func (g *MpesaGuard) options() []mpesa.ClientOption {
+	if g == nil {
+		return nil
+	}
+	opts := []mpesa.ClientOption{mpesa.WithBreaker(g.breaker)}
+	if g.bucket != nil {
+		opts = append(opts, mpesa.WithRateLimit(g.bucket))
+	}
+	return opts
+}
+
+// Watch pauses the mpesa queue while the circuit is open and probes
+// daraja until a probe closes the circuit, the queue is then resumed.
+// It returns when ctx is done.
+func (g *MpesaGuard) Watch(ctx context.Context, redisOpt asynq.RedisClientOpt, providers *provider.Registry) {
+	inspector := asynq.NewInspector(redisOpt)
+	defer inspector.Close()
+	id := uuid.NewString()
+	ticker := time.NewTicker(guardInterval)
+	defer ticker.Stop()
+	for {
+		switch g.breaker.State() {
+		case breaker.Open:
+			g.pause(ctx, inspector, id)
+			// fails fast with ErrOpen until the cooldown passed.
+			if err := probe(ctx, providers); err == nil {
+				slog.Info("mpesa probe succeeded")
+			}
+		case breaker.Closed:
+			g.resume(ctx, inspector, id)
+		}
+		select {
+		case <-ctx.Done():
+			g.client.ZRem(context.Background(), breakerPausedKey, id)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pause holds the queue paused for a few intervals and pauses it.
+func (g *MpesaGuard) pause(ctx context.Context, inspector *asynq.Inspector, id string) {
+	until := time.Now().Add(3 * guardInterval).UnixMilli()
+	if err := g.client.ZAdd(ctx, breakerPausedKey, &redis.Z{Score: float64(until), Member: id}).Err(); err != nil {
+		slog.Error("failed to hold the mpesa queue paused", err)
+	}
+	if info, err := inspector.GetQueueInfo(QueueMpesa); err == nil && info.Paused {
+		return
+	}
+	if err := inspector.PauseQueue(QueueMpesa); err != nil && !errors.Is(err, asynq.ErrQueueNotFound) {
+		slog.Error("failed to pause the mpesa queue", err)
+		return
+	}
+	slog.Warn("mpesa queue paused")
+}
+
+// resume releases the hold of the instance and resumes the queue once no
+// instance holds it paused, holds of dead instances expire.
+func (g *MpesaGuard) resume(ctx context.Context, inspector *asynq.Inspector, id string) {
+	info, err := inspector.GetQueueInfo(QueueMpesa)
+	if err != nil || !info.Paused {
+		return
+	}
+	pipe := g.client.TxPipeline()
+	pipe.ZRem(ctx, breakerPausedKey, id)
+	pipe.ZRemRangeByScore(ctx, breakerPausedKey, "-inf", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	held := pipe.ZCard(ctx, breakerPausedKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Error("failed to check the mpesa queue holds", err)
+		return
+	}
+	if held.Val() > 0 {
+		return
+	}
+	if err := inspector.UnpauseQueue(QueueMpesa); err != nil {
+		slog.Error("failed to resume the mpesa queue", err)
+		return
+	}
+	slog.Info("mpesa queue resumed")
+}
+
+// probe requests a daraja token through the breaker, a success closes it.
+func probe(ctx context.Context, providers *provider.Registry) error {
+	p, err := providers.Get(mpesa.ProviderName)
+	if err != nil {
+		return err
+	}
+	m, ok := p.(*mpesa.Mpesa)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.TimeOut)
+	defer cancel()
+	return m.Probe(ctx)
+}
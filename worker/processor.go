@@ -2,9 +2,15 @@ package worker
 
 import (
 	"context"
+	"paydex/callback"
 	"paydex/config"
-	"paydex/mpesa"
-
+	"paydex/events"
+	"paydex/metrics"
+	"paydex/provider"
+	"paydex/store"
+	"paydex/tracing"
+	"paydex/webhook"
+	"sync/atomic"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -14,25 +20,54 @@ import (
 const (
 	QueueCritical = "critical"
 	QueueDefault  = "default"
+	// QueueWebhooks keeps slow merchant endpoints from delaying payments.
+	QueueWebhooks = "webhooks"
+	// QueueMpesa holds the mpesa stk pushes, it is paused while daraja is down.
+	QueueMpesa = "mpesa"
 )
 
 type TaskProcessor interface {
 	Start() error
-	StartScheduler() error
 	ProcessTaskSendSTKPush(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPurgeExpiredData(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReconcilePayments(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error
+	ProcessTaskProcessCallback(ctx context.Context, task *asynq.Task) error
+	// Shutdown waits for the active tasks to finish.
+	Shutdown()
+	// Reload swaps the config read by the tasks, the running tasks finish with the old one.
+	Reload(c *config.Config)
 }
 
 type RedisTaskProcessor struct {
-	server   *asynq.Server
-	mpesa    *mpesa.Mpesa
+	server    *asynq.Server
+	providers *provider.Registry
+	store     store.Store
+	publisher events.Publisher
+	callbacks *callback.Processor
+	settings  atomic.Pointer[settings]
+}
+
+// settings are built from the config and replaced together on reload.
+type settings struct {
 	c        *config.Config
-	redisOpt asynq.RedisClientOpt
+	webhooks *webhook.Sender
+}
+
+func newSettings(c *config.Config) *settings {
+	timeout := 10 * time.Second
+	if c.Webhooks.Timeout > 0 {
+		timeout = time.Duration(c.Webhooks.Timeout) * time.Second
+	}
+	return &settings{c: c, webhooks: webhook.NewSender(webhook.SubscriptionsFromConfig(c), timeout)}
 }
 
 func NewRedisTaskProcessor(
 	redisOpt asynq.RedisClientOpt,
 	c *config.Config,
-
+	providers *provider.Registry,
+	s store.Store,
+	publisher events.Publisher,
 ) TaskProcessor {
 	server := asynq.NewServer(
 		redisOpt,
@@ -40,48 +75,60 @@ func NewRedisTaskProcessor(
 			Queues: map[string]int{
 				QueueCritical: 10,
 				QueueDefault:  5,
+				QueueMpesa:    5,
+				QueueWebhooks: 3,
 			},
+			RetryDelayFunc: retryDelay,
+			// tasks still running after the timeout are retried by another worker.
+			ShutdownTimeout: shutdownTimeout(c),
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
-				slog.Error("process task failed", err, "type", task.Type(), "payload", task.Payload())
+				// the payloads carry phone numbers, the task is identified by its id.
+				id, _ := asynq.GetTaskID(ctx)
+				slog.Error("process task failed", err, "type", task.Type(), "task_id", id)
 			}),
 			Logger: NewLogger(),
 		},
 	)
-
-	client := mpesa.New(
-		c.Mpesa.ConsumerKey,
-		c.Mpesa.ConsumerSecret,
-		mpesa.WithTimeout(10*time.Second),
-		mpesa.WithCache(true),
-		mpesa.WithPassKey(c.Mpesa.PassKey),
-		mpesa.WithB2CShortCode(c.Mpesa.ShortCode),
-	)
-	return &RedisTaskProcessor{
-		server: server,
-		mpesa:  client,
-		c:      c,
+	processor := &RedisTaskProcessor{
+		server:    server,
+		providers: providers,
+		store:     s,
+		publisher: publisher,
+		callbacks: callback.NewProcessor(s, callback.NewMpesa(s, providers, publisher), callback.NewAirtel(s, providers, publisher)),
 	}
+	processor.settings.Store(newSettings(c))
+	return processor
 }
 
 func (processor *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
+	mux.Use(tracing.TaskMiddleware, metrics.TaskMiddleware)
 	mux.HandleFunc(TaskSendSTK, processor.ProcessTaskSendSTKPush)
+	mux.HandleFunc(TaskPurgeExpiredData, processor.ProcessTaskPurgeExpiredData)
+	mux.HandleFunc(TaskReconcilePayments, processor.ProcessTaskReconcilePayments)
+	mux.HandleFunc(TaskDeliverWebhook, processor.ProcessTaskDeliverWebhook)
+	mux.HandleFunc(TaskProcessCallback, processor.ProcessTaskProcessCallback)
 
 	return processor.server.Start(mux)
 }
 
-func (processor *RedisTaskProcessor) StartScheduler() error {
-	l, err := time.LoadLocation("Africa/Nairobi")
-	if err != nil {
-		return err
+func (processor *RedisTaskProcessor) Shutdown() {
+	processor.server.Shutdown()
+}
+
+func (processor *RedisTaskProcessor) Reload(c *config.Config) {
+	processor.settings.Store(newSettings(c))
+}
+
+// conf is the current config, read it once per task.
+func (processor *RedisTaskProcessor) conf() *config.Config {
+	return processor.settings.Load().c
+}
+
+// shutdownTimeout leaves part of the shutdown deadline to the other servers.
+func shutdownTimeout(c *config.Config) time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return 0
 	}
-	mux := asynq.NewScheduler(processor.redisOpt, &asynq.SchedulerOpts{
-		Location: l,
-	})
-	// entry, err := mux.Register("@every 1m", asynq.NewTask(TaskUpdatePayment, nil))
-	// if err != nil {
-	// 	return err
-	// }
-	// log.Printf("task scheduled [%s]", entry)
-	return mux.Run()
+	return time.Duration(c.ShutdownTimeout) * time.Second * 3 / 4
 }
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"net/http"
+	"paydex/airtel"
+	"paydex/config"
+	"paydex/jenga"
+	"paydex/mpesa"
+	"paydex/provider"
+	"reflect"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// NewProviderRegistry builds the payment providers enabled in the config.
+// a provider is enabled when its credentials are set, the mpesa requests
+// go through guard when not nil.
+func NewProviderRegistry(c *config.Config, guard *MpesaGuard) *provider.Registry {
+	fallback := c.DefaultProvider
+	if fallback == "" {
+		fallback = mpesa.ProviderName
+	}
+	registry := provider.NewRegistry(fallback)
+	if c.Mpesa.ConsumerKey != "" {
+		registry.Register(newMpesa(c, guard))
+	}
+	if c.Airtel.ClientID != "" {
+		registry.Register(newAirtel(c))
+	}
+	if c.Jenga.APIKey != "" {
+		registry.Register(newJenga(c))
+	}
+	return registry
+}
+
+// ReloadProviders replaces the registered providers whose settings changed,
+// the others keep their clients and cached access tokens. The requests in
+// flight finish with the provider they started with.
+func ReloadProviders(registry *provider.Registry, guard *MpesaGuard, old, c *config.Config) {
+	if c.Mpesa.ConsumerKey != "" && !reflect.DeepEqual(old.Mpesa, c.Mpesa) {
+		registry.Register(newMpesa(c, guard))
+	}
+	if c.Airtel.ClientID != "" && !reflect.DeepEqual(old.Airtel, c.Airtel) {
+		registry.Register(newAirtel(c))
+	}
+	if c.Jenga.APIKey != "" && !reflect.DeepEqual(old.Jenga, c.Jenga) {
+		registry.Register(newJenga(c))
+	}
+}
+
+func newMpesa(c *config.Config, guard *MpesaGuard) *mpesa.Mpesa {
+	timeout := 10 * time.Second
+	if c.Mpesa.Timeout > 0 {
+		timeout = time.Duration(c.Mpesa.Timeout) * time.Second
+	}
+	opts := append(guard.options(),
+		mpesa.WithHooks(logRequests(mpesa.ProviderName)),
+		mpesa.WithLiveMode(c.Mpesa.Live),
+		mpesa.WithTimeout(timeout),
+		mpesa.WithCache(true),
+		mpesa.WithPassKey(c.Mpesa.PassKey),
+		mpesa.WithC2BShortCode(c.Mpesa.ShortCode),
+		mpesa.WithB2CShortCode(c.Mpesa.B2CShortCode),
+		mpesa.WithInitiator(c.Mpesa.InitiatorName, c.Mpesa.SecurityCredential),
+	)
+	if c.Mpesa.ShareAccessToken && guard != nil {
+		opts = append(opts, mpesa.WithTokenStore(guard.tokenStore()))
+	}
+	return mpesa.New(c.Mpesa.ConsumerKey, c.Mpesa.ConsumerSecret, opts...)
+}
+
+func newAirtel(c *config.Config) *airtel.Airtel {
+	opts := []airtel.ClientOption{
+		airtel.WithLiveMode(c.Airtel.Live),
+		airtel.WithEncryptedPIN(c.Airtel.EncryptedPIN),
+	}
+	if c.Airtel.Country != "" {
+		opts = append(opts, airtel.WithCountry(c.Airtel.Country, c.Airtel.Currency, c.Airtel.CountryCode))
+	}
+	if c.Airtel.BaseURL != "" {
+		opts = append(opts, airtel.WithBaseURL(c.Airtel.BaseURL))
+	}
+	if c.Airtel.Timeout > 0 {
+		opts = append(opts, airtel.WithTimeout(time.Duration(c.Airtel.Timeout)*time.Second))
+	}
+	return airtel.New(c.Airtel.ClientID, c.Airtel.ClientSecret, opts...)
+}
+
+func newJenga(c *config.Config) *jenga.Jenga {
+	opts := []jenga.ClientOption{
+		jenga.WithHooks(logRequests(jenga.ProviderName)),
+		jenga.WithSourceAccount(c.Jenga.CountryCode, c.Jenga.AccountName, c.Jenga.AccountNumber),
+		jenga.WithLiveMode(c.Jenga.Live),
+	}
+	if c.Jenga.Timeout > 0 {
+		opts = append(opts, jenga.WithTimeout(time.Duration(c.Jenga.Timeout)*time.Second))
+	}
+	return jenga.New(
+		c.Jenga.Username,
+		c.Jenga.Password,
+		c.Jenga.APIKey,
+		c.Jenga.MerchantCode,
+		c.Jenga.PrivateKeyPath,
+		opts...,
+	)
+}
+
+// logRequests logs the provider requests at debug level.
+func logRequests(name string) provider.Hooks {
+	return provider.Hooks{
+		Response: func(r *http.Request, res *http.Response, err error, elapsed time.Duration) {
+			code := 0
+			if res != nil {
+				code = res.StatusCode
+			}
+			slog.Debug("provider request", "provider", name, "method", r.Method, "path", r.URL.Path,
+				"status", code, "duration", elapsed, "error", err)
+		},
+	}
+}
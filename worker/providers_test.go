@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"paydex/config"
+	"paydex/mpesa"
+	"testing"
+)
+
+func TestReloadProviders(t *testing.T) {
+	c := &config.Config{}
+	c.Mpesa.ConsumerKey = "key"
+	c.Mpesa.PassKey = "passkey"
+	registry := NewProviderRegistry(c, nil)
+	before, err := registry.Get(mpesa.ProviderName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unchanged := *c
+	ReloadProviders(registry, nil, c, &unchanged)
+	if p, _ := registry.Get(mpesa.ProviderName); p != before {
+		t.Error("unchanged provider was replaced")
+	}
+
+	rotated := unchanged
+	rotated.Mpesa.PassKey = "rotated"
+	ReloadProviders(registry, nil, &unchanged, &rotated)
+	p, _ := registry.Get(mpesa.ProviderName)
+	if p == before {
+		t.Fatal("changed provider was not replaced")
+	}
+	if got := p.(*mpesa.Mpesa).DefaultPassKey; got != "rotated" {
+		t.Errorf("DefaultPassKey = %q, want rotated", got)
+	}
+}
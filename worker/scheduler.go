@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"paydex/config"
+	"paydex/leader"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// SchedulerLockKey is the leader lock of the scheduler instances.
+	SchedulerLockKey = "paydex:scheduler:leader"
+	// schedulerLockTTL is how long the periodic tasks may be missed
+	// when the leader dies without releasing the lock.
+	schedulerLockTTL = 30 * time.Second
+)
+
+// Scheduler enqueues the periodic tasks, only the instance holding the
+// leader lock enqueues them so each run is enqueued once.
+type Scheduler struct {
+	redisOpt asynq.RedisClientOpt
+	c        *config.Config
+	lock     *leader.Lock
+}
+
+func NewScheduler(redisOpt asynq.RedisClientOpt, c *config.Config, lock *leader.Lock) *Scheduler {
+	return &Scheduler{redisOpt: redisOpt, c: c, lock: lock}
+}
+
+// NewSchedulerLock is the leader lock of the scheduler in the asynq redis.
+func NewSchedulerLock(redisOpt asynq.RedisClientOpt) *leader.Lock {
+	return leader.New(NewRedisClient(redisOpt), SchedulerLockKey, schedulerLockTTL)
+}
+
+// NewRedisClient connects to the redis of the queues.
+func NewRedisClient(redisOpt asynq.RedisClientOpt) redis.UniversalClient {
+	// asynq v0.24 builds go-redis v8 clients.
+	return redisOpt.MakeRedisClient().(redis.UniversalClient)
+}
+
+// Run campaigns for the leader lock and schedules the tasks while it is
+// held, until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	return s.lock.Run(ctx, s.schedule)
+}
+
+func (s *Scheduler) schedule(ctx context.Context) error {
+	l, err := time.LoadLocation("Africa/Nairobi")
+	if err != nil {
+		return err
+	}
+	// a scheduler can not be started again, each leadership gets its own.
+	scheduler := asynq.NewScheduler(s.redisOpt, &asynq.SchedulerOpts{
+		Location: l,
+		Logger:   NewLogger(),
+	})
+	periodic := []struct {
+		schedule string
+		task     string
+		queue    string
+	}{
+		{s.c.Reconciliation.Schedule, TaskReconcilePayments, QueueCritical},
+		{s.c.Retention.Schedule, TaskPurgeExpiredData, QueueDefault},
+	}
+	for _, p := range periodic {
+		if p.schedule == "" {
+			continue
+		}
+		// a run that overlaps the next one is not useful, drop it.
+		entry, err := scheduler.Register(p.schedule, asynq.NewTask(p.task, nil), asynq.Queue(p.queue), asynq.MaxRetry(0))
+		if err != nil {
+			return err
+		}
+		slog.Info("task scheduled", "type", p.task, "entry", entry, "schedule", p.schedule)
+	}
+	if err := scheduler.Start(); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	scheduler.Shutdown()
+	return nil
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"paydex/store"
+	"paydex/tracing"
+	"paydex/webhook"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+const TaskDeliverWebhook = "task:deliver_webhook"
+
+// defaultWebhookAttempts is used when Webhooks.MaxAttempts is not configured.
+const defaultWebhookAttempts = 8
+
+type WebhookDeliveryPayload struct {
+	DeliveryID string
+	Trace      map[string]string `json:",omitempty"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskDeliverWebhook(
+	ctx context.Context,
+	deliveryID string,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(WebhookDeliveryPayload{DeliveryID: deliveryID, Trace: tracing.Inject(ctx)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	opts = append([]asynq.Option{
+		asynq.Queue(QueueWebhooks),
+		asynq.MaxRetry(distributor.webhookAttempts - 1),
+	}, opts...)
+	task := asynq.NewTask(TaskDeliverWebhook, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	slog.Info("enqueued task", "type", task.Type(), "delivery_id", deliveryID, "queue", info.Queue, "max_retry", info.MaxRetry)
+	return nil
+}
+
+// ProcessTaskDeliverWebhook makes one delivery attempt, failed attempts
+// are retried with webhook.RetryDelay until the attempts run out.
+func (processor *RedisTaskProcessor) ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	var payload WebhookDeliveryPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+	d, err := processor.store.GetDelivery(ctx, payload.DeliveryID)
+	if errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("webhook delivery %s: %w", payload.DeliveryID, asynq.SkipRetry)
+	}
+	if err != nil {
+		return err
+	}
+	if d.Status == store.DeliverySucceeded {
+		return nil
+	}
+
+	sendErr := processor.settings.Load().webhooks.Send(ctx, d)
+	if sendErr != nil {
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if errors.Is(sendErr, webhook.ErrUnknownSubscription) || retried >= maxRetry {
+			d.Status = store.DeliveryFailed
+			sendErr = fmt.Errorf("%s: %w", sendErr, asynq.SkipRetry)
+		}
+	}
+	d.UpdatedAt = time.Now()
+	if err := processor.store.UpdateDelivery(ctx, d); err != nil {
+		slog.Error("failed to update webhook delivery", err, "delivery_id", d.ID)
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	slog.Info("processed task", "type", task.Type(), "delivery_id", d.ID, "attempts", d.Attempts)
+	return nil
+}
+
+// retryDelay backs off webhook deliveries, other tasks keep the asynq default.
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if task.Type() == TaskDeliverWebhook {
+		return webhook.RetryDelay(n)
+	}
+	return asynq.DefaultRetryDelayFunc(n, err, task)
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"paydex/callback"
+	"paydex/mpesa"
+	"paydex/store"
+	"paydex/tracing"
+
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+const TaskProcessCallback = "task:process_callback"
+
+type CallbackPayload struct {
+	CallbackID string
+	Trace      map[string]string `json:",omitempty"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskProcessCallback(
+	ctx context.Context,
+	callbackID string,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(CallbackPayload{CallbackID: callbackID, Trace: tracing.Inject(ctx)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	opts = append([]asynq.Option{asynq.Queue(QueueCritical)}, opts...)
+	task := asynq.NewTask(TaskProcessCallback, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	slog.Info("enqueued task", "type", task.Type(), "callback_id", callbackID, "queue", info.Queue, "max_retry", info.MaxRetry)
+	return nil
+}
+
+// ProcessTaskProcessCallback applies a stored callback, callbacks that
+// were rejected or cannot be parsed are not retried.
+func (processor *RedisTaskProcessor) ProcessTaskProcessCallback(ctx context.Context, task *asynq.Task) error {
+	var payload CallbackPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+	c, err := processor.callbacks.Process(ctx, payload.CallbackID)
+	if errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("callback %s: %w", payload.CallbackID, asynq.SkipRetry)
+	}
+	if errors.Is(err, mpesa.ErrCallbackRejected) || errors.Is(err, callback.ErrMalformed) {
+		return fmt.Errorf("%s: %w", err, asynq.SkipRetry)
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("processed task", "type", task.Type(), "callback_id", c.ID, "kind", c.Kind, "attempts", c.Attempts)
+	return nil
+}
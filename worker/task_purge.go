@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"paydex/retention"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+const TaskPurgeExpiredData = "task:purge_expired_data"
+
+// ProcessTaskPurgeExpiredData deletes or anonymizes the records
+// that are past the configured retention periods.
+func (processor *RedisTaskProcessor) ProcessTaskPurgeExpiredData(ctx context.Context, task *asynq.Task) error {
+	c := processor.conf()
+	purger := retention.NewPurger(processor.store, retention.PolicyFromConfig(c))
+	report, err := purger.Run(ctx, time.Now(), c.Retention.DryRun)
+	if err != nil {
+		return err
+	}
+	slog.Info("processed task", "type", task.Type(),
+		"dry_run", report.DryRun,
+		"callbacks", len(report.Callbacks),
+		"anonymized", len(report.Anonymized),
+		"deliveries", len(report.Deliveries),
+		"deleted", len(report.Deleted))
+	return nil
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"paydex/reconcile"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"golang.org/x/exp/slog"
+)
+
+const TaskReconcilePayments = "task:reconcile_payments"
+
+// ProcessTaskReconcilePayments resolves payments whose callback never arrived.
+func (processor *RedisTaskProcessor) ProcessTaskReconcilePayments(ctx context.Context, task *asynq.Task) error {
+	reconciler := reconcile.New(processor.store, processor.providers, processor.publisher, reconcile.PolicyFromConfig(processor.conf()))
+	report, err := reconciler.Run(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	slog.Info("processed task", "type", task.Type(),
+		"checked", report.Checked,
+		"completed", report.Completed,
+		"failed", report.Failed,
+		"expired", report.Expired)
+	return nil
+}
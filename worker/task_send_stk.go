@@ -5,7 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"paydex/breaker"
+	"paydex/callback"
+	"paydex/metrics"
+	"paydex/money"
 	"paydex/mpesa"
+	"paydex/provider"
+	"paydex/ratelimit"
+	"paydex/store"
+	"paydex/tracing"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -16,9 +24,42 @@ import (
 const TaskSendSTK = "task:send_stk"
 
 type STKRequest struct {
-	Amount      string
+	// PaymentID is the stored payment the push is for.
+	PaymentID string
+	// Provider is the payment provider to collect with
+	// the default provider is used when empty.
+	Provider    string
+	Amount      money.Money
 	Description string
 	PhoneNumber string
+	// set when the client paid in another currency
+	// and the amount was converted.
+	OriginalAmount money.Money
+	ExchangeRate   float64 `json:",omitempty"`
+	RateTimestamp  time.Time
+	// Trace is the trace context of the request, see tracing.TaskMiddleware.
+	Trace map[string]string `json:",omitempty"`
+}
+
+// UnmarshalJSON also reads the tasks queued before the amounts were money.
+func (r *STKRequest) UnmarshalJSON(b []byte) error {
+	type request STKRequest
+	v := struct {
+		*request
+		Amount           json.RawMessage
+		Currency         string
+		OriginalAmount   json.RawMessage
+		OriginalCurrency string
+	}{request: (*request)(r)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	var err error
+	if r.Amount, err = money.UnmarshalLegacy(v.Amount, v.Currency); err != nil {
+		return err
+	}
+	r.OriginalAmount, err = money.UnmarshalLegacy(v.OriginalAmount, v.OriginalCurrency)
+	return err
 }
 
 func (distributor *RedisTaskDistributor) DistributeTaskSendSTKPush(
@@ -26,17 +67,27 @@ func (distributor *RedisTaskDistributor) DistributeTaskSendSTKPush(
 	payload *STKRequest,
 	opts ...asynq.Option,
 ) error {
+	payload.Trace = tracing.Inject(ctx)
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal task payload: %w", err)
 	}
 
+	name := payload.Provider
+	if name == "" {
+		name = distributor.defaultProvider
+	}
+	if name == mpesa.ProviderName {
+		// paused while daraja is down, see MpesaGuard.
+		opts = append([]asynq.Option{asynq.Queue(QueueMpesa)}, opts...)
+	}
 	task := asynq.NewTask(TaskSendSTK, jsonPayload, opts...)
 	info, err := distributor.client.EnqueueContext(ctx, task)
 	if err != nil {
 		return fmt.Errorf("failed to enqueue task: %w", err)
 	}
-	slog.Info("enqueued task", "type", task.Type(), "payload", string(task.Payload()), "queue", info.Queue, "max_retry", info.MaxRetry)
+	// the payload carries the phone number, it is not logged.
+	slog.Info("enqueued task", "type", task.Type(), "payment_id", payload.PaymentID, "provider", name, "queue", info.Queue, "max_retry", info.MaxRetry)
 	return nil
 }
 
@@ -47,25 +98,94 @@ func (processor *RedisTaskProcessor) ProcessTaskSendSTKPush(ctx context.Context,
 		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
 	}
 
-	val := mpesa.StKPushRequestBody{
-		BusinessShortCode: processor.c.Mpesa.ShortCode,
-		Amount:            payload.Amount,
-		PhoneNumber:       payload.PhoneNumber,
-		CallBackURL:       processor.c.Mpesa.CallbackURL,
-		AccountReference:  processor.c.Mpesa.BusinessName,
-		TransactionDesc:   payload.Description,
+	p, err := processor.providers.Get(payload.Provider)
+	if err != nil {
+		return fmt.Errorf("%s: %w", err, asynq.SkipRetry)
+	}
+
+	c := processor.conf()
+	// the token authenticates the callback, only its hash is stored.
+	token, tokenHash, err := callback.NewToken()
+	if err != nil {
+		return err
+	}
+	callbackURL, err := callback.URL(c.Mpesa.CallbackURL, payload.PaymentID, token)
+	if err != nil {
+		return fmt.Errorf("invalid callback url: %s: %w", err, asynq.SkipRetry)
+	}
+	processor.updatePayment(ctx, payload.PaymentID, func(payment *store.Payment) {
+		payment.CallbackTokenHash = tokenHash
+	})
+
+	val := provider.CollectRequest{
+		Amount:      payload.Amount,
+		PhoneNumber: payload.PhoneNumber,
+		CallbackURL: callbackURL,
+		Reference:   c.Mpesa.BusinessName,
+		Description: payload.Description,
 	}
 	ct, cancelFunc := context.WithTimeout(ctx, 10*time.Second)
 	defer cancelFunc()
-	data, err := processor.mpesa.StkPushRequest(ct, val)
+	data, err := p.Collect(ct, val)
+	if errors.Is(err, breaker.ErrOpen) || errors.Is(err, ratelimit.ErrThrottled) {
+		// the push was not sent, retry it once the provider takes requests.
+		return err
+	}
 	if err != nil {
 		log.Print(err)
-		return errors.Wrap(asynq.SkipRetry, "MpesaService.MpesaPay")
+		processor.updatePayment(ctx, payload.PaymentID, func(payment *store.Payment) {
+			payment.Status = store.PaymentFailed
+			payment.ResultDesc = err.Error()
+		})
+		return errors.Wrap(asynq.SkipRetry, p.Name()+".Collect")
 	}
 
-	if data.ResponseCode != "0" {
-		return errors.Wrap(asynq.SkipRetry, "MpesaService.MpesaPay")
+	processor.updatePayment(ctx, payload.PaymentID, func(payment *store.Payment) {
+		payment.TransactionID = data.TransactionID
+		payment.Status = store.PaymentStatus(data.Status)
+		payment.ResultDesc = data.Message
+	})
+	if data.Status == provider.StatusFailed {
+		return errors.Wrap(asynq.SkipRetry, p.Name()+".Collect")
 	}
-	slog.Info("processed task", "type", task.Type(), "payload", string(task.Payload()))
+	slog.Info("processed task", "type", task.Type(), "payment_id", payload.PaymentID, "provider", p.Name())
 	return nil
 }
+
+// updatePaymentAttempts bounds the retries of an update that raced
+// another write of the payment.
+const updatePaymentAttempts = 3
+
+// updatePayment applies fn to the stored payment while it is pending, an
+// outcome the callback or the reconciler recorded in the meantime is not
+// overwritten. Tasks enqueued before payments were stored have no payment
+// id and are skipped.
+func (processor *RedisTaskProcessor) updatePayment(ctx context.Context, id string, fn func(*store.Payment)) {
+	if id == "" {
+		return
+	}
+	for i := 0; i < updatePaymentAttempts; i++ {
+		payment, err := processor.store.GetPayment(ctx, id)
+		if err != nil {
+			slog.Error("failed to get payment", err, "payment_id", id)
+			return
+		}
+		if payment.Status != store.PaymentPending {
+			slog.Info("payment already resolved", "payment_id", id, "status", payment.Status)
+			return
+		}
+		fn(payment)
+		payment.UpdatedAt = time.Now()
+		err = processor.store.SwapPayment(ctx, payment, store.PaymentPending)
+		if errors.Is(err, store.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			slog.Error("failed to update payment", err, "payment_id", id)
+		} else if payment.Status != store.PaymentPending {
+			metrics.StkPushResolved(payment.Provider, string(payment.Status), payment.ResultCode)
+		}
+		return
+	}
+	slog.Warn("payment kept changing, it was not updated", "payment_id", id)
+}
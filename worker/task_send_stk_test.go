@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"paydex/airtel"
+	"paydex/airtel/airteltest"
+	"paydex/config"
+	"paydex/money"
+	"paydex/store"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestProcessTaskSendSTKPush_Airtel(t *testing.T) {
+	s := airteltest.NewServer()
+	defer s.Close()
+
+	c := &config.Config{DefaultProvider: airtel.ProviderName}
+	c.Airtel.ClientID = airteltest.ClientID
+	c.Airtel.ClientSecret = airteltest.ClientSecret
+	c.Airtel.BaseURL = s.URL
+	c.Mpesa.BusinessName = "paydex"
+	processor := &RedisTaskProcessor{providers: NewProviderRegistry(c, nil)}
+	processor.Reload(c)
+
+	payload, err := json.Marshal(STKRequest{Amount: money.Money{Minor: 1000, Currency: "KES"}, PhoneNumber: "254733000000", Description: "order"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.ProcessTaskSendSTKPush(context.Background(), asynq.NewTask(TaskSendSTK, payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err = json.Marshal(STKRequest{Provider: "unknown", Amount: money.Money{Minor: 1000, Currency: "KES"}, PhoneNumber: "254733000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.ProcessTaskSendSTKPush(context.Background(), asynq.NewTask(TaskSendSTK, payload)); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestSTKRequest_UnmarshalLegacy(t *testing.T) {
+	var r STKRequest
+	legacy := `{"PaymentID": "6f1c7a0e", "Amount": "1500", "Currency": "KES", "OriginalAmount": "11.50", "OriginalCurrency": "USD", "ExchangeRate": 130.4}`
+	if err := json.Unmarshal([]byte(legacy), &r); err != nil {
+		t.Fatal(err)
+	}
+	if want := (money.Money{Minor: 150000, Currency: "KES"}); !r.Amount.Equal(want) {
+		t.Errorf("Amount = %v, want %v", r.Amount, want)
+	}
+	if want := (money.Money{Minor: 1150, Currency: "USD"}); !r.OriginalAmount.Equal(want) {
+		t.Errorf("OriginalAmount = %v, want %v", r.OriginalAmount, want)
+	}
+	if r.PaymentID != "6f1c7a0e" || r.ExchangeRate != 130.4 {
+		t.Errorf("STKRequest = %+v, want the other fields decoded", r)
+	}
+}
+
+func TestUpdatePayment_Resolved(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	if err := s.CreatePayment(ctx, &store.Payment{ID: "p1", Status: store.PaymentCompleted, ReceiptNumber: "NLJ7RT61SV"}); err != nil {
+		t.Fatal(err)
+	}
+	processor := &RedisTaskProcessor{store: s}
+	processor.updatePayment(ctx, "p1", func(payment *store.Payment) {
+		payment.Status = store.PaymentPending
+		payment.TransactionID = "ws_CO_1"
+	})
+	p, _ := s.GetPayment(ctx, "p1")
+	if p.Status != store.PaymentCompleted || p.TransactionID != "" {
+		t.Errorf("payment = %s %q, want the completed payment kept", p.Status, p.TransactionID)
+	}
+}
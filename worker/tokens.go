@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"paydex/mpesa"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const mpesaTokenKeyPrefix = "paydex:mpesa:token:"
+
+// redisTokens shares the daraja access tokens between the instances.
+type redisTokens struct {
+	client redis.UniversalClient
+}
+
+var _ mpesa.TokenStore = redisTokens{}
+
+// tokenStore keeps the tokens in the redis of the guard.
+func (g *MpesaGuard) tokenStore() mpesa.TokenStore {
+	return redisTokens{client: g.client}
+}
+
+func (t redisTokens) Get(ctx context.Context, key string) (*mpesa.AccessTokenResponse, error) {
+	b, err := t.client.Get(ctx, mpesaTokenKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var token mpesa.AccessTokenResponse
+	if err := json.Unmarshal(b, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (t redisTokens) Set(ctx context.Context, key string, token *mpesa.AccessTokenResponse) error {
+	ttl := time.Until(token.ExpireTime)
+	if ttl <= 0 {
+		return nil
+	}
+	b, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return t.client.Set(ctx, mpesaTokenKeyPrefix+key, b, ttl).Err()
+}
+
+func (t redisTokens) Delete(ctx context.Context, key string) error {
+	return t.client.Del(ctx, mpesaTokenKeyPrefix+key).Err()
+}